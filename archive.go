@@ -0,0 +1,170 @@
+package lld
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats for -archive.
+const (
+	archiveZip = "zip"
+	archiveTgz = "tgz"
+)
+
+func archiveExt(format string) string {
+	if format == archiveTgz {
+		return ".tar.gz"
+	}
+
+	return ".zip"
+}
+
+// archiveCourse packages everything saved in dir into <slug>.zip/.tar.gz inside it, or onto stdout with -archive-stdout.
+func archiveCourse(ctx context.Context, opts *options, courseURL, dir string) error {
+	if opts.archive == "" {
+		return nil
+	}
+	slug, err := courseSlug(opts.learningBase, courseURL)
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(dir, slug+archiveExt(opts.archive))
+
+	var w io.Writer = os.Stdout
+	if !opts.archiveStdout {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create file %s: %w", name, err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		w = f
+	}
+
+	logf(ctx, tr("🗜️ Archiving %s\n"), dir)
+	if opts.archive == archiveTgz {
+		err = writeTgz(w, dir, name)
+	} else {
+		err = writeZip(w, dir, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", dir, err)
+	}
+	if !opts.archiveStdout {
+		logf(ctx, tr("💾 Archive saved: %s\n"), name)
+	}
+
+	return nil
+}
+
+// walkCourseFiles calls fn for every file of the course under dir, except skip, leaving out our hidden bookkeeping,
+// like the checkpoints, -sync validators, and partial downloads, which only make sense where they were saved.
+func walkCourseFiles(dir, skip string, fn func(file, rel string, info fs.FileInfo) error) error {
+	return walkFiles(dir, skip, func(file, rel string, info fs.FileInfo) error {
+		if strings.HasPrefix(filepath.Base(file), ".") {
+			return nil
+		}
+
+		return fn(file, rel, info)
+	})
+}
+
+// walkFiles calls fn for every regular file under dir (following links into a -dedupe pool), except skip.
+func walkFiles(dir, skip string, fn func(file, rel string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || file == skip {
+			return err
+		}
+		info, err := os.Stat(file)
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		return fn(file, filepath.ToSlash(rel), info)
+	})
+}
+
+func writeZip(w io.Writer, dir, skip string) error {
+	zw := zip.NewWriter(w)
+	if err := walkCourseFiles(dir, skip, func(file, rel string, info fs.FileInfo) error {
+		fh, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		fh.Name = rel
+		// Media is already compressed, so deflating it only burns CPU.
+		fh.Method = zip.Store
+		if compressible(rel) {
+			fh.Method = zip.Deflate
+		}
+		dst, err := zw.CreateHeader(fh)
+		if err != nil {
+			return err
+		}
+
+		return copyFile(dst, file)
+	}); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeTgz(w io.Writer, dir, skip string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := walkCourseFiles(dir, skip, func(file, rel string, info fs.FileInfo) error {
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		return copyFile(tw, file)
+	}); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+func compressible(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".txt", ".json", ".jsonl", ".md", ".srt", ".vtt", ".html":
+		return true
+	}
+
+	return false
+}
+
+func copyFile(w io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	_, err = io.Copy(w, f)
+
+	return err
+}
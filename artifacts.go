@@ -0,0 +1,91 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Artifact kinds in the -jsonl log.
+const (
+	artifactCourse     = "course"     // The course details, once parsed.
+	artifactItem       = "item"       // An item's metadata, from the TOC.
+	artifactTranscript = "transcript" // An item's transcript, with its captions.
+	artifactQuiz       = "quiz"       // A chapter quiz's questions.
+	artifactFile       = "file"       // A downloaded file, e.g. a video.
+)
+
+// Artifact is a line of the -jsonl log: something scraped, as it was saved.
+type Artifact struct {
+	Time   time.Time      `json:"time"`
+	Kind   string         `json:"kind"`
+	Course *Course        `json:"course,omitempty"`
+	Item   *VideoEntry    `json:"item,omitempty"`
+	Quiz   []QuizQuestion `json:"quiz,omitempty"`
+	File   string         `json:"file,omitempty"`
+	Size   int64          `json:"size,omitempty"`
+}
+
+// artifactLog appends every artifact to the -jsonl file as soon as it's saved, syncing each line to disk so the log
+// holds up to a crash. A nil log records nothing.
+type artifactLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func openArtifactLog(filename string) (*artifactLog, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+
+	return &artifactLog{f: f}, nil
+}
+
+// record appends a, failing softly since the log is a by-product of the download.
+func (l *artifactLog) record(ctx context.Context, a Artifact) {
+	if l == nil {
+		return
+	}
+	a.Time = time.Now().UTC()
+	b, err := json.Marshal(a)
+	if err != nil {
+		logf(ctx, tr("⚠️ failed to log %s: %v"), a.Kind, err)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(b, '\n')); err != nil {
+		logf(ctx, tr("⚠️ failed to log %s: %v"), a.Kind, err)
+		return
+	}
+	_ = l.f.Sync()
+}
+
+// recordCourse logs the course details, then the metadata of each of its items.
+func recordCourse(ctx context.Context, l *artifactLog, course *Course) {
+	if l == nil {
+		return
+	}
+	details := *course
+	details.Videos = nil
+	l.record(ctx, Artifact{Kind: artifactCourse, Course: &details})
+	for _, v := range course.Videos {
+		l.record(ctx, Artifact{Kind: artifactItem, Item: &v})
+	}
+}
+
+// recordFile logs the file saved at filename.
+func (l *artifactLog) recordFile(ctx context.Context, filename string) {
+	if l == nil {
+		return
+	}
+	a := Artifact{Kind: artifactFile, File: filename}
+	if info, err := os.Stat(filename); err == nil {
+		a.Size = info.Size()
+	}
+	l.record(ctx, a)
+}
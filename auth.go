@@ -0,0 +1,343 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// sessionAccount is the keychain entry holding the LinkedIn session cookies.
+const sessionAccount = "session"
+
+// authCommands are the `lld auth` subcommands.
+func authCommands() map[string]func(args []string) {
+	return map[string]func(args []string){
+		"export": runAuthExport,
+		"status": runAuthStatus,
+		"logout": runAuthLogout,
+	}
+}
+
+func runAuth(args []string) {
+	if len(args) > 0 {
+		if cmd, ok := authCommands()[args[0]]; ok {
+			cmd(args[1:])
+			return
+		}
+	}
+	log.Fatal(tr("❌ usage: lld auth export|status|logout"))
+}
+
+// runAuthExport prints the stored session cookies, for explicitly taking them out of the keychain.
+func runAuthExport(args []string) {
+	var source, format string
+	flags := flag.NewFlagSet("auth export", flag.ExitOnError)
+	flags.StringVar(&source, "credential-source", "", "Where the session is stored (see lld -h).")
+	flags.StringVar(&format, "format", "json", "Output format: json, or cookies.txt for curl, yt-dlp, and friends.")
+	_ = flags.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cookies, err := loadSession(ctx, source)
+	if err != nil {
+		log.Fatalf(tr("❌ no stored session (log in with -session first): %v"), err)
+	}
+	switch format {
+	case "json":
+		b, err := json.Marshal(cookies)
+		if err != nil {
+			log.Fatal(errorText(err))
+		}
+		fmt.Println(string(b))
+	case "cookies.txt":
+		fmt.Print(netscapeCookies(cookies))
+	default:
+		log.Fatalf(tr("❌ unsupported -format %q, expected json or cookies.txt"), format)
+	}
+}
+
+// netscapeCookies renders cookies in the Netscape cookies.txt format that curl, wget, and yt-dlp read.
+func netscapeCookies(cookies []*network.Cookie) string {
+	var sb strings.Builder
+	sb.WriteString("# Netscape HTTP Cookie File\n")
+	for _, c := range cookies {
+		domain := c.Domain
+		if c.HTTPOnly {
+			// curl's convention, which keeps the line from being taken for a comment by anything else.
+			domain = "#HttpOnly_" + domain
+		}
+		expires := int64(0) // Session cookie.
+		if c.Expires > 0 {
+			expires = int64(c.Expires)
+		}
+		fmt.Fprintf(&sb, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, netscapeBool(strings.HasPrefix(c.Domain, ".")), c.Path, netscapeBool(c.Secure), expires, c.Name, c.Value)
+	}
+
+	return sb.String()
+}
+
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+
+	return "FALSE"
+}
+
+// runAuthStatus checks the stored session against LinkedIn, without starting a browser.
+func runAuthStatus(args []string) {
+	var (
+		source string
+		opts   options
+	)
+	flags := flag.NewFlagSet("auth status", flag.ExitOnError)
+	flags.StringVar(&source, "credential-source", "", "Where the session is stored (see lld -h).")
+	flags.StringVar(&opts.baseURL, "base-url", defaultBaseURL, "Home page of LinkedIn Learning, or of a Learning Hub (see lld -h).")
+	_ = flags.Parse(args)
+	if err := setupBaseURL(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cookies, err := loadSession(ctx, source)
+	if err != nil {
+		log.Fatalf(tr("❌ no stored session (log in with -session first): %v"), err)
+	}
+	for _, c := range cookies {
+		// li_at is the actual login; LinkedIn gives it about a year, but revokes it early on logout or a password change.
+		if c.Name == "li_at" && c.Expires > 0 {
+			expires := time.Unix(int64(c.Expires), 0)
+			log.Printf(tr("⏳ Session cookie expires %s (in %d days)\n"), expires.Format(time.DateOnly), int(time.Until(expires).Hours()/24))
+		}
+	}
+	log.Printf(tr("🎫 License: %s\n"), tr(licenseType(cookies)))
+
+	name, err := whoami(ctx, opts.learningBase, cookies)
+	if err != nil {
+		log.Fatalf(tr("❌ the stored session no longer works: %v"), err)
+	}
+	log.Printf(tr("✅ Logged in as %s\n"), name)
+}
+
+// runAuthLogout wipes the session stored in the keychain.
+func runAuthLogout(_ []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := keychainDelete(ctx, sessionAccount); err != nil {
+		log.Fatalf(tr("❌ failed to remove the stored session: %v"), err)
+	}
+	log.Println(tr("👋 Stored session removed."))
+}
+
+// licenseType guesses the kind of LinkedIn Learning license from the session: SSO logins carry an enterprise
+// profile context cookie.
+func licenseType(cookies []*network.Cookie) string {
+	for _, c := range cookies {
+		if c.Name == "li_ep_auth_context" {
+			return "enterprise (SSO)"
+		}
+	}
+
+	return "individual"
+}
+
+// whoami asks the API of base's host whose session this is; an expired session is redirected to the login page
+// instead.
+func whoami(ctx context.Context, base *url.URL, cookies []*network.Cookie) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, learningHost(base, "/voyager/api/me"), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cookies {
+		req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+		if c.Name == "JSESSIONID" {
+			req.Header.Set("Csrf-Token", strings.Trim(c.Value, `"`))
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", newStatusError(resp)
+	}
+
+	var me struct {
+		MiniProfile struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"miniProfile"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&me); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(me.MiniProfile.FirstName + " " + me.MiniProfile.LastName), nil
+}
+
+// saveSession stores the browser's LinkedIn cookies in the OS keychain, so later runs can skip SSO.
+func saveSession(ctx context.Context) error {
+	cookies, err := sessionCookies(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(cookies)
+	if err != nil {
+		return err
+	}
+
+	return keychainSet(ctx, sessionAccount, string(b))
+}
+
+// sessionCookies returns the browser's LinkedIn session cookies.
+func sessionCookies(ctx context.Context) ([]*network.Cookie, error) {
+	var cookies []*network.Cookie
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		cookies, err = network.GetCookies().WithURLs(sessionCookieURLs(learningBaseFrom(ctx))).Do(ctx)
+		return err
+	}))
+
+	return cookies, err
+}
+
+// readCredential fetches the session cookies (as exported by `lld auth export`) from source:
+//
+//   - "keychain" (or empty): the OS keychain, where -session keeps them.
+//   - "env:NAME": the environment variable NAME.
+//   - "op://vault/item/field": a 1Password secret reference, read with the op CLI.
+//   - "vault:path#field": a HashiCorp Vault KV secret, read with the vault CLI.
+func readCredential(ctx context.Context, source string) (string, error) {
+	switch {
+	case isKeychain(source):
+		return keychainGet(ctx, sessionAccount)
+	case strings.HasPrefix(source, "env:"):
+		name := strings.TrimPrefix(source, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("$%s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(source, "op://"):
+		return outputCLI(ctx, "op", "read", "--no-newline", source)
+	case strings.HasPrefix(source, "vault:"):
+		path, field, ok := strings.Cut(strings.TrimPrefix(source, "vault:"), "#")
+		if !ok {
+			return "", fmt.Errorf("bad credential source %q, expected vault:path#field", source)
+		}
+		return outputCLI(ctx, "vault", "kv", "get", "-field="+field, path)
+	}
+
+	return "", fmt.Errorf("unsupported credential source %q", source)
+}
+
+// isKeychain is whether the credential source is the OS keychain, rather than an external secret store.
+func isKeychain(source string) bool {
+	return source == "" || source == "keychain"
+}
+
+// loadSession returns the session cookies from the credential source.
+func loadSession(ctx context.Context, source string) ([]*network.Cookie, error) {
+	session, err := readCredential(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	var cookies []*network.Cookie
+	if err := json.Unmarshal([]byte(session), &cookies); err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return nil, errors.New("the stored session is empty")
+	}
+
+	return cookies, nil
+}
+
+// restoreSession puts the stored cookies into the browser and checks that they still get us in.
+func restoreSession(ctx context.Context, source string) error {
+	cookies, err := loadSession(ctx, source)
+	if err != nil {
+		return err
+	}
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		p := &network.CookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			SameSite: c.SameSite,
+		}
+		if c.Expires > 0 {
+			sec, frac := math.Modf(c.Expires)
+			t := cdp.TimeSinceEpoch(time.Unix(int64(sec), int64(frac*1e9)))
+			p.Expires = &t
+		}
+		params = append(params, p)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := chromedp.Run(checkCtx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return network.SetCookies(params).Do(ctx)
+		}),
+		navigate(learningHome(learningBaseFrom(ctx))),
+		waitVisible(selLoggedIn, new(string)),
+	); err != nil {
+		return withKind(ErrAuthExpired, err)
+	}
+
+	return nil
+}
+
+// sessionLogin logs in with the stored session if it's still good, falling back to SSO. Only the keychain is written
+// back to; external secret stores are the deployment's to keep current.
+func sessionLogin(ctx context.Context, opts *options) error {
+	err := restoreSession(ctx, opts.credentialSource)
+	if err == nil {
+		logln(ctx, tr("🔑 Restored the stored session."))
+		return nil
+	}
+	logf(ctx, tr("⚠️ No usable stored session, logging in again: %v"), err)
+
+	if err := ssoLogin(ctx, opts.ssoURL); err != nil {
+		return err
+	}
+	if !opts.session || !isKeychain(opts.credentialSource) {
+		return nil
+	}
+	if err := saveSession(ctx); err != nil {
+		logf(ctx, tr("⚠️ failed to store the session in the OS keychain: %v"), err)
+	} else {
+		logln(ctx, tr("🔐 Session stored in the OS keychain."))
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+type AuthorCourse struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Local bool   `json:"local"`
+}
+
+type AuthorIndex struct {
+	Name    string         `json:"name"`
+	URL     string         `json:"url"`
+	Courses []AuthorCourse `json:"courses"`
+	Updated time.Time      `json:"updated"`
+}
+
+// authorIndexFile aggregates every author that has been looked up, keyed by author URL.
+const authorIndexFile = "authors.json"
+
+const authorParseJS = `(sel, base = "/learning/") => {
+	const skip = ["instructors", "paths", "search", "topics", "browse", "me", "subscription"];
+	const seen = new Set();
+	const courses = [];
+	for (const a of document.querySelectorAll("a[href*='" + base + "']")) {
+		const u = new URL(a.href);
+		if (!u.pathname.startsWith(base)) continue;
+		const parts = u.pathname.slice(base.length).split("/").filter(Boolean);
+		if (parts.length !== 1 || skip.includes(parts[0])) continue;
+		const url = u.origin + base + parts[0];
+		const title = (a.querySelector("h3, [class*='title']") || a).innerText.trim();
+		if (!title || seen.has(url)) continue;
+		seen.add(url);
+		courses.push({ title: title, url: url });
+	}
+	return { name: document.querySelector("h1")?.innerText.trim() || "", courses: courses };
+}`
+
+// showMoreJS clicks a "Show more" button if there is one, reporting whether it did.
+const showMoreJS = `() => {
+	const button = Array.from(document.querySelectorAll("button"))
+		.find(b => /show more|see more|load more/i.test(b.innerText));
+	if (!button || button.disabled) return false;
+	button.click();
+	return true;
+}`
+
+func runAuthor(args []string) {
+	var (
+		opts     options
+		download bool
+	)
+	flags := flag.NewFlagSet("author", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s author [flags] URL\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	registerFlags(flags, &opts)
+	flags.BoolVar(&download, "download", false, "Whether or not to download every course not already saved locally.")
+	_ = flags.Parse(args)
+	if err := setup(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+	defer shutdownTracing()
+	defer stopProfiling(&opts)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if download && !opts.dlVideos && !opts.dlTranscripts {
+		log.Fatal(tr("❌ You must specify at least one of -transcripts or -videos to download."))
+	}
+
+	ctx, cancel := newChromeDPCtx(opts.timeout, &opts)
+	defer cancel()
+
+	if err := login(ctx, &opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+
+	author, err := parseAuthor(ctx, flags.Arg(0), opts.outDir)
+	if err != nil {
+		log.Fatalf(tr("❌ Failed to parse author: %v"), err)
+	}
+	logf(ctx, tr("👤 %s has %d course(s)\n"), author.Name, len(author.Courses))
+	for _, c := range author.Courses {
+		mark := "⬜"
+		if c.Local {
+			mark = "✅"
+		}
+		logf(ctx, "%s %s (%s)\n", mark, c.Title, c.URL)
+	}
+	if err := saveAuthorIndex(ctx, author, opts.outDir); err != nil {
+		log.Fatal(errorText(err))
+	}
+
+	if !download {
+		return
+	}
+	missing := 0
+	for _, c := range author.Courses {
+		if !c.Local {
+			missing++
+		}
+	}
+	// Archives written one after another onto stdout don't make up one that can be read back.
+	if opts.archiveStdout && missing > 1 {
+		log.Fatalf(tr("❌ -archive-stdout takes a single course, but %d are to be downloaded"), missing)
+	}
+	for i, c := range author.Courses {
+		if c.Local {
+			continue
+		}
+		logf(ctx, "📦 [%d/%d] %s\n", i+1, len(author.Courses), c.Title)
+		slug, err := courseSlug(opts.learningBase, c.URL)
+		if err != nil {
+			logf(ctx, tr("%v -> skipping."), errorText(err))
+			continue
+		}
+		if _, err := downloadCourse(ctx, &opts, c.URL, filepath.Join(opts.outDir, slug)); err != nil {
+			logf(ctx, tr("%v -> skipping."), errorText(err))
+			continue
+		}
+		if err := finishCourse(ctx, &opts, c.URL, filepath.Join(opts.outDir, slug)); err != nil {
+			logln(ctx, errorText(err))
+		}
+		author.Courses[i].Local = true
+		if err := saveAuthorIndex(ctx, author, opts.outDir); err != nil {
+			logln(ctx, errorText(err))
+		}
+	}
+	logln(ctx, tr("✅ All courses info saved."))
+}
+
+func parseAuthor(ctx context.Context, authorURL, dir string) (*AuthorIndex, error) {
+	logln(ctx, tr("👤 Parsing author page."))
+	author := AuthorIndex{URL: authorURL}
+	if err := chromedp.Run(ctx,
+		navigate(authorURL),
+		waitVisible(selHeading, new(string)),
+	); err != nil {
+		return nil, err
+	}
+	// Course lists are paginated behind a "Show more" button; keep clicking until it goes away.
+	for range 50 {
+		var more bool
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(pageScript(ctx, jsShowMore), &more),
+		); err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+		if err := chromedp.Run(ctx, chromedp.Sleep(time.Second)); err != nil {
+			return nil, err
+		}
+	}
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(pageScript(ctx, jsParseAuthor), &author),
+	); err != nil {
+		return nil, err
+	}
+
+	for i, c := range author.Courses {
+		slug, err := courseSlug(learningBaseFrom(ctx), c.URL)
+		if err != nil {
+			return nil, err
+		}
+		author.Courses[i].Local = hasFiles(filepath.Join(dir, slug))
+	}
+	author.Updated = time.Now()
+
+	return &author, nil
+}
+
+// hasFiles reports whether dir exists and contains at least one entry.
+func hasFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	return err == nil && len(entries) > 0
+}
+
+// saveAuthorIndex merges author into the aggregated index in dir.
+func saveAuthorIndex(ctx context.Context, author *AuthorIndex, dir string) error {
+	filename := filepath.Join(dir, authorIndexFile)
+	index := make(map[string]*AuthorIndex)
+	b, err := os.ReadFile(filename)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	default:
+		if err := json.Unmarshal(b, &index); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+	}
+	index[author.URL] = author
+
+	b, err = json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	if err := os.WriteFile(filename, b, 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 author index saved: %s\n"), filename)
+
+	return nil
+}
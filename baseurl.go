@@ -0,0 +1,78 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// defaultBaseURL is LinkedIn Learning's home page. Enterprise Learning Hubs on a domain of their own are given with
+// -base-url instead.
+const defaultBaseURL = "https://www.linkedin.com/learning/"
+
+// setupBaseURL checks -base-url and makes it the learningBase, where courses are found, always ending with a slash.
+func setupBaseURL(opts *options) error {
+	if opts.baseURL == "" {
+		opts.learningBase = defaultLearningBase()
+		return nil
+	}
+	u, err := url.Parse(opts.baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("unsupported -base-url %q, expected a URL like %s", opts.baseURL, defaultBaseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	u.RawPath, u.RawQuery, u.Fragment = "", "", ""
+	opts.learningBase = u
+
+	return nil
+}
+
+// defaultLearningBase is where courses are found without -base-url.
+func defaultLearningBase() *url.URL {
+	return &url.URL{Scheme: "https", Host: "www.linkedin.com", Path: "/learning/"}
+}
+
+type learningBaseKey struct{}
+
+// withLearningBase returns ctx with the -base-url of pages browsed with it.
+func withLearningBase(ctx context.Context, base *url.URL) context.Context {
+	if base == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, learningBaseKey{}, base)
+}
+
+func learningBaseFrom(ctx context.Context) *url.URL {
+	if base, ok := ctx.Value(learningBaseKey{}).(*url.URL); ok {
+		return base
+	}
+
+	return defaultLearningBase()
+}
+
+// learningHome is the home page of base, which only shows who's logged in once they are.
+func learningHome(base *url.URL) string {
+	return base.String()
+}
+
+// learningURL is the URL of the course (or other page) called slug under base.
+func learningURL(base *url.URL, slug string) string {
+	return base.JoinPath(slug).String()
+}
+
+// learningHost is the URL of path on the host of base, for LinkedIn's APIs.
+func learningHost(base *url.URL, path string) string {
+	return (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: path}).String()
+}
+
+// sessionCookieURLs are the URLs whose cookies make up a session: a Learning Hub's own domain, and LinkedIn's that it
+// signs in through.
+func sessionCookieURLs(base *url.URL) []string {
+	if learningHome(base) == defaultBaseURL {
+		return []string{defaultBaseURL}
+	}
+
+	return []string{learningHome(base), defaultBaseURL}
+}
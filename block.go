@@ -0,0 +1,83 @@
+package lld
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// blockedPatterns are requests nobody needs for scraping: trackers, ads, and the heavy page furniture.
+func blockedPatterns() []*fetch.RequestPattern {
+	patterns := []*fetch.RequestPattern{
+		{URLPattern: "*", ResourceType: network.ResourceTypeImage},
+		{URLPattern: "*", ResourceType: network.ResourceTypeFont},
+	}
+	for _, host := range []string{
+		"*google-analytics.com*",
+		"*googletagmanager.com*",
+		"*doubleclick.net*",
+		"*px.ads.linkedin.com*",
+		"*snap.licdn.com*",
+		"*linkedin.com/li/track*",
+		"*linkedin.com/sensorCollect*",
+		"*bat.bing.com*",
+		"*facebook.net*",
+	} {
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: host})
+	}
+
+	return patterns
+}
+
+// noMediaJS keeps the player from ever starting (or buffering) a video on the pages we visit.
+const noMediaJS = `(() => {
+	HTMLMediaElement.prototype.play = function () { this.muted = true; this.preload = "none"; return Promise.resolve(); };
+	new MutationObserver(() => document.querySelectorAll("video, audio").forEach(m => {
+		m.muted = true;
+		m.autoplay = false;
+		m.preload = "none";
+		if (!m.paused) m.pause();
+	})).observe(document, { childList: true, subtree: true });
+})()`
+
+// prepareBrowser sets up the logged in browser for scraping according to opts.
+func prepareBrowser(ctx context.Context, opts *options) error {
+	if err := opts.har.listen(ctx); err != nil {
+		return err
+	}
+	if opts.replay != nil {
+		return opts.replay.serve(ctx)
+	}
+	var patterns []*fetch.RequestPattern
+	if opts.blockRequests {
+		patterns = blockedPatterns()
+	}
+	// Nothing will be saved from the player on transcript-only runs, so don't waste bandwidth (or rate limit) on it.
+	transcriptOnly := opts.dlTranscripts && !opts.dlVideos
+	if transcriptOnly {
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*", ResourceType: network.ResourceTypeMedia})
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			_, err := page.AddScriptToEvaluateOnNewDocument(noMediaJS).Do(ctx)
+			return err
+		})); err != nil {
+			return err
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	// Everything that matches a pattern is paused by Chrome, so every paused request is one to fail.
+	chromedp.ListenTarget(ctx, func(ev any) {
+		if e, ok := ev.(*fetch.EventRequestPaused); ok {
+			go func() {
+				_ = chromedp.Run(ctx, fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient))
+			}()
+		}
+	})
+
+	return chromedp.Run(ctx, fetch.Enable().WithPatterns(patterns))
+}
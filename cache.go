@@ -0,0 +1,79 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// courseCache is a course's parsed TOC as saved on disk, so later runs can skip navigating the classroom.
+type courseCache struct {
+	URL    string       `json:"url"`
+	Saved  time.Time    `json:"saved"`
+	Videos []VideoEntry `json:"videos"`
+}
+
+// courseCacheFile is where the TOC of courseURL (under base) is cached, under the user's cache directory.
+func courseCacheFile(base *url.URL, courseURL string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	slug, err := courseSlug(base, courseURL)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "lld", "courses", slug+".json"), nil
+}
+
+// loadCourseCache returns the cached TOC of courseURL if there is one younger than -cache-ttl and -refresh wasn't given.
+func loadCourseCache(ctx context.Context, courseURL string, opts *options) ([]VideoEntry, bool) {
+	if opts.refresh || opts.cacheTTL <= 0 {
+		return nil, false
+	}
+	file, err := courseCacheFile(opts.learningBase, courseURL)
+	if err != nil {
+		return nil, false
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	landing, err := courseLandingURL(opts.learningBase, courseURL)
+	if err != nil {
+		return nil, false
+	}
+	var c courseCache
+	if err := json.Unmarshal(b, &c); err != nil || c.URL != landing || time.Since(c.Saved) > opts.cacheTTL {
+		return nil, false
+	}
+	logf(ctx, tr("🗃️ Using the course structure cached %s ago (-refresh to re-parse).\n"), time.Since(c.Saved).Round(time.Minute))
+
+	return c.Videos, true
+}
+
+// saveCourseCache caches the freshly parsed TOC of courseURL, under base.
+func saveCourseCache(base *url.URL, courseURL string, videos []VideoEntry) error {
+	file, err := courseCacheFile(base, courseURL)
+	if err != nil {
+		return err
+	}
+	landing, err := courseLandingURL(base, courseURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(file), err)
+	}
+	b, err := json.MarshalIndent(courseCache{URL: landing, Saved: time.Now(), Videos: videos}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, b, 0o600)
+}
@@ -0,0 +1,134 @@
+package lld
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Cue is a single timed caption.
+type Cue struct {
+	Start float64 `json:"start"` // Seconds.
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// captionsJS reads the cues of the player's caption track, turning the track on (hidden) if needed so they load.
+const captionsJS = `async sel => {
+	const video = document.querySelector(sel.video);
+	if (!video) return [];
+	const track = Array.from(video.textTracks).find(t => t.kind === "captions" || t.kind === "subtitles");
+	if (!track) return [];
+	if (track.mode === "disabled") track.mode = "hidden";
+	for (let i = 0; i < 50 && !(track.cues && track.cues.length); i++) {
+		await new Promise(r => setTimeout(r, 100));
+	}
+	return Array.from(track.cues || []).map(c => ({ start: c.startTime, end: c.endTime, text: c.text }));
+}`
+
+// scrapeCaptions returns the timed captions of the current video, if it has any.
+func scrapeCaptions(ctx context.Context) []Cue {
+	var cues []Cue
+	if err := chromedp.Run(ctx, chromedp.Evaluate(pageScript(ctx, jsCaptions), &cues, awaitPromise)); err != nil {
+		logf(ctx, tr("⚠️ failed to read captions: %v"), err)
+		return nil
+	}
+
+	return cues
+}
+
+// writeVTT saves cues as a WebVTT file, noting lang (if known) in the header.
+func writeVTT(ctx context.Context, filename string, cues []Cue, lang string) error {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n")
+	if lang != "" {
+		sb.WriteString("Language: " + lang + "\n")
+	}
+	for i, c := range cues {
+		fmt.Fprintf(&sb, "\n%d\n%s --> %s\n%s\n", i+1, vttTime(c.Start), vttTime(c.End), c.Text)
+	}
+	if err := os.WriteFile(filename, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 captions saved: %s\n"), filename)
+
+	return nil
+}
+
+func vttTime(secs float64) string {
+	ms := int64(secs*1000 + 0.5)
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// parseVTT reads back a WebVTT file written by writeVTT (or any simple one).
+func parseVTT(filename string) ([]Cue, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var (
+		cues []Cue
+		cur  *Cue
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+			cur = nil
+		case strings.Contains(line, "-->"):
+			start, end, _ := strings.Cut(line, "-->")
+			end, _, _ = strings.Cut(strings.TrimSpace(end), " ") // Drop any cue settings.
+			cues = append(cues, Cue{Start: parseVTTTime(start), End: parseVTTTime(end)})
+			cur = &cues[len(cues)-1]
+		case cur != nil:
+			if cur.Text != "" {
+				cur.Text += "\n"
+			}
+			cur.Text += line
+		}
+	}
+
+	return cues, sc.Err()
+}
+
+// vttLanguage returns the Language from the header of a WebVTT file, if any.
+func vttLanguage(filename string) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() && strings.TrimSpace(sc.Text()) != "" {
+		if lang, ok := strings.CutPrefix(sc.Text(), "Language:"); ok {
+			return strings.TrimSpace(lang)
+		}
+	}
+
+	return ""
+}
+
+// parseVTTTime parses [hh:]mm:ss.ttt into seconds.
+func parseVTTTime(s string) float64 {
+	var secs float64
+	for _, part := range strings.Split(strings.TrimSpace(s), ":") {
+		v, _ := strconv.ParseFloat(part, 64)
+		secs = secs*60 + v
+	}
+
+	return secs
+}
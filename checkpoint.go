@@ -0,0 +1,188 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checkpoint records which stages of processing an item are done, in a hidden file next to its files, so that a run
+// resumed after a failure (or lld retry) only redoes what didn't go through: say, the video that failed, but not the
+// transcript that was saved.
+type checkpoint struct {
+	Stages map[string]stageDone `json:"stages"`
+
+	filename string // The item's, without extension.
+}
+
+// stageDone is when a stage was done, and with what, where doing it differently means doing it again.
+type stageDone struct {
+	At   time.Time `json:"at"`
+	With string    `json:"with,omitempty"`
+}
+
+type checkpointKey struct{}
+
+func withCheckpoint(ctx context.Context, c *checkpoint) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, c)
+}
+
+func checkpointFrom(ctx context.Context) *checkpoint {
+	c, _ := ctx.Value(checkpointKey{}).(*checkpoint)
+	return c
+}
+
+// checkpointFile is where the checkpoint of the item saved as filename is kept.
+func checkpointFile(filename string) string {
+	return filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".stages.json")
+}
+
+// loadCheckpoint reads the checkpoint of the item saved as filename, starting afresh without one. -refresh and -sync
+// also start afresh, as they're for checking everything again.
+func loadCheckpoint(filename string, opts *options) *checkpoint {
+	c := &checkpoint{Stages: map[string]stageDone{}, filename: filename}
+	if opts.refresh || opts.sync {
+		return c
+	}
+	if b, err := os.ReadFile(checkpointFile(filename)); err == nil {
+		_ = json.Unmarshal(b, c)
+	}
+	if c.Stages == nil {
+		c.Stages = map[string]stageDone{}
+	}
+
+	return c
+}
+
+// itemStages are the stages that save what was asked for of video from its page.
+func itemStages(video VideoEntry, opts *options) []string {
+	switch video.Type {
+	case itemDocument:
+		return []string{stageDocument}
+	case itemQuiz:
+		return []string{stageQuiz}
+	}
+	var stages []string
+	if opts.dlTranscripts {
+		stages = append(stages, stageTranscript)
+	}
+	if opts.dlVideos {
+		stages = append(stages, stageMedia)
+	}
+
+	return stages
+}
+
+// stageKinds are the kinds of file (see fileKind) a stage saves, one of which must still be there for it to count as
+// done.
+func stageKinds(stage string) []string {
+	switch stage {
+	case stageTranscript:
+		return []string{"transcript"}
+	case stageMedia:
+		return []string{itemVideo, itemAudio}
+	case stageDocument:
+		return []string{itemDocument}
+	case stageQuiz:
+		return []string{itemQuiz}
+	}
+
+	return nil
+}
+
+// done is whether stage was done with with, and what it saved is still there.
+func (c *checkpoint) done(stage, with string) bool {
+	if c == nil {
+		return false
+	}
+	s, ok := c.Stages[stage]
+	if !ok || s.With != with {
+		return false
+	}
+	kinds := stageKinds(stage)
+	if len(kinds) == 0 {
+		return true
+	}
+	files := itemFiles(c.filename)
+
+	return slices.ContainsFunc(files, func(f string) bool { return slices.Contains(kinds, fileKind(f)) })
+}
+
+// doneAll is whether every one of stages is done.
+func (c *checkpoint) doneAll(stages []string) bool {
+	return len(stages) > 0 && !slices.ContainsFunc(stages, func(s string) bool { return !c.done(s, "") })
+}
+
+// run does stage with fn unless it's already done with with, recording it once it is. Redoing a stage that saves
+// files means what comes after them, like post-processing, has to be redone too.
+func (c *checkpoint) run(ctx context.Context, stage, with string, fn func() error) error {
+	if c.done(stage, with) {
+		logf(ctx, tr("⏭️ The %s stage is already done, skipping it (-refresh to redo it).\n"), tr(stage))
+		return nil
+	}
+	if err := fn(); err != nil {
+		return inStage(stage, err)
+	}
+	if c != nil && len(stageKinds(stage)) > 0 {
+		delete(c.Stages, stagePostProcess)
+		delete(c.Stages, stageUpload)
+	}
+	c.mark(ctx, stage, with)
+
+	return nil
+}
+
+// mark records stage as done with with, now.
+func (c *checkpoint) mark(ctx context.Context, stage, with string) {
+	if c == nil {
+		return
+	}
+	c.Stages[stage] = stageDone{At: time.Now().UTC(), With: with}
+	b, err := json.Marshal(c)
+	if err == nil {
+		err = os.WriteFile(checkpointFile(c.filename), b, 0o600)
+	}
+	if err != nil {
+		logf(ctx, tr("⚠️ failed to save the progress of %s: %v\n"), c.filename, err)
+	}
+}
+
+// postProcessWith is what post-processing is done with, so that asking for something else redoes it.
+func postProcessWith(opts *options) string {
+	return fmt.Sprintf("ocr=%v burn=%t embed=%t", opts.ocr, opts.burnSubs, opts.embedSubs)
+}
+
+// itemCheckpoint returns the checkpoint of the item file was saved for, loading it into checkpoints the first time,
+// or nil for files that aren't an item's. Items' file names are theirs plus one or more extensions.
+func itemCheckpoint(checkpoints map[string]*checkpoint, file string) *checkpoint {
+	for name := file; strings.Contains(filepath.Base(name), "."); {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		if c, ok := checkpoints[name]; ok {
+			return c
+		}
+		b, err := os.ReadFile(checkpointFile(name))
+		if err != nil {
+			continue
+		}
+		c := &checkpoint{filename: name}
+		if err := json.Unmarshal(b, c); err != nil || c.Stages == nil {
+			return nil
+		}
+		checkpoints[name] = c
+
+		return c
+	}
+
+	return nil
+}
+
+// uploadedSince is whether the item's files were uploaded to with after modified.
+func (c *checkpoint) uploadedSince(with string, modified time.Time) bool {
+	s, ok := c.Stages[stageUpload]
+	return ok && s.With == with && !modified.After(s.At)
+}
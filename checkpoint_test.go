@@ -0,0 +1,130 @@
+package lld_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+func TestCheckpointRun(t *testing.T) {
+	t.Parallel()
+	errStage := errors.New("download failed")
+	tests := []struct {
+		name       string
+		saved      string   // The checkpoint left by a previous run, if any.
+		files      []string // The extensions of the item's files.
+		refresh    bool
+		stage      string
+		with       string
+		fnErr      error
+		wantRun    bool
+		wantDone   bool
+		wantRedone []string // Stages that have to be done again after this one.
+	}{
+		{name: "first run", stage: "transcript", wantRun: true, wantDone: true},
+		{
+			name:     "done",
+			saved:    `{"stages":{"transcript":{"at":"2026-10-01T12:00:00Z"}}}`,
+			files:    []string{".txt"},
+			stage:    "transcript",
+			wantDone: true,
+		},
+		{
+			name:     "done with the same",
+			saved:    `{"stages":{"postprocess":{"at":"2026-10-01T12:00:00Z","with":"ocr=false burn=true embed=false"}}}`,
+			stage:    "postprocess",
+			with:     "ocr=false burn=true embed=false",
+			wantDone: true,
+		},
+		{
+			name:     "done with something else",
+			saved:    `{"stages":{"postprocess":{"at":"2026-10-01T12:00:00Z","with":"ocr=false burn=true embed=false"}}}`,
+			stage:    "postprocess",
+			with:     "ocr=false burn=false embed=true",
+			wantRun:  true,
+			wantDone: true,
+		},
+		{
+			name:     "files gone",
+			saved:    `{"stages":{"media":{"at":"2026-10-01T12:00:00Z"}}}`,
+			files:    []string{".txt", ".vtt"},
+			stage:    "media",
+			wantRun:  true,
+			wantDone: true,
+		},
+		{
+			name: "redone files",
+			saved: `{"stages":{"media":{"at":"2026-10-01T12:00:00Z"},` +
+				`"postprocess":{"at":"2026-10-01T12:00:00Z"},"upload":{"at":"2026-10-01T12:00:00Z"}}}`,
+			stage:      "media",
+			wantRun:    true,
+			wantDone:   true,
+			wantRedone: []string{"postprocess", "upload"},
+		},
+		{
+			name:     "-refresh",
+			saved:    `{"stages":{"transcript":{"at":"2026-10-01T12:00:00Z"}}}`,
+			files:    []string{".txt"},
+			refresh:  true,
+			stage:    "transcript",
+			wantRun:  true,
+			wantDone: true,
+		},
+		{
+			name:     "damaged checkpoint",
+			saved:    `{"stages":`,
+			files:    []string{".mp4"},
+			stage:    "media",
+			wantRun:  true,
+			wantDone: true,
+		},
+		{name: "failed", stage: "media", fnErr: errStage, wantRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			filename := filepath.Join(t.TempDir(), "1_01_Intro")
+			if tt.saved != "" {
+				if err := os.WriteFile(filepath.Join(filepath.Dir(filename), ".1_01_Intro.stages.json"), []byte(tt.saved), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, ext := range tt.files {
+				if err := os.WriteFile(filename+ext, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ran := false
+			err := lld.LoadCheckpoint(filename, tt.refresh).Run(tt.stage, tt.with, func() error {
+				ran = true
+				return tt.fnErr
+			})
+			if !errors.Is(err, tt.fnErr) {
+				t.Errorf("run() error = %v, want %v", err, tt.fnErr)
+			}
+			if ran != tt.wantRun {
+				t.Errorf("run() ran the stage: %t, want %t", ran, tt.wantRun)
+			}
+
+			// What's left for the next run.
+			for _, ext := range []string{".txt", ".mp4"} {
+				if err := os.WriteFile(filename+ext, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := lld.LoadCheckpoint(filename, false)
+			if done := c.Done(tt.stage, tt.with); done != tt.wantDone {
+				t.Errorf("done(%s) after run() = %t, want %t", tt.stage, done, tt.wantDone)
+			}
+			for _, stage := range tt.wantRedone {
+				if c.Done(stage, "") {
+					t.Errorf("done(%s) after run(%s) = true, want false", stage, tt.stage)
+				}
+			}
+		})
+	}
+}
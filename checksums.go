@@ -0,0 +1,86 @@
+package lld
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checksumsFileName lists the SHA-256 of every file of a course, in the format of `sha256sum`.
+const checksumsFileName = "SHA256SUMS"
+
+// writeChecksums writes the SHA256SUMS of everything saved in dir (related courses included), which `lld verify` and
+// `sha256sum -c` check. Our hidden bookkeeping and the -archive, which is made afterwards, are left out.
+func writeChecksums(ctx context.Context, opts *options, courseURL, dir string) error {
+	if !opts.checksums {
+		return nil
+	}
+	slug, err := courseSlug(opts.learningBase, courseURL)
+	if err != nil {
+		return err
+	}
+	skip := map[string]bool{
+		checksumsFileName:             true,
+		slug + archiveExt(archiveZip): true,
+		slug + archiveExt(archiveTgz): true,
+	}
+
+	var sb strings.Builder
+	if err := walkCourseFiles(dir, "", func(file, rel string, _ fs.FileInfo) error {
+		if skip[rel] {
+			return nil
+		}
+		sum, err := hashFile(file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s  %s\n", sum, rel)
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", dir, err)
+	}
+	filename := filepath.Join(dir, checksumsFileName)
+	if err := os.WriteFile(filename, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 checksums saved: %s\n"), filename)
+
+	return nil
+}
+
+// verifyChecksums rehashes every file listed in dir's SHA256SUMS, reporting each that's missing or changed to bad.
+func verifyChecksums(dir string, bad func(rel, problem string)) (total int, err error) {
+	filename := filepath.Join(dir, checksumsFileName)
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// sha256sum marks binary mode with a * before the name.
+		want, rel, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		rel = strings.TrimPrefix(strings.TrimPrefix(rel, " "), "*")
+		total++
+		got, err := hashFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		switch {
+		case err != nil:
+			bad(rel, err.Error())
+		case got != want:
+			bad(rel, tr("checksum mismatch"))
+		}
+	}
+
+	return total, scanner.Err()
+}
@@ -0,0 +1,105 @@
+package lld
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// noiseRules are what -clean removes from transcripts out of the box.
+func noiseRules() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		// Sound cues, e.g. "(upbeat music)" or "[laughs]".
+		regexp.MustCompile(`(?i)\s*[\[(][^\])]*\b(?:music|laugh\w*|applause|silence|inaudible|sigh\w*|chuckle\w*|cough\w*|` +
+			`noise|beep\w*|typing|clicking|whoosh\w*)\b[^\])]*[\])]`),
+		// Speaker labels, e.g. ">> NARRATOR:" or "Speaker 1:".
+		regexp.MustCompile(`^\s*>>\s*`),
+		regexp.MustCompile(`^\s*(?:[A-Z][A-Z.'-]*(?: [A-Z][A-Z.'-]*){0,2}|(?i:speaker) \d+):\s+`),
+		// The player's own text, scraped along with the lines.
+		regexp.MustCompile(`(?i)^\s*(?:(?:show|hide) transcript|auto-?scroll|transcript|skip to main content|` +
+			`download (?:courses|transcript)[^\n]*)\s*$`),
+	}
+}
+
+// setupClean compiles the rules of -clean, with the extra ones in -clean-rules, which implies it.
+func setupClean(opts *options) error {
+	if opts.cleanRules != "" {
+		rules, err := loadCleanRules(opts.cleanRules)
+		if err != nil {
+			return err
+		}
+		opts.clean = true
+		opts.cleaners = rules
+	}
+	if opts.clean {
+		opts.cleaners = append(noiseRules(), opts.cleaners...)
+	}
+
+	return nil
+}
+
+// loadCleanRules reads a regular expression per line of filename, skipping blank lines and # comments.
+func loadCleanRules(filename string) ([]*regexp.Regexp, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var rules []*regexp.Regexp
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("bad -clean-rules %s:%d: %w", filename, n, err)
+		}
+		rules = append(rules, re)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	return rules, nil
+}
+
+// cleanText removes every match of rules from each line of text, dropping the lines left empty.
+func cleanText(text string, rules []*regexp.Regexp) string {
+	if len(rules) == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		for _, re := range rules {
+			line = re.ReplaceAllString(line, "")
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// cleanCaptions cleans the text of each cue, dropping the cues left empty.
+func cleanCaptions(cues []Cue, rules []*regexp.Regexp) []Cue {
+	if len(rules) == 0 {
+		return cues
+	}
+	kept := cues[:0]
+	for _, cue := range cues {
+		if cue.Text = cleanText(cue.Text, rules); cue.Text != "" {
+			kept = append(kept, cue)
+		}
+	}
+
+	return kept
+}
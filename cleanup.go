@@ -0,0 +1,143 @@
+package lld
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// cleanup is a run of lld clean over some downloaded courses.
+type cleanup struct {
+	dryRun    bool
+	olderThan time.Duration // Temp files younger than this may belong to a download still running.
+	removed   int
+	pruned    int
+}
+
+// runClean tidies up the given directories: stale temp files, dangling links, empty directories, and index entries of
+// files that are gone.
+func runClean(args []string) {
+	c := cleanup{}
+	flags := flag.NewFlagSet("clean", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s clean [flags] DIR...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&c.dryRun, "dry-run", false, "Whether or not to only list what would be removed.")
+	flags.DurationVar(&c.olderThan, "older-than", time.Hour, "How old temp files must be to be removed.")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	for _, dir := range flags.Args() {
+		if err := c.clean(dir); err != nil {
+			log.Fatal(errorText(err))
+		}
+	}
+	verb := tr("Removed")
+	if c.dryRun {
+		verb = tr("Would remove")
+	}
+	log.Printf(tr("🧹 %s %d file(s) or directories, and %d index entries.\n"), verb, c.removed, c.pruned)
+}
+
+// clean tidies up everything under root.
+func (c *cleanup) clean(root string) error {
+	var dirs, indexes []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			if path != root {
+				dirs = append(dirs, path)
+			}
+		case d.Name() == "index.json":
+			indexes = append(indexes, path)
+		case d.Type()&fs.ModeSymlink != 0:
+			// Links into a -dedupe pool whose file was deleted.
+			if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+				return c.remove(path, tr("dangling link"))
+			}
+		case isTempFile(d.Name()):
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if time.Since(info.ModTime()) >= c.olderThan {
+				return c.remove(path, tr("stale temp file"))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clean %s: %w", root, err)
+	}
+
+	for _, index := range indexes {
+		if err := c.pruneIndex(filepath.Dir(index)); err != nil {
+			return err
+		}
+	}
+	// Deepest first, so directories only holding empty ones go too.
+	for _, dir := range slices.Backward(dirs) {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			if err := c.remove(dir, tr("empty directory")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *cleanup) remove(path, why string) error {
+	log.Printf("🧹 %s: %s\n", path, why)
+	c.removed++
+	if c.dryRun {
+		return nil
+	}
+
+	return os.Remove(path)
+}
+
+// pruneIndex drops the files that no longer exist from the index.json of the course in dir.
+func (c *cleanup) pruneIndex(dir string) error {
+	index, err := loadIndex(dir)
+	if err != nil {
+		return err
+	}
+	pruned := 0
+	existing := func(files []IndexFile) []IndexFile {
+		return slices.DeleteFunc(files, func(f IndexFile) bool {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f.Path))); !errors.Is(err, fs.ErrNotExist) {
+				return false
+			}
+			log.Printf(tr("🧹 %s: index entry of a deleted file\n"), filepath.Join(dir, f.Path))
+			pruned++
+			return true
+		})
+	}
+	index.Files = existing(index.Files)
+	for i := range index.Items {
+		index.Items[i].Files = existing(index.Items[i].Files)
+	}
+	if pruned == 0 {
+		return nil
+	}
+	c.pruned += pruned
+	if c.dryRun {
+		return nil
+	}
+
+	return writeJSONFile(filepath.Join(dir, "index.json"), index)
+}
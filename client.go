@@ -0,0 +1,228 @@
+// Package lld scrapes and downloads LinkedIn Learning courses (transcripts, videos, and more) through a browser.
+//
+// Client is its Go API, of which the lld command is just one consumer:
+//
+//	c, err := lld.NewClient(lld.WithSession(""), lld.WithHeadless(true), lld.WithStorage("courses"))
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	if err := c.Login(ctx); err != nil {
+//		return err
+//	}
+//	course, err := c.Download(ctx, "https://www.linkedin.com/learning/some-course")
+package lld
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNotLoggedIn is returned by a Client's methods called before Login.
+var ErrNotLoggedIn = errors.New("not logged in")
+
+// Client is a browser session logged in to LinkedIn Learning. Its methods are safe to call from several goroutines,
+// but they share the one browser tab, so they take turns.
+type Client struct {
+	mu      sync.Mutex
+	opts    options
+	http    *http.Client
+	logger  *log.Logger
+	browser context.Context
+	close   context.CancelFunc
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHeadless runs the browser without a window. Logging in with SSO needs one, so it's for WithSession.
+func WithHeadless(headless bool) Option {
+	return func(c *Client) {
+		c.opts.headless = headless
+	}
+}
+
+// WithSession logs in with the session cookies from source: keychain, env:NAME, op://vault/item/field, or
+// vault:path#field. An empty source is the OS keychain, where the session is kept after logging in with SSO too.
+func WithSession(source string) Option {
+	return func(c *Client) {
+		c.opts.session = true
+		c.opts.credentialSource = source
+	}
+}
+
+// WithSSO logs in through the enterprise SSO sign-on at url, unless there's a WithSession session.
+func WithSSO(url string) Option {
+	return func(c *Client) {
+		c.opts.ssoURL = url
+	}
+}
+
+// WithHTTPClient downloads files with hc rather than a client made from the network options.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithLogger sends the progress messages to l. They go through the standard log package, so it's process-wide.
+func WithLogger(l *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithStorage saves downloaded courses under dir, each into a directory named after it (default the current one).
+func WithStorage(dir string) Option {
+	return func(c *Client) {
+		c.opts.outDir = dir
+	}
+}
+
+// NewClient returns a Client with the lld command's defaults, downloading both transcripts and videos, changed by
+// opts. It doesn't start the browser until Login.
+func NewClient(opts ...Option) (*Client, error) {
+	c := &Client{}
+	// The flags' defaults are the defaults.
+	fs := flag.NewFlagSet("lld", flag.ContinueOnError)
+	registerFlags(fs, &c.opts)
+	if err := fs.Parse(nil); err != nil {
+		return nil, err
+	}
+	c.opts.dlTranscripts, c.opts.dlVideos = true, true
+	for _, o := range opts {
+		o(c)
+	}
+	if c.logger != nil {
+		c.opts.logOutput = c.logger.Writer()
+	}
+	if err := setup(&c.opts); err != nil {
+		return nil, err
+	}
+	if c.logger != nil && !jsonLogs {
+		log.SetFlags(c.logger.Flags())
+		log.SetPrefix(c.logger.Prefix())
+	}
+	if c.http != nil {
+		c.opts.dl.client = c.http
+	}
+
+	return c, nil
+}
+
+// Login starts the browser and logs in.
+func (c *Client) Login(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.browser == nil {
+		c.browser, c.close = newChromeDPCtx(0, &c.opts)
+	}
+	ctx, cancel := c.bind(ctx)
+	defer cancel()
+
+	return login(ctx, &c.opts)
+}
+
+// Course parses a course's details and table of contents, without downloading anything.
+func (c *Client) Course(ctx context.Context, courseURL string) (*Course, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.browser == nil {
+		return nil, ErrNotLoggedIn
+	}
+	ctx, cancel := c.bind(ctx)
+	defer cancel()
+
+	courseURL, err := normalizeCourseURL(c.opts.learningBase, courseURL)
+	if err != nil {
+		return nil, err
+	}
+	slug, err := courseSlug(c.opts.learningBase, courseURL)
+	if err != nil {
+		return nil, err
+	}
+	course, err := parseCourse(ctx, courseURL)
+	if err != nil {
+		return nil, err
+	}
+	if course.Videos, err = parseCourseVideos(ctx, &c.opts, courseURL, filepath.Join(c.opts.outDir, slug)); err != nil {
+		return nil, err
+	}
+
+	return course, nil
+}
+
+// Download downloads a course into its own directory of the WithStorage one, as the lld command would. Items that
+// couldn't be saved don't stop it: they're reported together in the error, which unwraps to each of theirs.
+func (c *Client) Download(ctx context.Context, courseURL string) (*Course, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.browser == nil {
+		return nil, ErrNotLoggedIn
+	}
+	ctx, cancel := c.bind(ctx)
+	defer cancel()
+
+	courseURL, err := normalizeCourseURL(c.opts.learningBase, courseURL)
+	if err != nil {
+		return nil, err
+	}
+	slug, err := courseSlug(c.opts.learningBase, courseURL)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(c.opts.outDir, slug)
+	course, err := downloadCourse(ctx, &c.opts, courseURL, dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := finishCourse(ctx, &c.opts, courseURL, dir); err != nil {
+		return course, err
+	}
+	if len(course.failures) > 0 {
+		failed := &itemsError{}
+		for _, err := range course.failures {
+			failed.errs = append(failed.errs, err)
+		}
+		return course, failed
+	}
+
+	return course, nil
+}
+
+// Close closes the browser, and sends the trace spans that are left, with OTEL_EXPORTER_OTLP_ENDPOINT set.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.close != nil {
+		c.close()
+		c.browser, c.close = nil, nil
+	}
+	shutdownTracing()
+
+	return nil
+}
+
+// bind returns a context of the browser that's canceled along with ctx, and shares its deadline.
+func (c *Client) bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	var (
+		bctx   context.Context
+		cancel context.CancelFunc
+	)
+	if deadline, ok := ctx.Deadline(); ok {
+		bctx, cancel = context.WithDeadline(c.browser, deadline)
+	} else {
+		bctx, cancel = context.WithCancel(c.browser)
+	}
+	stop := context.AfterFunc(ctx, cancel)
+
+	return bctx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,50 @@
+package lld_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+// syncBuffer is a bytes.Buffer that the tests running alongside may log to at the same time.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+	var buf syncBuffer
+	c, err := lld.NewClient(lld.WithLogger(log.New(&buf, "lld: ", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = c.Close()
+		log.SetOutput(os.Stderr)
+		log.SetPrefix("")
+		log.SetFlags(log.LstdFlags)
+	})
+
+	log.Println("✅ Logged in.")
+	if got := buf.String(); !strings.Contains(got, "lld: [OK] Logged in.\n") {
+		t.Errorf("the WithLogger buffer has %q, want the message logged", got)
+	}
+}
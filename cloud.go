@@ -0,0 +1,55 @@
+package lld
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// azureUploader uploads to Azure Blob Storage with the az CLI, given as az://account/container/prefix. The CLI brings
+// the usual credential chain: az login, a managed identity, or $AZURE_STORAGE_CONNECTION_STRING/$AZURE_STORAGE_KEY.
+type azureUploader struct {
+	account   string
+	container string
+	prefix    string
+}
+
+func newAzure(u *url.URL) *azureUploader {
+	container, prefix, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+
+	return &azureUploader{account: u.Host, container: container, prefix: prefix}
+}
+
+func (a *azureUploader) put(ctx context.Context, file, rel string) error {
+	args := []string{
+		"storage", "blob", "upload", "--only-show-errors", "--overwrite",
+		"--account-name", a.account,
+		"--container-name", a.container,
+		"--name", path.Join(a.prefix, rel),
+		"--file", file,
+	}
+	// Without a key in the environment, authenticate as whoever is logged in (or the managed identity).
+	if os.Getenv("AZURE_STORAGE_CONNECTION_STRING") == "" && os.Getenv("AZURE_STORAGE_KEY") == "" {
+		args = append(args, "--auth-mode", "login")
+	}
+
+	return runCLI(ctx, nil, "az", args...)
+}
+
+// gcsUploader uploads to Google Cloud Storage with the gcloud CLI, given as gs://bucket/prefix. The CLI brings the
+// usual credential chain: gcloud auth, $GOOGLE_APPLICATION_CREDENTIALS, or the instance's service account.
+type gcsUploader struct {
+	bucket string
+	prefix string
+}
+
+func newGCS(u *url.URL) *gcsUploader {
+	return &gcsUploader{bucket: u.Host, prefix: strings.Trim(u.Path, "/")}
+}
+
+func (g *gcsUploader) put(ctx context.Context, file, rel string) error {
+	return runCLI(ctx, nil, "gcloud", "storage", "cp", "--no-user-output-enabled", file,
+		"gs://"+g.bucket+"/"+path.Join(g.prefix, rel))
+}
@@ -0,0 +1,8 @@
+// Command lld downloads LinkedIn Learning courses; see the README for its flags and subcommands.
+package main
+
+import "github.com/jh125486/lld"
+
+func main() {
+	lld.Main()
+}
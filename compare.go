@@ -0,0 +1,255 @@
+package lld
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// compareKeywords is how many keywords each list of the comparison shows.
+const compareKeywords = 15
+
+// courseProfile is what lld compare knows of a course: its details, TOC, and what it talks about.
+type courseProfile struct {
+	course      *Course
+	videos      []VideoEntry
+	keywords    map[string]int
+	transcripts int // How many transcripts the keywords come from; none means the titles and description.
+}
+
+func runCompare(args []string) {
+	var opts options
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s compare [flags] URL1 URL2\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	registerFlags(flags, &opts)
+	_ = flags.Parse(args)
+	if err := setup(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+	defer shutdownTracing()
+	defer stopProfiling(&opts)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var courseURLs [2]string
+	for i, course := range flags.Args() {
+		courseURL, err := normalizeCourseURL(opts.learningBase, course)
+		if err != nil {
+			log.Fatal(errorText(err))
+		}
+		courseURLs[i] = courseURL
+	}
+
+	ctx, cancel := newChromeDPCtx(opts.timeout, &opts)
+	defer cancel()
+	if err := login(ctx, &opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+	var profiles [2]*courseProfile
+	for i, courseURL := range courseURLs {
+		p, err := profileCourse(ctx, &opts, courseURL)
+		if err != nil {
+			log.Fatal(errorText(err))
+		}
+		profiles[i] = p
+	}
+	fmt.Print(compareReport(profiles[0], profiles[1]))
+}
+
+// profileCourse parses a course's details and TOC, and profiles its keywords from the transcripts saved under
+// -output/<course-slug>, if it was downloaded, or else from its titles and description.
+func profileCourse(ctx context.Context, opts *options, courseURL string) (*courseProfile, error) {
+	slug, err := courseSlug(opts.learningBase, courseURL)
+	if err != nil {
+		return nil, err
+	}
+	course, err := parseCourse(ctx, courseURL)
+	if err != nil {
+		logf(ctx, tr("⚠️ Failed to parse course details: %v"), err)
+		course = &Course{URL: courseURL, Title: slug}
+	}
+	videos, err := parseCourseVideos(ctx, opts, courseURL, filepath.Join(opts.outDir, slug))
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract video links: %w", err)
+	}
+
+	p := &courseProfile{course: course, videos: videos}
+	var text strings.Builder
+	for _, v := range videos {
+		if t := loadTranscript(v.filename); t != "" {
+			text.WriteString(t + "\n")
+			p.transcripts++
+		}
+	}
+	if p.transcripts == 0 {
+		text.WriteString(course.Description + "\n" + strings.Join(course.Objectives, "\n") + "\n")
+		text.WriteString(strings.Join(course.Skills, "\n") + "\n")
+		for _, v := range videos {
+			text.WriteString(v.Section + "\n" + v.Title + "\n")
+		}
+	}
+	p.keywords = keywordCounts(text.String())
+	for _, w := range fillerWords() {
+		delete(p.keywords, w)
+	}
+
+	return p, nil
+}
+
+// fillerWords are common in speech, but say nothing about what a course covers.
+func fillerWords() []string {
+	return []string{
+		"about", "all", "also", "any", "are", "because", "been", "but", "can", "could", "don", "each", "first", "for",
+		"from", "get", "going", "had", "has", "have", "her", "here", "him", "his", "how", "into", "its", "just", "know",
+		"let", "like", "look", "make", "more", "need", "next", "not", "now", "okay", "one", "other", "our", "out",
+		"really", "right", "see", "she", "should", "some", "them", "then", "there", "these", "they", "thing", "things",
+		"those", "use", "using", "want", "was", "way", "well", "were", "what", "when", "which", "will", "would", "your",
+	}
+}
+
+// compareReport renders the comparison of a and b as Markdown.
+func compareReport(a, b *courseProfile) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s vs. %s\n\n", a.course.Title, b.course.Title)
+	sb.WriteString("| | A | B |\n|---|---|---|\n")
+	row := func(name string, f func(p *courseProfile) string) {
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", name, f(a), f(b))
+	}
+	row("Course", func(p *courseProfile) string { return "[" + p.course.Title + "](" + p.course.URL + ")" })
+	row("Level", func(p *courseProfile) string { return p.course.Level })
+	row("Updated", func(p *courseProfile) string { return cmp.Or(p.course.Updated, p.course.Released) })
+	row("Instructors", func(p *courseProfile) string {
+		names := make([]string, 0, len(p.course.Authors))
+		for _, au := range p.course.Authors {
+			names = append(names, au.Name)
+		}
+		return strings.Join(names, ", ")
+	})
+	row("Sections", func(p *courseProfile) string { return strconv.Itoa(countSections(p.videos)) })
+	row("Items", func(p *courseProfile) string { return strconv.Itoa(len(p.videos)) })
+	row("Length", func(p *courseProfile) string { return studyTime(videoLength(p.videos)) })
+	row("Keywords from", func(p *courseProfile) string {
+		if p.transcripts == 0 {
+			return "titles and description"
+		}
+		return strconv.Itoa(p.transcripts) + " transcript(s)"
+	})
+
+	fmt.Fprintf(&sb, "\nKeyword similarity: %.0f%%\n", 100*cosineSimilarity(a.keywords, b.keywords))
+	writeKeywords(&sb, "Shared keywords", sharedKeywords(a.keywords, b.keywords))
+	writeKeywords(&sb, "Keywords only in A", uniqueKeywords(a.keywords, b.keywords))
+	writeKeywords(&sb, "Keywords only in B", uniqueKeywords(b.keywords, a.keywords))
+	writeTitles(&sb, "Items only in A", uniqueTitles(a.videos, b.videos))
+	writeTitles(&sb, "Items only in B", uniqueTitles(b.videos, a.videos))
+
+	return sb.String()
+}
+
+// videoLength totals the durations of the videos, leaving out the items without one.
+func videoLength(videos []VideoEntry) (total time.Duration) {
+	for _, v := range videos {
+		if v.Duration != "" {
+			total += itemLength(v)
+		}
+	}
+
+	return total
+}
+
+// cosineSimilarity is how alike two keyword profiles are, from 0 to 1.
+func cosineSimilarity(a, b map[string]int) float64 {
+	var dot, na, nb float64
+	for w, n := range a {
+		dot += float64(n * b[w])
+		na += float64(n * n)
+	}
+	for _, n := range b {
+		nb += float64(n * n)
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// topKeywords returns the most frequent keywords of freq, most frequent (then alphabetically) first.
+func topKeywords(freq map[string]int) []string {
+	words := slices.SortedFunc(maps.Keys(freq), func(x, y string) int {
+		return cmp.Or(freq[y]-freq[x], strings.Compare(x, y))
+	})
+
+	return words[:min(len(words), compareKeywords)]
+}
+
+// sharedKeywords are the keywords of both, weighted by how much the less keen of the two uses each.
+func sharedKeywords(a, b map[string]int) []string {
+	shared := map[string]int{}
+	for w, n := range a {
+		if m := b[w]; m > 0 {
+			shared[w] = min(n, m)
+		}
+	}
+
+	return topKeywords(shared)
+}
+
+// uniqueKeywords are the keywords of a that b never uses.
+func uniqueKeywords(a, b map[string]int) []string {
+	only := map[string]int{}
+	for w, n := range a {
+		if b[w] == 0 {
+			only[w] = n
+		}
+	}
+
+	return topKeywords(only)
+}
+
+// uniqueTitles are the items of a without one of the same title in b.
+func uniqueTitles(a, b []VideoEntry) []string {
+	normalize := func(s string) string { return strings.Join(tokenize(s), " ") }
+	seen := map[string]bool{}
+	for _, v := range b {
+		seen[normalize(v.Title)] = true
+	}
+	var out []string
+	for _, v := range a {
+		if !seen[normalize(v.Title)] {
+			out = append(out, v.Section+": "+v.Title)
+		}
+	}
+
+	return out
+}
+
+func writeKeywords(sb *strings.Builder, heading string, words []string) {
+	fmt.Fprintf(sb, "\n## %s\n\n", heading)
+	if len(words) == 0 {
+		sb.WriteString("_None._\n")
+		return
+	}
+	sb.WriteString(strings.Join(words, ", ") + "\n")
+}
+
+func writeTitles(sb *strings.Builder, heading string, titles []string) {
+	fmt.Fprintf(sb, "\n## %s (%d)\n\n", heading, len(titles))
+	for _, t := range titles {
+		sb.WriteString("- " + t + "\n")
+	}
+}
@@ -0,0 +1,267 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+type Course struct {
+	Title       string       `json:"title"`
+	URL         string       `json:"url"`
+	Description string       `json:"description"`
+	Level       string       `json:"level,omitempty"`
+	Released    string       `json:"released,omitempty"` // As shown on the course page, like the rest.
+	Updated     string       `json:"updated,omitempty"`
+	Version     string       `json:"version,omitempty"`
+	Objectives  []string     `json:"objectives,omitempty"`
+	Skills      []string     `json:"skills,omitempty"`
+	Authors     []Author     `json:"authors,omitempty"`
+	Related     []string     `json:"related,omitempty"`
+	Videos      []VideoEntry `json:"videos,omitempty"`
+	// Words and ReadingMinutes total those of the transcripts saved.
+	Words          int `json:"words,omitempty"`
+	ReadingMinutes int `json:"readingMinutes,omitempty"`
+
+	failures map[string]error // Items that couldn't be saved, by filename.
+}
+
+type Author struct {
+	Name     string `json:"name"`
+	Headline string `json:"headline,omitempty"`
+	Bio      string `json:"bio,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+const courseParseJS = `(sel, base = "/learning/") => {
+	const text = el => el?.innerText.trim() || "";
+	const all = sel => Array.from(document.querySelectorAll(sel));
+	// The dates are shown as e.g. "Updated: 3/14/2024" in the course details.
+	const labeled = label => {
+		const re = new RegExp("^" + label + ":?\\s+", "i");
+		return (all("[class*='details'] span, [class*='details'] li, [class*='meta'] span").map(text)
+			.find(t => re.test(t) && t.length < 60) || "").replace(re, "");
+	};
+	return {
+		title: text(document.querySelector("h1")),
+		description: text(document.querySelector(".course-details__description, [class*='course-description'], .show-more-less-html__markup")),
+		level: text(document.querySelector("[class*='difficulty-level'], [class*='course-level'], .course-details__level"))
+			.replace(/^(skill\s+)?level:?\s*/i, ""),
+		released: labeled("released"),
+		updated: labeled("updated"),
+		version: labeled("version"),
+		objectives: all(".course-objectives li, [class*='learning-objectives'] li").map(text).filter(Boolean),
+		skills: all(".course-skills a, [class*='skills-list'] a, [class*='course-skills'] li").map(text).filter(Boolean),
+		authors: all(".instructor-card, [class*='instructor-details'], [class*='course-instructor']").map(el => ({
+			name: text(el.querySelector("h3, [class*='name']")),
+			headline: text(el.querySelector("h4, [class*='headline']")),
+			bio: text(el.querySelector("p, [class*='bio']")),
+			url: el.querySelector("a[href*='" + base + "instructors/']")?.href || ""
+		})).filter(a => a.name),
+		related: [...new Set(all("[class*='related'] a[href*='" + base + "'], [class*='similar'] a[href*='" + base + "']")
+			.map(a => new URL(a.href))
+			.filter(u => u.pathname.startsWith(base) && u.pathname.slice(base.length).split("/").filter(Boolean).length === 1)
+			.map(u => u.origin + u.pathname.replace(/\/$/, "")))]
+	};
+}`
+
+// courseLandingURL turns any URL inside a course (e.g. a classroom video link) under base into the course's landing
+// page.
+func courseLandingURL(base *url.URL, courseURL string) (string, error) {
+	u, err := url.Parse(courseURL)
+	if err != nil {
+		return "", fmt.Errorf("bad url: %w", err)
+	}
+	if rest, ok := strings.CutPrefix(u.Path, base.Path); ok && u.Host == base.Host {
+		if slug, _, _ := strings.Cut(rest, "/"); slug != "" {
+			return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: base.Path + slug}).String(), nil
+		}
+	}
+	// Links to linkedin.com/learning are still understood with the -base-url of a Learning Hub.
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, p := range parts {
+		if p == "learning" && i+1 < len(parts) {
+			return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/learning/" + parts[i+1]}).String(), nil
+		}
+	}
+
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(), nil
+}
+
+// courseSlug is the course's URL slug, which doubles as its directory name when downloading several courses.
+func courseSlug(base *url.URL, courseURL string) (string, error) {
+	landing, err := courseLandingURL(base, courseURL)
+	if err != nil {
+		return "", err
+	}
+
+	return sanitizeFileName(path.Base(landing)), nil
+}
+
+func parseCourse(ctx context.Context, courseURL string) (_ *Course, err error) {
+	ctx, span := startSpan(ctx, "course details")
+	defer span.end(&err)
+
+	logln(ctx, tr("📖 Parsing course details."))
+	landing, err := courseLandingURL(learningBaseFrom(ctx), courseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var course Course
+	if err := chromedp.Run(ctx,
+		navigate(landing),
+		waitVisible(selHeading, new(string)),
+		chromedp.Evaluate(pageScript(ctx, jsParseCourse), &course),
+	); err != nil {
+		return nil, err
+	}
+	course.URL = landing
+	course.Related = slices.DeleteFunc(course.Related, func(r string) bool { return r == landing })
+	course.Title = strings.TrimSpace(strings.ReplaceAll(course.Title, "| LinkedIn Learning", ""))
+
+	return &course, nil
+}
+
+// courseUpdated reports whether course has been updated on LinkedIn Learning since it was saved into dir.
+func courseUpdated(ctx context.Context, course *Course, dir string) bool {
+	b, err := os.ReadFile(filepath.Join(dir, "course.json"))
+	if err != nil {
+		return false
+	}
+	var saved Course
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return false
+	}
+	// Either side may not have been scraped (older runs, layout changes), which says nothing either way.
+	if (saved.Updated == "" && saved.Version == "") || (course.Updated == "" && course.Version == "") {
+		return false
+	}
+	if saved.Updated == course.Updated && saved.Version == course.Version {
+		return false
+	}
+	logf(ctx, tr("🆕 The course was updated since it was downloaded: %s -> %s\n"),
+		strings.TrimSpace(saved.Updated+" "+saved.Version), strings.TrimSpace(course.Updated+" "+course.Version))
+
+	return true
+}
+
+func writeCourseJSON(ctx context.Context, course *Course, dir string) error {
+	filename := filepath.Join(dir, "course.json")
+	if err := writeJSONFile(filename, course); err != nil {
+		return err
+	}
+	logf(ctx, tr("💾 course saved: %s\n"), filename)
+
+	return nil
+}
+
+func writeReadme(ctx context.Context, course *Course, videos []VideoEntry, dir string) error {
+	var sb strings.Builder
+	sb.WriteString("# " + course.Title + "\n\n")
+	sb.WriteString("<" + course.URL + ">\n\n")
+	if course.Description != "" {
+		sb.WriteString(course.Description + "\n\n")
+	}
+	if course.Level != "" {
+		sb.WriteString("**Level:** " + course.Level + "\n\n")
+	}
+	if course.Words > 0 {
+		fmt.Fprintf(&sb, "**Reading time:** about %d min (%d words of transcripts)\n\n", course.ReadingMinutes, course.Words)
+	}
+	writeList(&sb, "What you'll learn", course.Objectives)
+	writeList(&sb, "Skills covered", course.Skills)
+	if len(course.Authors) > 0 {
+		sb.WriteString("## Instructors\n\n")
+		for _, a := range course.Authors {
+			name := a.Name
+			if a.URL != "" {
+				name = "[" + a.Name + "](" + a.URL + ")"
+			}
+			sb.WriteString("### " + name + "\n\n")
+			if a.Headline != "" {
+				sb.WriteString("_" + a.Headline + "_\n\n")
+			}
+			if a.Bio != "" {
+				sb.WriteString(a.Bio + "\n\n")
+			}
+		}
+	}
+
+	sb.WriteString("## Contents\n")
+	writeContents(&sb, videos, dir, "###")
+
+	filename := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(filename, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write README: %w", err)
+	}
+	logf(ctx, tr("💾 README saved: %s\n"), filename)
+
+	return nil
+}
+
+// writeTOC writes TOC.md, the table of contents linking each item to its saved files, for browsing the course offline.
+func writeTOC(ctx context.Context, course *Course, videos []VideoEntry, dir string) error {
+	var sb strings.Builder
+	title := course.Title
+	if title == "" {
+		title = course.URL
+	}
+	sb.WriteString("# " + title + "\n")
+	writeContents(&sb, videos, dir, "##")
+
+	filename := filepath.Join(dir, "TOC.md")
+	if err := os.WriteFile(filename, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 TOC saved: %s\n"), filename)
+
+	return nil
+}
+
+// writeContents lists the items under a heading per section, linking whatever was actually produced for each item
+// (video, audio, transcript, document...) by its path relative to the course directory.
+func writeContents(sb *strings.Builder, videos []VideoEntry, dir, heading string) {
+	section := ""
+	for _, v := range videos {
+		if v.Section != section {
+			section = v.Section
+			sb.WriteString("\n" + heading + " " + section + "\n\n")
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s", v.Index, v.Title))
+		files := itemFiles(v.filename)
+		for _, f := range files {
+			sb.WriteString(fmt.Sprintf(" [%s](%s)", strings.TrimPrefix(filepath.Ext(f), "."), relPath(dir, f)))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// relPath is file's path relative to dir with forward slashes, for linking to it.
+func relPath(dir, file string) string {
+	rel, err := filepath.Rel(dir, file)
+	if err != nil {
+		return filepath.Base(file)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+func writeList(sb *strings.Builder, heading string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString("## " + heading + "\n\n")
+	for _, item := range items {
+		sb.WriteString("- " + item + "\n")
+	}
+	sb.WriteString("\n")
+}
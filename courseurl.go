@@ -0,0 +1,115 @@
+package lld
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// learningLink is a link into a course: the course called slug under prefix (-base-url's path, or /learning/ of
+// linkedin.com), and the item called item when it's a link to one of its videos.
+type learningLink struct {
+	url     *url.URL
+	prefix  string
+	slug    string
+	item    string
+	account string // The u parameter, which picks the enterprise account to use.
+}
+
+// normalizeCourseURL turns what was given as a course into its canonical URL, so it's checked before logging in rather
+// than failing halfway through. A course slug, a link to one of its videos, or a share link with tracking parameters
+// all become the course's landing page, keeping only the u parameter.
+func normalizeCourseURL(base *url.URL, course string) (string, error) {
+	l, err := parseLearningLink(base, course)
+	if err != nil {
+		return "", err
+	}
+
+	return l.canonical(l.prefix + l.slug), nil
+}
+
+// normalizeVideoURL turns a link to one of a course's videos into its canonical URL, as normalizeCourseURL does for
+// courses.
+func normalizeVideoURL(base *url.URL, video string) (string, error) {
+	l, err := parseLearningLink(base, video)
+	if err != nil {
+		return "", err
+	}
+	if l.item == "" {
+		return "", fmt.Errorf("%s is a course, not one of its videos: give it with -course instead", video)
+	}
+
+	return l.canonical(l.prefix + l.slug + "/" + l.item), nil
+}
+
+func (l *learningLink) canonical(path string) string {
+	u := url.URL{Scheme: l.url.Scheme, Host: l.url.Host, Path: path}
+	if l.account != "" {
+		u.RawQuery = url.Values{"u": {l.account}}.Encode()
+	}
+
+	return u.String()
+}
+
+// parseLearningLink checks link is a course, or a video of one, under base (-base-url) or linkedin.com/learning,
+// refusing links to anything else.
+func parseLearningLink(base *url.URL, link string) (*learningLink, error) {
+	link = strings.TrimSpace(link)
+	switch {
+	case link == "":
+		return nil, errors.New("no course given: -course takes a course URL or slug")
+	case !strings.ContainsAny(link, "/?#:"):
+		return parseLearningLink(base, learningURL(base, link))
+	case !strings.Contains(link, "://"):
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q isn't a course URL or slug", link)
+	}
+	// Enterprise accounts share courses through their login page, e.g. /learning-login/share?redirect=COURSE.
+	if redirect := u.Query().Get("redirect"); redirect != "" {
+		return parseLearningLink(base, redirect)
+	}
+	if u.Host == "lnkd.in" {
+		return nil, fmt.Errorf("%s is a short link: open it in a browser, and give the course URL it leads to", link)
+	}
+
+	l := &learningLink{url: u, account: u.Query().Get("u")}
+	switch {
+	case u.Host == base.Host && strings.HasPrefix(u.Path, base.Path):
+		l.prefix = base.Path
+	case (u.Host == "linkedin.com" || strings.HasSuffix(u.Host, ".linkedin.com")) && strings.HasPrefix(u.Path, "/learning/"):
+		l.prefix = "/learning/"
+	default:
+		return nil, fmt.Errorf("%s isn't a LinkedIn Learning URL (give -base-url for a Learning Hub on its own domain)", link)
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(u.Path, l.prefix), "/"), "/")
+	l.slug = parts[0]
+	if len(parts) > 1 {
+		l.item = parts[1]
+	}
+	if l.slug == "" {
+		return nil, fmt.Errorf("%s is the home page, not a course", link)
+	}
+	if what := notCourse(l.slug); what != "" {
+		return nil, fmt.Errorf("%s is %s, not a course", link, what)
+	}
+
+	return l, nil
+}
+
+// notCourse says what the page whose path starts with segment (under -base-url) is, if it isn't a course.
+func notCourse(segment string) string {
+	switch segment {
+	case "paths":
+		return tr("a learning path")
+	case "instructors":
+		return tr("an instructor's page (see lld author)")
+	case "search", "topics", "browse", "collections", "me", "subscription", "certificates":
+		return tr("a listing page")
+	}
+
+	return ""
+}
@@ -0,0 +1,141 @@
+package lld_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+const hubBase = "https://learning.example.com/hub/"
+
+func baseURL(t *testing.T, base string) *url.URL {
+	t.Helper()
+	if base == "" {
+		base = "https://www.linkedin.com/learning/"
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+func TestNormalizeCourseURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		base    string // -base-url, linkedin.com's when empty.
+		course  string
+		want    string
+		wantErr bool
+	}{
+		{name: "slug", course: "go-essential-training", want: "https://www.linkedin.com/learning/go-essential-training"},
+		{name: "slug with spaces around", course: " go-essential-training\n", want: "https://www.linkedin.com/learning/go-essential-training"},
+		{
+			name:   "landing page",
+			course: "https://www.linkedin.com/learning/go-essential-training/",
+			want:   "https://www.linkedin.com/learning/go-essential-training",
+		},
+		{
+			name:   "without a scheme",
+			course: "www.linkedin.com/learning/go-essential-training",
+			want:   "https://www.linkedin.com/learning/go-essential-training",
+		},
+		{
+			name:   "without www",
+			course: "https://linkedin.com/learning/go-essential-training",
+			want:   "https://linkedin.com/learning/go-essential-training",
+		},
+		{
+			name:   "video",
+			course: "https://www.linkedin.com/learning/go-essential-training/what-is-go?autoPlay=true#transcript",
+			want:   "https://www.linkedin.com/learning/go-essential-training",
+		},
+		{
+			name:   "tracking parameters",
+			course: "https://www.linkedin.com/learning/go-essential-training?trk=share_ent_url&shareId=abc&u=2113185",
+			want:   "https://www.linkedin.com/learning/go-essential-training?u=2113185",
+		},
+		{
+			name: "share link",
+			course: "https://www.linkedin.com/learning-login/share?account=2113185&forceAccount=false&redirect=" +
+				url.QueryEscape("https://www.linkedin.com/learning/go-essential-training?trk=share_ent_url&u=2113185"),
+			want: "https://www.linkedin.com/learning/go-essential-training?u=2113185",
+		},
+		{name: "Learning Hub slug", base: hubBase, course: "go-essential-training", want: hubBase + "go-essential-training"},
+		{
+			name:   "Learning Hub video",
+			base:   hubBase,
+			course: "learning.example.com/hub/go-essential-training/what-is-go",
+			want:   hubBase + "go-essential-training",
+		},
+		{
+			name:   "linkedin.com with a Learning Hub",
+			base:   hubBase,
+			course: "https://www.linkedin.com/learning/go-essential-training",
+			want:   "https://www.linkedin.com/learning/go-essential-training",
+		},
+		{name: "empty", course: " ", wantErr: true},
+		{name: "short link", course: "https://lnkd.in/gHx2Zq9", wantErr: true},
+		{name: "home page", course: "https://www.linkedin.com/learning/", wantErr: true},
+		{name: "learning path", course: "https://www.linkedin.com/learning/paths/become-a-go-developer", wantErr: true},
+		{name: "instructor", course: "https://www.linkedin.com/learning/instructors/jane-doe", wantErr: true},
+		{name: "search", course: "https://www.linkedin.com/learning/search?keywords=go", wantErr: true},
+		{name: "profile", course: "https://www.linkedin.com/in/jane-doe", wantErr: true},
+		{name: "another site", course: "https://example.com/learning/go-essential-training", wantErr: true},
+		{name: "another scheme", course: "ftp://www.linkedin.com/learning/go-essential-training", wantErr: true},
+		{name: "Learning Hub without -base-url", course: hubBase + "go-essential-training", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := lld.NormalizeCourseURL(baseURL(t, tt.base), tt.course)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeCourseURL(%q) error = %v, want error %t", tt.course, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeCourseURL(%q) = %q, want %q", tt.course, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeVideoURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		base    string // -base-url, linkedin.com's when empty.
+		video   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "video",
+			video: "https://www.linkedin.com/learning/go-essential-training/what-is-go/?autoPlay=true&u=2113185",
+			want:  "https://www.linkedin.com/learning/go-essential-training/what-is-go?u=2113185",
+		},
+		{
+			name:  "Learning Hub video",
+			base:  hubBase,
+			video: hubBase + "go-essential-training/what-is-go?resume=false",
+			want:  hubBase + "go-essential-training/what-is-go",
+		},
+		{name: "course", video: "https://www.linkedin.com/learning/go-essential-training", wantErr: true},
+		{name: "slug", video: "go-essential-training", wantErr: true},
+		{name: "another site", video: "https://example.com/learning/go-essential-training/what-is-go", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := lld.NormalizeVideoURL(baseURL(t, tt.base), tt.video)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeVideoURL(%q) error = %v, want error %t", tt.video, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeVideoURL(%q) = %q, want %q", tt.video, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package lld
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// dashboard is the web UI of serve, at /: the jobs with their progress and failures, and a form to queue courses.
+//
+//go:embed dashboard.html
+var dashboard []byte
+
+func handleDashboard(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboard)
+}
@@ -0,0 +1,302 @@
+package lld
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TranscriptRow is a line of a transcript in a dataset export: a caption with its timing, or a line of the plain
+// transcript (without timing) when there were no captions.
+type TranscriptRow struct {
+	Course  string   `json:"course"`
+	Section string   `json:"section"`
+	Video   string   `json:"video"`
+	Line    int      `json:"line"` // From 1, within the video.
+	Start   *float64 `json:"start"`
+	End     *float64 `json:"end"`
+	Text    string   `json:"text"`
+}
+
+func runParquet(args []string) {
+	var out string
+	flags := flag.NewFlagSet("parquet", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s parquet [flags] DIR...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&out, "o", "transcripts.parquet", "Parquet file to write.")
+	_ = flags.Parse(args)
+	if out == "-" {
+		fmt.Fprintln(flags.Output(), tr("❌ Parquet can't be written to stdout, -o must be a file."))
+	}
+	if flags.NArg() == 0 || out == "-" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rows, err := coursesRows(flags.Args())
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	if err := writeParquet(context.Background(), rows, out); err != nil {
+		log.Fatal(errorText(err))
+	}
+	log.Printf(tr("💾 %d transcript line(s) of %d course(s) saved: %s\n"), len(rows), flags.NArg(), out)
+}
+
+// Dataset export formats.
+const (
+	formatCSV     = "csv"
+	formatParquet = "parquet"
+	formatJSONL   = "jsonl"
+)
+
+func runExport(args []string) {
+	var (
+		all          bool
+		root, format string
+		out          string
+	)
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export [flags] (-all | DIR...)\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&all, "all", false, "Whether or not to export every course downloaded under -output.")
+	flags.StringVar(&root, "output", ".", "Directory the courses were downloaded into, for -all.")
+	flags.StringVar(&format, "format", formatCSV, "Format of the dataset: csv, parquet, or jsonl.")
+	flags.StringVar(&out, "o", "", "File to write the dataset to, or - for stdout (default transcripts.<format>).")
+	_ = flags.Parse(args)
+	if all == (flags.NArg() > 0) {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if format != formatCSV && format != formatParquet && format != formatJSONL {
+		log.Fatalf(tr("❌ unsupported -format %q, expected csv, parquet, or jsonl"), format)
+	}
+	if format == formatParquet && out == "-" {
+		fmt.Fprintln(flags.Output(), tr("❌ Parquet can't be written to stdout, -o must be a file."))
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	dirs := flags.Args()
+	if all {
+		var err error
+		if dirs, err = findCourses(root); err != nil {
+			log.Fatal(errorText(err))
+		}
+	}
+	rows, err := coursesRows(dirs)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	out = cmp.Or(out, "transcripts."+format)
+	if err := writeDataset(rows, format, out); err != nil {
+		log.Fatal(errorText(err))
+	}
+	log.Printf(tr("💾 %d transcript line(s) of %d course(s) saved: %s\n"), len(rows), len(dirs), out)
+}
+
+// findCourses returns every course directory under root: those with an index.json, including related courses
+// downloaded into subdirectories.
+func findCourses(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == "index.json" {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+
+	return dirs, nil
+}
+
+// coursesRows reads back the transcripts of every course in dirs.
+func coursesRows(dirs []string) ([]TranscriptRow, error) {
+	var rows []TranscriptRow
+	for _, dir := range dirs {
+		r, err := courseRows(dir)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
+	}
+
+	return rows, nil
+}
+
+// writeDataset writes rows to out in format; out can be - for stdout, except for Parquet (which the flags rule out).
+func writeDataset(rows []TranscriptRow, format, out string) error {
+	if format == formatParquet {
+		return writeParquet(context.Background(), rows, out)
+	}
+	w := os.Stdout
+	if out != "-" {
+		f, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create file %s: %w", out, err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	write := writeRowsCSV
+	if format == formatJSONL {
+		write = writeRowsJSONL
+	}
+	if err := write(bw, rows); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", out, err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", out, err)
+	}
+
+	return nil
+}
+
+// courseRows reads back the transcripts of the course saved in dir, going by its index.json.
+func courseRows(dir string) ([]TranscriptRow, error) {
+	index, err := loadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []TranscriptRow
+	course := strings.TrimSpace(index.Title)
+	if course == "" {
+		course = index.Course
+	}
+	for _, item := range index.Items {
+		base, ok := transcriptBase(item.Files)
+		if !ok {
+			continue
+		}
+		v, ok := loadTranscriptEntry(filepath.Join(dir, filepath.FromSlash(base)))
+		if !ok {
+			continue
+		}
+		rows = append(rows, transcriptRows(course, item.VideoEntry, v)...)
+	}
+
+	return rows, nil
+}
+
+// transcriptBase is the path of an item's transcript without its extension: the shortest, since translations are
+// saved as <name>.<lang>.<ext> next to it. Encrypted ones can't be read back.
+func transcriptBase(files []IndexFile) (string, bool) {
+	var bases []string
+	for _, f := range files {
+		if f.Kind == "transcript" && !f.Encrypted {
+			bases = append(bases, strings.TrimSuffix(f.Path, filepath.Ext(f.Path)))
+		}
+	}
+	if len(bases) == 0 {
+		return "", false
+	}
+
+	return slices.MinFunc(bases, func(a, b string) int { return len(a) - len(b) }), true
+}
+
+func transcriptRows(course string, item, saved VideoEntry) []TranscriptRow {
+	var rows []TranscriptRow
+	if len(saved.Captions) > 0 {
+		for i, cue := range saved.Captions {
+			rows = append(rows, TranscriptRow{
+				Course: course, Section: item.Section, Video: item.Title, Line: i + 1,
+				Start: &cue.Start, End: &cue.End, Text: cue.Text,
+			})
+		}
+		return rows
+	}
+	for _, line := range strings.Split(saved.Transcript, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			rows = append(rows, TranscriptRow{
+				Course: course, Section: item.Section, Video: item.Title, Line: len(rows) + 1, Text: line,
+			})
+		}
+	}
+
+	return rows
+}
+
+// parquetColumns are the DuckDB types of TranscriptRow's columns.
+const parquetColumns = `{course: 'VARCHAR', section: 'VARCHAR', video: 'VARCHAR', line: 'INTEGER', ` +
+	`start: 'DOUBLE', "end": 'DOUBLE', text: 'VARCHAR'}`
+
+// writeParquet writes rows to the Parquet file out through the duckdb CLI, by way of a JSON Lines file.
+func writeParquet(ctx context.Context, rows []TranscriptRow, out string) error {
+	tmp, err := os.CreateTemp("", "lld-*.jsonl")
+	if err != nil {
+		return fmt.Errorf("failed to write Parquet: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if err := writeRowsJSONL(tmp, rows); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write Parquet: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write Parquet: %w", err)
+	}
+
+	query := fmt.Sprintf("COPY (SELECT * FROM read_json(%s, format = 'newline_delimited', columns = %s)) TO %s (FORMAT parquet);",
+		sqlQuote(tmp.Name()), parquetColumns, sqlQuote(out))
+	if _, err := outputCLI(ctx, "duckdb", "-c", query); err != nil {
+		return fmt.Errorf("failed to write Parquet: %w", err)
+	}
+
+	return nil
+}
+
+// writeRowsCSV writes rows as CSV with a header, leaving the timing empty where there is none.
+func writeRowsCSV(w io.Writer, rows []TranscriptRow) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"course", "section", "video", "line", "start", "end", "text"})
+	seconds := func(f *float64) string {
+		if f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*f, 'f', -1, 64)
+	}
+	for _, r := range rows {
+		_ = cw.Write([]string{r.Course, r.Section, r.Video, strconv.Itoa(r.Line), seconds(r.Start), seconds(r.End), r.Text})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func writeRowsJSONL(w io.Writer, rows []TranscriptRow) error {
+	enc := json.NewEncoder(w)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package lld
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// dedupe moves a finished download into the -dedupe pool, named by its content hash, and links it back into place.
+// A file that's already in the pool (from another course or learning path) is dropped in favor of the pooled copy.
+func (d *downloader) dedupe(ctx context.Context, filename string) error {
+	sum, err := hashFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.pool, 0o750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", d.pool, err)
+	}
+	pooled, err := filepath.Abs(filepath.Join(d.pool, sum+filepath.Ext(filename)))
+	if err != nil {
+		return err
+	}
+
+	switch _, err := os.Stat(pooled); {
+	case err == nil:
+		logf(ctx, tr("🔗 Already in the pool, linking: %s\n"), filename)
+		if err := os.Remove(filename); err != nil {
+			return err
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if err := os.Rename(filename, pooled); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	// Hard links are invisible to everything else, but can't cross filesystems; symlinks can.
+	if err := os.Link(pooled, filename); err == nil {
+		return nil
+	}
+
+	return os.Symlink(pooled, filename)
+}
+
+func hashFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
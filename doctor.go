@@ -0,0 +1,263 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// doctorCourse is a public course that's been around for years, to check the selectors on.
+	doctorCourse = "how-to-speak-smarter-when-put-on-the-spot"
+	// doctorLoginWait bounds logging in, in case it's the logged in selector that's broken.
+	doctorLoginWait = 5 * time.Minute
+	// doctorPageWait bounds waiting for each page to show what it should.
+	doctorPageWait = 30 * time.Second
+)
+
+// doctorReport is what lld doctor found: how every selector and page script fared on the pages they're used on.
+type doctorReport struct {
+	selectors []selectorCheck
+	scripts   []scriptCheck
+}
+
+type selectorCheck struct {
+	name     string
+	page     string
+	required bool  // Whether it should match there; the others only show up on some items.
+	matches  []int // The elements each candidate matched.
+	err      error
+}
+
+type scriptCheck struct {
+	name     string
+	page     string
+	required bool
+	result   string
+	ok       bool
+}
+
+func runDoctor(args []string) {
+	var opts options
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s doctor [flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	registerFlags(flags, &opts)
+	flags.StringVar(&opts.courseURL, "course", "", "Public course to check the selectors on (default "+doctorCourse+" under -base-url).")
+	_ = flags.Parse(args)
+	if err := setup(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+	defer shutdownTracing()
+	defer stopProfiling(&opts)
+	if opts.courseURL == "" {
+		opts.courseURL = doctorCourse
+	}
+	courseURL, err := normalizeCourseURL(opts.learningBase, opts.courseURL)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	opts.courseURL = courseURL
+
+	ctx, cancel := newChromeDPCtx(opts.timeout, &opts)
+	defer cancel()
+	var r doctorReport
+	loginCtx, loginCancel := context.WithTimeout(ctx, doctorLoginWait)
+	if err := login(loginCtx, &opts); err != nil {
+		logln(ctx, errorText(err))
+	}
+	loginCancel()
+	r.checkSelectors(ctx, "home", true, selLoggedIn)
+	if err := r.checkCourse(ctx, opts.courseURL); err != nil {
+		logln(ctx, errorText(err))
+	}
+
+	fmt.Print(r.markdown(opts.selectorsFile, opts.courseURL))
+	if !r.ok() {
+		cancel()
+		os.Exit(1)
+	}
+}
+
+// checkCourse checks the selectors and scripts used on the course's landing page, table of contents, and first video.
+func (r *doctorReport) checkCourse(ctx context.Context, courseURL string) error {
+	landing, err := courseLandingURL(learningBaseFrom(ctx), courseURL)
+	if err != nil {
+		return err
+	}
+	visit(ctx, landing, selHeading)
+	r.checkSelectors(ctx, "landing", true, selHeading)
+	var course Course
+	r.checkScript(ctx, "landing", jsParseCourse, true, &course, func() (string, bool) {
+		return course.Title, course.Title != ""
+	})
+
+	visit(ctx, courseURL, selTOCSection)
+	r.checkSelectors(ctx, "toc", true, selTOCSection, selTOCSectionTitle, selTOCItem, selTOCItemLink, selTOCItemTitle)
+	r.checkSelectors(ctx, "toc", false, selTOCSectionToggle)
+	var items int
+	r.checkScript(ctx, "toc", jsExpandTOC, true, &items, func() (string, bool) {
+		return strconv.Itoa(items) + " item(s)", items > 0
+	})
+	var videos []VideoEntry
+	r.checkScript(ctx, "toc", jsParseTOC, true, &videos, func() (string, bool) {
+		return strconv.Itoa(len(videos)) + " item(s)", len(videos) > 0
+	})
+	for _, v := range videos {
+		if v.Type == itemVideo {
+			r.checkVideo(ctx, v.Href)
+			break
+		}
+	}
+
+	return nil
+}
+
+// checkVideo checks the selectors and scripts used on a video item, before and after opening its transcript.
+func (r *doctorReport) checkVideo(ctx context.Context, href string) {
+	visit(ctx, href, selVideo)
+	r.checkSelectors(ctx, "video", true, selVideo, selTranscriptButton)
+	r.checkSelectors(ctx, "video", false, selPlayButton, selDRM, selAudio, selDocumentLink, selDocumentEmbed, selRateLimited)
+	var protected bool
+	r.checkScript(ctx, "video", jsProtected, false, &protected, func() (string, bool) {
+		return strconv.FormatBool(protected), true
+	})
+	var src string
+	r.checkScript(ctx, "video", jsVideoSrc, false, &src, func() (string, bool) {
+		return src, src != ""
+	})
+	var cues []Cue
+	r.checkScript(ctx, "video", jsCaptions, false, &cues, func() (string, bool) {
+		return strconv.Itoa(len(cues)) + " cue(s)", len(cues) > 0
+	})
+
+	var button string
+	openCtx, cancel := context.WithTimeout(ctx, doctorPageWait)
+	defer cancel()
+	_ = chromedp.Run(openCtx,
+		waitReady(&button, selTranscriptButton),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return chromedp.Click(button, chromedp.ByQuery).Do(ctx)
+		}),
+		waitSettled(selTranscriptLine),
+	)
+	r.checkSelectors(ctx, "transcript", true, selTranscriptLine)
+	var lines []string
+	r.checkScript(ctx, "transcript", jsTranscript, true, &lines, func() (string, bool) {
+		return strconv.Itoa(len(lines)) + " line(s)", len(lines) > 0
+	})
+}
+
+// visit navigates to u, waiting a little for the selector called name to show up, to check the page whether it does
+// or not.
+func visit(ctx context.Context, u, name string) {
+	ctx, cancel := context.WithTimeout(ctx, doctorPageWait)
+	defer cancel()
+	_ = chromedp.Run(ctx, navigate(u), waitSettled(name))
+}
+
+// checkSelectors counts the elements each candidate of the selectors called names matches on the current page.
+func (r *doctorReport) checkSelectors(ctx context.Context, page string, required bool, names ...string) {
+	for _, name := range names {
+		c := selectorCheck{name: name, page: page, required: required}
+		candidates, _ := json.Marshal(selectorsFrom(ctx).CSS[name])
+		c.err = chromedp.Run(ctx,
+			chromedp.Evaluate("("+string(candidates)+").map("+matchJS+").map(els => els.length)", &c.matches),
+		)
+		r.selectors = append(r.selectors, c)
+	}
+}
+
+// checkScript evaluates the page script called name into v, summarizing the result with summary.
+func (r *doctorReport) checkScript(ctx context.Context, page, name string, required bool, v any, summary func() (string, bool)) {
+	c := scriptCheck{name: name, page: page, required: required}
+	if err := chromedp.Run(ctx, chromedp.Evaluate(pageScript(ctx, name), v, awaitPromise)); err != nil {
+		c.result = err.Error()
+	} else {
+		c.result, c.ok = summary()
+	}
+	r.scripts = append(r.scripts, c)
+}
+
+func (c selectorCheck) status() string {
+	switch {
+	case c.err != nil:
+		return "❌ " + c.err.Error()
+	case len(c.matches) > 0 && c.matches[0] > 0:
+		return "✅ ok"
+	case slices.ContainsFunc(c.matches, matched):
+		return "🩹 fallback"
+	case c.required:
+		return "❌ no match"
+	default:
+		return "➖ not on this page"
+	}
+}
+
+func matched(n int) bool { return n > 0 }
+
+// ok is whether everything that should have matched or worked did.
+func (r *doctorReport) ok() bool {
+	for _, c := range r.selectors {
+		if c.required && (c.err != nil || !slices.ContainsFunc(c.matches, matched)) {
+			return false
+		}
+	}
+	for _, c := range r.scripts {
+		if c.required && !c.ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String formats the report as Markdown, to paste into a bug report.
+func (r *doctorReport) markdown(selectorsFile, courseURL string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## lld doctor\n\n- Version: %s (%s/%s)\n- Selectors: version %d", currentVersion(), runtime.GOOS,
+		runtime.GOARCH, selectorsVersion)
+	if selectorsFile != "" {
+		fmt.Fprintf(&sb, ", with overrides from %s", selectorsFile)
+	}
+	fmt.Fprintf(&sb, "\n- Course: %s\n- Checked: %s\n\n", courseURL, time.Now().UTC().Format(time.RFC3339))
+
+	sb.WriteString("| Selector | Page | Status | Matches per candidate |\n|---|---|---|---|\n")
+	for _, c := range r.selectors {
+		counts := make([]string, 0, len(c.matches))
+		for _, n := range c.matches {
+			counts = append(counts, strconv.Itoa(n))
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", c.name, c.page, strings.ReplaceAll(c.status(), "|", `\|`), strings.Join(counts, ", "))
+	}
+
+	sb.WriteString("\n| Script | Page | Status | Result |\n|---|---|---|---|\n")
+	for _, c := range r.scripts {
+		status := "✅ ok"
+		switch {
+		case !c.ok && c.required:
+			status = "❌ failed"
+		case !c.ok:
+			status = "➖ nothing on this page"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", c.name, c.page, status, strings.ReplaceAll(c.result, "|", `\|`))
+	}
+	if !r.ok() {
+		sb.WriteString("\nSome selectors no longer match: please attach this report to an issue at " +
+			"https://github.com/jh125486/lld/issues, or override them with -selectors (see lld selectors).\n")
+	}
+
+	return sb.String()
+}
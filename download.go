@@ -0,0 +1,466 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNoRanges is returned when the server won't serve byte ranges, so a segmented download has to fall back.
+var errNoRanges = errors.New("server doesn't support range requests")
+
+// downloader fetches media over plain HTTP, outside the browser.
+type downloader struct {
+	client    *http.Client
+	segments  int
+	backoff   time.Duration
+	sync      bool
+	pool      string // The -dedupe directory, if any.
+	artifacts *artifactLog
+	meter     *meter // Counts what's downloaded, towards -max-bytes.
+	external  string // The -downloader to delegate to, unless it's our own.
+	ipVersion string // The -ip-version to force on external downloaders, if any.
+	// slots caps the downloads running at once at -downloads. Nil for no limit.
+	slots chan struct{}
+}
+
+// target is a single file to download.
+type target struct {
+	url      string
+	filename string
+	kind     string // Only used for logging.
+	cookies  []*http.Cookie
+	// refresh re-extracts the URL from the page, for when its signed token has expired. May be nil.
+	refresh func(ctx context.Context) (string, error)
+	// validators are what the server last said about the file, for conditional requests next time.
+	validators validators
+}
+
+// validators are the HTTP cache validators of a downloaded file, kept in a hidden file next to it.
+type validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// statusError is an unexpected HTTP response status.
+type statusError struct {
+	status string
+	code   int
+	header http.Header
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned status: %s", e.status)
+}
+
+// Unwrap tells what kind of failure the status is, if it's one of ours.
+func (e *statusError) Unwrap() error {
+	switch e.code {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized:
+		return ErrAuthExpired
+	}
+
+	return nil
+}
+
+func newStatusError(resp *http.Response) error {
+	return &statusError{status: resp.Status, code: resp.StatusCode, header: resp.Header}
+}
+
+func newDownloader(opts *options) *downloader {
+	d := &downloader{
+		client:    newHTTPClient(opts),
+		segments:  max(opts.segments, 1),
+		backoff:   opts.backoff,
+		sync:      opts.sync,
+		pool:      opts.dedupe,
+		external:  opts.downloader,
+		ipVersion: opts.ipVersion,
+		artifacts: opts.artifacts,
+		meter:     opts.meter,
+	}
+	if opts.downloads > 0 {
+		d.slots = make(chan struct{}, opts.downloads)
+	}
+
+	return d
+}
+
+// download fetches t into its file, retrying when the CDN throttles us or the signed URL expires.
+func (d *downloader) download(ctx context.Context, t *target) (err error) {
+	ctx, span := startSpan(ctx, "download", "kind", t.kind, "file", t.filename)
+	defer span.end(&err)
+
+	if d.sync && d.unchanged(ctx, t) {
+		logf(ctx, tr("✅ %s unchanged, skipping: %s\n"), tr(t.kind), t.filename)
+		return nil
+	}
+	if d.slots != nil {
+		select {
+		case d.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		defer func() {
+			<-d.slots
+		}()
+	}
+
+	save := d.internal
+	if d.external != "" {
+		save = d.delegate
+	}
+	if err := save(ctx, t); err != nil {
+		return err
+	}
+	// The old validators no longer describe what's on disk.
+	_ = os.Remove(validatorsFile(t.filename))
+	logf(ctx, tr("💾 %s saved: %s\n"), tr(t.kind), t.filename)
+	d.artifacts.recordFile(ctx, t.filename)
+	if d.pool != "" {
+		if err := d.dedupe(ctx, t.filename); err != nil {
+			logf(ctx, tr("⚠️ failed to dedupe %s: %v"), t.filename, err)
+		}
+	}
+	if err := saveValidators(t); err != nil {
+		logf(ctx, tr("⚠️ failed to save the ETag of %s: %v"), t.filename, err)
+	}
+
+	return nil
+}
+
+// internal downloads t with our own HTTP client.
+func (d *downloader) internal(ctx context.Context, t *target) (err error) {
+	part := partFile(t.filename)
+	f, err := os.Create(part)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", part, err)
+	}
+	defer func() {
+		_ = f.Close()
+		if err != nil {
+			_ = os.Remove(part)
+		}
+	}()
+
+	for attempt := 0; ; attempt++ {
+		err := d.fetch(ctx, f, t)
+		if err == nil {
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to save %s: %w", tr(t.kind), err)
+			}
+			return complete(part, t.filename)
+		}
+		if err := d.retry(ctx, t, err, attempt); err != nil {
+			return err
+		}
+		if err := rewind(f); err != nil {
+			return fmt.Errorf("failed to save %s: %w", tr(t.kind), err)
+		}
+	}
+}
+
+// partFile is where filename is downloaded to, hidden next to it, until it's complete and replaces it. That way a
+// failed -sync refresh leaves the copy already there alone, and a link into the -dedupe pool is replaced rather than
+// written through.
+func partFile(filename string) string {
+	return filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp"+filepath.Ext(filename))
+}
+
+// complete moves the finished download part into place as filename.
+func complete(part, filename string) error {
+	if err := os.Rename(part, filename); err != nil {
+		_ = os.Remove(part)
+		return fmt.Errorf("failed to replace %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// validatorsFile is where the validators of filename are kept; it's hidden so it doesn't clutter the course.
+func validatorsFile(filename string) string {
+	return filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".etag.json")
+}
+
+func saveValidators(t *target) error {
+	if t.validators == (validators{}) {
+		return nil
+	}
+	b, err := json.Marshal(t.validators)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(validatorsFile(t.filename), b, 0o600)
+}
+
+// unchanged asks the server, with a conditional request, whether the file saved last time is still current.
+// Anything other than a clear 304 means it gets downloaded again.
+func (d *downloader) unchanged(ctx context.Context, t *target) bool {
+	if !exists(t.filename) && !exists(t.filename+ageExt) {
+		return false
+	}
+	b, err := os.ReadFile(validatorsFile(t.filename))
+	if err != nil {
+		return false
+	}
+	var v validators
+	if err := json.Unmarshal(b, &v); err != nil || v == (validators{}) {
+		return false
+	}
+
+	h := http.Header{}
+	if v.ETag != "" {
+		h.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		h.Set("If-Modified-Since", v.LastModified)
+	}
+	// Only ask for a single byte, in case the server ignores the conditions.
+	resp, err := d.get(ctx, t.url, "bytes=0-0", t.cookies, h)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+
+	return resp.StatusCode == http.StatusNotModified
+}
+
+func exists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
+
+// remember keeps the validators of a successful response.
+func (t *target) remember(h http.Header) {
+	t.validators = validators{ETag: h.Get("ETag"), LastModified: h.Get("Last-Modified")}
+}
+
+// retry decides whether err is worth another attempt, waiting or refreshing the URL as needed. It returns err if not.
+func (d *downloader) retry(ctx context.Context, t *target, err error, attempt int) error {
+	var se *statusError
+	if !errors.As(err, &se) || attempt >= maxRetry {
+		return err
+	}
+
+	switch se.code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		wait := retryAfter(se.header, d.backoff)
+		logf(ctx, tr("🚧 Throttled (%s), retrying in %v...\n"), se.status, wait)
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+	case http.StatusForbidden, http.StatusGone:
+		// The CDN URLs carry short-lived tokens (sometimes behind a 302), so grab a fresh one from the page.
+		if t.refresh == nil {
+			return err
+		}
+		logf(ctx, tr("🔑 URL expired (%s), re-extracting...\n"), se.status)
+		u, rerr := t.refresh(ctx)
+		if rerr != nil || u == "" {
+			return err
+		}
+		t.url = u
+	default:
+		return err
+	}
+
+	return nil
+}
+
+// retryAfter honors a Retry-After header (seconds or an HTTP date), falling back to the backoff.
+func retryAfter(h http.Header, fallback time.Duration) time.Duration {
+	v := h.Get("Retry-After")
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+
+	return fallback
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func rewind(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+
+	return err
+}
+
+// fetch makes a single attempt at downloading t into f.
+func (d *downloader) fetch(ctx context.Context, f *os.File, t *target) error {
+	if d.segments > 1 {
+		err := d.segmented(ctx, f, t)
+		if !errors.Is(err, errNoRanges) {
+			return err
+		}
+		logln(ctx, tr("↩️ Server doesn't support ranges, falling back to a single connection."))
+		if err := rewind(f); err != nil {
+			return fmt.Errorf("failed to save %s: %w", tr(t.kind), err)
+		}
+	}
+
+	resp, err := d.get(ctx, t.url, "", t.cookies, nil)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", tr(t.kind), err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(resp)
+	}
+	t.remember(resp.Header)
+
+	// Copy the response body to the file
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		return fmt.Errorf("failed to save %s: %w", tr(t.kind), err)
+	}
+
+	return nil
+}
+
+// segmented splits the download into d.segments byte ranges fetched in parallel and written in place.
+func (d *downloader) segmented(ctx context.Context, f *os.File, t *target) error {
+	size, err := d.probeSize(ctx, t)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	chunk := (size + int64(d.segments) - 1) / int64(d.segments)
+	for start := int64(0); start < size; start += chunk {
+		end := min(start+chunk, size) - 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.fetchRange(ctx, f, t.url, start, end, t.cookies); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// probeSize asks for the first byte to find out whether ranges are supported and how big the file is.
+func (d *downloader) probeSize(ctx context.Context, t *target) (int64, error) {
+	resp, err := d.get(ctx, t.url, "bytes=0-0", t.cookies, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		return 0, errNoRanges
+	default:
+		return 0, newStatusError(resp)
+	}
+
+	// Content-Range: bytes 0-0/12345
+	_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	size, err := strconv.ParseInt(total, 10, 64)
+	if !ok || err != nil || size <= 0 {
+		return 0, errNoRanges
+	}
+	t.remember(resp.Header)
+
+	return size, nil
+}
+
+func (d *downloader) fetchRange(ctx context.Context, f *os.File, u string, start, end int64, cookies []*http.Cookie) error {
+	resp, err := d.get(ctx, u, fmt.Sprintf("bytes=%d-%d", start, end), cookies, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		return errNoRanges
+	default:
+		return newStatusError(resp)
+	}
+
+	n, err := io.Copy(io.NewOffsetWriter(f, start), resp.Body)
+	if err != nil {
+		return err
+	}
+	if n != end-start+1 {
+		return fmt.Errorf("short read for bytes %d-%d: got %d", start, end, n)
+	}
+
+	return nil
+}
+
+func (d *downloader) get(ctx context.Context, u, byteRange string, cookies []*http.Cookie, h http.Header) (*http.Response, error) {
+	if err := limiterFrom(ctx).wait(ctx); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, vs := range h {
+		req.Header[k] = vs
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = d.meter.counted(resp.Body)
+
+	return resp, nil
+}
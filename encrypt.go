@@ -0,0 +1,36 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ageExt is added to every file encrypted with -encrypt.
+const ageExt = ".age"
+
+// encryptFiles replaces every file saved for the item at filename (video, transcript, ...) with an age-encrypted copy,
+// using the system's age CLI, so no plaintext is left behind once the item is done.
+func encryptFiles(ctx context.Context, filename string, recipients []string) error {
+	for _, file := range itemFiles(filename) {
+		if strings.HasSuffix(file, ageExt) {
+			continue
+		}
+		args := make([]string, 0, 2*len(recipients)+3)
+		for _, r := range recipients {
+			args = append(args, "-r", r)
+		}
+		args = append(args, "-o", file+ageExt, file)
+		if err := runCLI(ctx, nil, "age", args...); err != nil {
+			_ = os.Remove(file + ageExt)
+			return fmt.Errorf("failed to encrypt %s: %w", file, err)
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+		logf(ctx, tr("🔐 Encrypted: %s\n"), file+ageExt)
+	}
+
+	return nil
+}
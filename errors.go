@@ -0,0 +1,165 @@
+package lld
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The kinds of failure wrappers may want to handle, matched with errors.Is. Each has a code (in logs, index.json, and
+// the serve API) and an exit status, see errorKinds.
+var (
+	ErrAuthExpired     = errors.New("authentication expired")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrSelectorChanged = errors.New("page layout changed")
+	ErrDRM             = errors.New("protected content")
+	ErrNoTranscript    = errors.New("no transcript")
+	ErrSkipped         = errors.New("skipped")
+	ErrCorrupt         = errors.New("missing or damaged file")
+)
+
+// errorKind is the code, exit status, and log emoji of a typed error.
+type errorKind struct {
+	err   error
+	code  string
+	exit  int
+	emoji string
+}
+
+// errorKinds lists the typed errors by severity: a run that hit several exits with the status of the first.
+// Statuses 1 (any other failure) and 2 (usage) are taken.
+func errorKinds() []errorKind {
+	return []errorKind{
+		{ErrAuthExpired, "auth_expired", 3, "❌"},
+		{ErrRateLimited, "rate_limited", 4, "🚧"},
+		{ErrSelectorChanged, "selector_changed", 5, "⚠️"},
+		{ErrDRM, "drm", 6, "🔒"},
+		{ErrNoTranscript, "no_transcript", 7, "⚠️"},
+		{ErrSkipped, "skipped", 8, "⏭️"},
+		{ErrCorrupt, "corrupt", 9, "❌"},
+	}
+}
+
+// kindError tags err with the kind of failure it is, keeping its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+func (e *kindError) Error() string {
+	return fmt.Sprintf("%v [%s]", e.err, errorCode(e.kind))
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// itemsError is returned when a run went through, but some items couldn't be saved.
+type itemsError struct {
+	errs []error
+}
+
+func (e *itemsError) Error() string {
+	codes := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		codes = append(codes, errorCode(err))
+	}
+
+	return fmt.Sprintf("%d item(s) failed: %s", len(e.errs), strings.Join(codes, ", "))
+}
+
+func (e *itemsError) Unwrap() []error {
+	return e.errs
+}
+
+// errorCode is the machine readable code of err: that of its kind, "error" for any other, or empty for nil.
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, k := range errorKinds() {
+		if errors.Is(err, k.err) {
+			return k.code
+		}
+	}
+
+	return "error"
+}
+
+// errorText is how err is logged: after the emoji of its kind, and translated when its whole message is in the catalog.
+// Error values themselves are plain, so that wrappers, index.json, and the serve API get them as they are.
+func errorText(err error) string {
+	emoji := "❌"
+	for _, k := range errorKinds() {
+		if errors.Is(err, k.err) {
+			emoji = k.emoji
+			break
+		}
+	}
+
+	return emoji + " " + tr(err.Error())
+}
+
+// exitCode is the process exit status for err.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	for _, k := range errorKinds() {
+		if errors.Is(err, k.err) {
+			return k.exit
+		}
+	}
+
+	return 1
+}
+
+// Stages of processing an item, for telling where it failed.
+const (
+	stageVisit       = "visit"
+	stageTranscript  = "transcript"
+	stageMedia       = "media"
+	stageDocument    = "document"
+	stageQuiz        = "quiz"
+	stagePostProcess = "postprocess"
+	stageEncrypt     = "encrypt"
+	stageUpload      = "upload"
+	stageVerify      = "verify" // Found damaged by lld verify.
+)
+
+// stageError records the stage of processing an item that err happened in.
+type stageError struct {
+	stage string
+	err   error
+}
+
+// inStage tags a non-nil err with the stage it happened in.
+func inStage(stage string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stageError{stage: stage, err: err}
+}
+
+func (e *stageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stageError) Unwrap() error {
+	return e.err
+}
+
+// stageOf returns the stage err happened in, if known.
+func stageOf(err error) string {
+	var se *stageError
+	if errors.As(err, &se) {
+		return se.stage
+	}
+
+	return ""
+}
@@ -0,0 +1,47 @@
+package lld_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+func TestErrorText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "plain",
+			err:  errors.New("no course given"),
+			want: "❌ no course given",
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("failed to save notes: %w", errors.New("disk full")),
+			want: "❌ failed to save notes: disk full",
+		},
+		{
+			name: "rate limited",
+			err:  lld.WithKind(lld.ErrRateLimited, errors.New("still rate limited, giving up")),
+			want: "🚧 still rate limited, giving up [rate_limited]",
+		},
+		{
+			name: "skipped",
+			err:  fmt.Errorf("skipping (no transcript): %s: %w", "Intro", lld.ErrSkipped),
+			want: "⏭️ skipping (no transcript): Intro: skipped",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := lld.ErrorText(tt.err); got != tt.want {
+				t.Errorf("ErrorText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
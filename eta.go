@@ -0,0 +1,47 @@
+package lld
+
+import (
+	"context"
+	"time"
+)
+
+// eta estimates when a run of items will be done, from how long the items are (see itemLength) and how fast those
+// done so far went, so the estimate gets better as the run goes on.
+type eta struct {
+	start  time.Time
+	total  time.Duration // The length of every item of the run.
+	done   time.Duration // The length of those done.
+	window *activeHours  // Whose pauses don't count towards the pace.
+	paused time.Duration // How long window had paused for before the run.
+}
+
+func newETA(videos []VideoEntry, window *activeHours) *eta {
+	e := &eta{start: time.Now(), window: window, paused: window.pausedFor()}
+	for _, v := range videos {
+		e.total += itemLength(v)
+	}
+
+	return e
+}
+
+// finish counts v as done, and returns how long the rest of the run should take at the pace so far, not counting the
+// time it was paused for.
+func (e *eta) finish(v VideoEntry) time.Duration {
+	e.done += itemLength(v)
+	if e.done <= 0 {
+		return 0
+	}
+	busy := time.Since(e.start) - (e.window.pausedFor() - e.paused)
+	perItemSecond := float64(busy) / float64(e.done)
+
+	return time.Duration(perItemSecond * float64(e.total-e.done))
+}
+
+// logETA says how much of the run is left after n of total items, and when it should be done.
+func logETA(ctx context.Context, n, total int, left time.Duration) {
+	if n == total {
+		return
+	}
+	logf(ctx, tr("⏱️ %d/%d done, about %s left (until around %s)\n"), n, total, studyTime(left),
+		time.Now().Add(left).Format("15:04"))
+}
@@ -0,0 +1,145 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	eventLog    = "log"    // A progress message, as the CLI would print it.
+	eventStatus = "status" // The job changed status.
+	eventItem   = "item"   // An item of the course was done, or failed.
+)
+
+// Event is a progress event of a serve job, streamed by GET /jobs/{id}/events.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Message   string    `json:"message,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Done      int       `json:"done,omitempty"` // With the Total, how far along an item event is.
+	Total     int       `json:"total,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	ErrorCode string    `json:"errorCode,omitempty"`
+}
+
+// events keeps the events of a job, waking up whoever is streaming them.
+type events struct {
+	mu     sync.Mutex
+	list   []Event
+	closed bool
+	wake   chan struct{} // Closed (and replaced) on every change.
+}
+
+func newEvents() *events {
+	return &events{wake: make(chan struct{})}
+}
+
+func (e *events) add(ev Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	ev.Time = time.Now()
+	e.list = append(e.list, ev)
+	close(e.wake)
+	e.wake = make(chan struct{})
+}
+
+// close marks the job finished, ending the streams.
+func (e *events) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	e.closed = true
+	close(e.wake)
+}
+
+// since returns the events after the first n, whether there'll be any more, and a channel closed when there are.
+func (e *events) since(n int) ([]Event, bool, <-chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.list[min(n, len(e.list)):], e.closed, e.wake
+}
+
+var logPrefixRE = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} `)
+
+// Write turns log lines into events, so they can be tee'd off the log while a job runs.
+func (e *events) Write(b []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		var entry struct { // With -log-format json.
+			Msg string `json:"msg"`
+		}
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &entry) == nil {
+			line = entry.Msg
+		}
+		if line = logPrefixRE.ReplaceAllString(line, ""); line != "" {
+			e.add(Event{Type: eventLog, Message: line})
+		}
+	}
+
+	return len(b), nil
+}
+
+// handleEvents streams a job's events as Server-Sent Events, from the start, until the job is finished.
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.job(r.PathValue("id"))
+	if !ok || owns(r.Context(), &j) != nil {
+		writeError(w, http.StatusNotFound, errors.New("no such job"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming unsupported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	_ = j.events.watch(r.Context(), func(ev Event) error {
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, b); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+// watch calls send with each event, from the start, until the job is finished, ctx is done, or send fails.
+func (e *events) watch(ctx context.Context, send func(Event) error) error {
+	for n := 0; ; {
+		evs, closed, wake := e.since(n)
+		for _, ev := range evs {
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+		n += len(evs)
+		if closed {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package lld
+
+import (
+	"context"
+	"time"
+)
+
+// Internals exported for the tests of package lld_test.
+var (
+	Catalogs           = catalogs
+	FileKind           = fileKind
+	NewJobQueue        = newJobQueue
+	UpdateJob          = updateJob
+	ErrNoJob           = errNoJob
+	PlanStudy          = planStudy
+	EscapeICS          = escapeICS
+	FoldICS            = foldICS
+	NormalizeCourseURL = normalizeCourseURL
+	NormalizeVideoURL  = normalizeVideoURL
+	ParseByteSize      = parseByteSize
+	ParseActiveHours   = parseActiveHours
+	WithKind           = withKind
+	ErrorText          = errorText
+)
+
+// JobQueue is a jobQueue, see newJobQueue.
+type JobQueue = jobQueue
+
+// AddJob adds j to q.
+func AddJob(ctx context.Context, q JobQueue, j *Job) error {
+	return q.add(ctx, j)
+}
+
+// GetJob returns the job of q with the given ID.
+func GetJob(ctx context.Context, q JobQueue, id string) (*Job, error) {
+	return q.get(ctx, id)
+}
+
+// ActiveHours is an activeHours, see parseActiveHours.
+type ActiveHours = activeHours
+
+// Contains is whether t is inside the window.
+func (a *activeHours) Contains(t time.Time) bool {
+	return a.contains(t)
+}
+
+// Opens is when the window next opens after t.
+func (a *activeHours) Opens(t time.Time) time.Time {
+	return a.opens(t)
+}
+
+// Checkpoint is a checkpoint, see loadCheckpoint.
+type Checkpoint = checkpoint
+
+// LoadCheckpoint reads the checkpoint of the item saved as filename, as a run with(out) -refresh does.
+func LoadCheckpoint(filename string, refresh bool) *Checkpoint {
+	return loadCheckpoint(filename, &options{refresh: refresh})
+}
+
+// Done is whether stage was done with with, and what it saved is still there.
+func (c *checkpoint) Done(stage, with string) bool {
+	return c.done(stage, with)
+}
+
+// Run does stage with fn unless it's already done with with.
+func (c *checkpoint) Run(stage, with string, fn func() error) error {
+	return c.run(context.Background(), stage, with, fn)
+}
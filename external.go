@@ -0,0 +1,78 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Downloaders for -downloader.
+const (
+	downloaderInternal = "internal"
+	downloaderYtDlp    = "yt-dlp"
+	downloaderAria2c   = "aria2c"
+)
+
+// delegate hands t to the external -downloader, which brings its own retries, resuming, and segmenting. The cookies
+// go through stdin rather than the command line, so they don't show up in the process list.
+func (d *downloader) delegate(ctx context.Context, t *target) error {
+	cookie := cookieHeader(t.cookies)
+	part := partFile(t.filename)
+	var err error
+	switch d.external {
+	case downloaderYtDlp:
+		var config strings.Builder
+		if cookie != "" {
+			config.WriteString("--add-header " + configQuote("Cookie:"+cookie) + "\n")
+		}
+		if d.ipVersion != "" {
+			config.WriteString("--force-ipv" + d.ipVersion + "\n")
+		}
+		err = runCLI(ctx, strings.NewReader(config.String()), "yt-dlp",
+			"--config-locations", "-", "--quiet", "--no-part", "--force-overwrites", "-o", part, t.url)
+	case downloaderAria2c:
+		// aria2c reads the URL and its options from an input file, here stdin.
+		input := t.url + "\n  dir=" + filepath.Dir(part) + "\n  out=" + filepath.Base(part) + "\n"
+		if cookie != "" {
+			input += "  header=Cookie: " + cookie + "\n"
+		}
+		if d.ipVersion == ipVersion4 {
+			// aria2c can't be limited to IPv6, only kept off it.
+			input += "  disable-ipv6=true\n"
+		}
+		n := strconv.Itoa(d.segments)
+		err = runCLI(ctx, strings.NewReader(input), "aria2c",
+			"--quiet", "--allow-overwrite=true", "--auto-file-renaming=false", "-x", n, "-s", n, "-i", "-")
+	}
+	if err != nil {
+		_ = os.Remove(part)
+		return fmt.Errorf("failed to download %s: %w", tr(t.kind), err)
+	}
+	if err := complete(part, t.filename); err != nil {
+		return err
+	}
+	// They don't say how much they downloaded, but it's about the size of the file.
+	if info, err := os.Stat(t.filename); err == nil {
+		d.meter.add(info.Size())
+	}
+
+	return nil
+}
+
+func cookieHeader(cookies []*http.Cookie) string {
+	parts := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		parts = append(parts, c.String())
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// configQuote double quotes s for a yt-dlp config file, which is parsed like a shell command line.
+func configQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s) + `"`
+}
@@ -0,0 +1,47 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// setupFilters compiles -only and -exclude.
+func setupFilters(opts *options) error {
+	var err error
+	if opts.only != "" {
+		if opts.onlyRE, err = regexp.Compile(opts.only); err != nil {
+			return fmt.Errorf("bad -only: %w", err)
+		}
+	}
+	if opts.exclude != "" {
+		if opts.excludeRE, err = regexp.Compile(opts.exclude); err != nil {
+			return fmt.Errorf("bad -exclude: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// filterVideos drops the items -only and -exclude leave out, matching either their title or their section.
+func filterVideos(ctx context.Context, videos []VideoEntry, opts *options) []VideoEntry {
+	if opts.onlyRE == nil && opts.excludeRE == nil {
+		return videos
+	}
+	matches := func(re *regexp.Regexp, v VideoEntry) bool {
+		return re.MatchString(v.Title) || re.MatchString(v.Section)
+	}
+	kept := make([]VideoEntry, 0, len(videos))
+	for _, v := range videos {
+		if (opts.onlyRE != nil && !matches(opts.onlyRE, v)) || (opts.excludeRE != nil && matches(opts.excludeRE, v)) {
+			logf(ctx, tr("⏭️ skipping (filtered out): %s: %s\n"), v.Section, v.Title)
+			continue
+		}
+		kept = append(kept, v)
+	}
+	if len(kept) < len(videos) {
+		logf(ctx, tr("🎯 %d of %d item(s) left after filtering\n"), len(kept), len(videos))
+	}
+
+	return kept
+}
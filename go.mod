@@ -2,10 +2,12 @@ module github.com/jh125486/lld
 
 go 1.24.2
 
-require github.com/chromedp/chromedp v0.13.6
+require (
+	github.com/chromedp/cdproto v0.0.0-20250530212709-4dcc110a7b92
+	github.com/chromedp/chromedp v0.13.6
+)
 
 require (
-	github.com/chromedp/cdproto v0.0.0-20250530212709-4dcc110a7b92 // indirect
 	github.com/chromedp/sysutil v1.1.0 // indirect
 	github.com/go-json-experiment/json v0.0.0-20250517221953-25912455fbc8 // indirect
 	github.com/gobwas/httphead v0.1.0 // indirect
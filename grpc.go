@@ -0,0 +1,353 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// grpcPath is where the methods of the Jobs service of lld.proto are served, e.g. /lld.v1.Jobs/SubmitJob.
+const grpcPath = "/lld.v1.Jobs/"
+
+// maxGRPCMessage caps the size of a gRPC message read, as gRPC's default does.
+const maxGRPCMessage = 4 << 20
+
+// gRPC status codes, of those the API answers with.
+const (
+	grpcOK                 = 0
+	grpcCanceled           = 1
+	grpcUnknown            = 2
+	grpcInvalidArgument    = 3
+	grpcNotFound           = 5
+	grpcPermissionDenied   = 7
+	grpcFailedPrecondition = 9
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+	grpcUnauthenticated    = 16
+)
+
+// RPCError is the status of a failed call of ServeClient, as the daemon answered it.
+type RPCError struct {
+	Code    int // The gRPC status code, e.g. 5 for NotFound.
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error: code = %d desc = %s", e.Code, e.Message)
+}
+
+// grpcMethod serves a call of a method of the Jobs service: it reads the request message, and sends the response
+// messages, just the one unless it's streaming.
+type grpcMethod func(ctx context.Context, req []byte, send func(msg []byte) error) error
+
+func (s *server) grpcMethods() map[string]grpcMethod {
+	return map[string]grpcMethod{
+		"SubmitJob": s.grpcSubmitJob,
+		"WatchJob":  s.grpcWatchJob,
+		"ListJobs":  s.grpcListJobs,
+		"Cancel":    s.grpcCancel,
+	}
+}
+
+// handleGRPC serves the gRPC API of lld.proto, next to the HTTP API. A gRPC call is a POST over HTTP/2 of the
+// request message, answered with the response messages and the call's status in the trailers.
+func (s *server) handleGRPC(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	flusher, _ := w.(http.Flusher)
+
+	method, ok := s.grpcMethods()[r.PathValue("method")]
+	if !ok {
+		writeGRPCStatus(w, grpcUnimplemented, "unknown method "+r.PathValue("method"))
+		return
+	}
+	req, err := readGRPCMessage(r.Body)
+	if err != nil {
+		writeGRPCStatus(w, grpcInvalidArgument, err.Error())
+		return
+	}
+	err = method(r.Context(), req, func(msg []byte) error {
+		if _, err := w.Write(grpcFrame(msg)); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		writeGRPCStatus(w, grpcCode(err), err.Error())
+		return
+	}
+
+	writeGRPCStatus(w, grpcOK, "")
+}
+
+// grpcCode is the gRPC status code of a failed call, as writeJobError's HTTP status is for the HTTP API.
+func grpcCode(err error) int {
+	var (
+		conflict   *conflictError
+		fe         *fieldError
+		permission *permissionError
+	)
+	switch {
+	case errors.Is(err, errNoJob):
+		return grpcNotFound
+	case errors.As(err, &conflict):
+		return grpcFailedPrecondition
+	case errors.As(err, &permission):
+		return grpcPermissionDenied
+	case errors.As(err, &fe), errors.Is(err, errBadProto):
+		return grpcInvalidArgument
+	case errors.Is(err, context.Canceled):
+		return grpcCanceled
+	}
+
+	return grpcInternal
+}
+
+func writeGRPCStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set("Grpc-Message", grpcEscape(msg))
+	}
+}
+
+// grpcEscape percent-encodes the grpc-message of a status, which must be printable ASCII.
+func grpcEscape(msg string) string {
+	var sb strings.Builder
+	for i := range len(msg) {
+		if c := msg[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// grpcFrame prefixes msg with the uncompressed flag and its length, as gRPC sends messages.
+func grpcFrame(msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg))) //nolint:gosec // Bounded by maxGRPCMessage.
+
+	return append(frame, msg...)
+}
+
+// readGRPCMessage reads the next message sent, returning io.EOF once there are no more.
+func readGRPCMessage(r io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	if prefix[0] != 0 {
+		return nil, errors.New("compressed messages aren't supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxGRPCMessage {
+		return nil, fmt.Errorf("message of %d bytes is larger than the maximum of %d", size, maxGRPCMessage)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return msg, nil
+}
+
+func (s *server) grpcSubmitJob(ctx context.Context, b []byte, send func([]byte) error) error {
+	req, err := unmarshalSubmitRequest(b)
+	if err != nil {
+		return err
+	}
+	j, err := s.submit(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return send(marshalJob(&j))
+}
+
+func (s *server) grpcWatchJob(ctx context.Context, b []byte, send func([]byte) error) error {
+	id, err := unmarshalID(b)
+	if err != nil {
+		return err
+	}
+	j, ok := s.job(id)
+	if !ok || owns(ctx, &j) != nil {
+		return errNoJob
+	}
+
+	return j.events.watch(ctx, func(ev Event) error {
+		return send(marshalEvent(ev))
+	})
+}
+
+func (s *server) grpcListJobs(ctx context.Context, _ []byte, send func([]byte) error) error {
+	jobs, err := s.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	return send(marshalJobs(jobs))
+}
+
+func (s *server) grpcCancel(ctx context.Context, b []byte, send func([]byte) error) error {
+	id, err := unmarshalID(b)
+	if err != nil {
+		return err
+	}
+	j, err := s.cancel(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return send(marshalJob(&j))
+}
+
+// ServeClient calls the gRPC API of an lld serve daemon, for tools that queue and follow its jobs. See lld.proto to
+// generate clients in other languages.
+type ServeClient struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// NewServeClient returns a client of the lld serve daemon listening on addr, e.g. localhost:8080, calling it with
+// token: the daemon's, or that of the user to submit jobs as (see serve's -config). It's empty if none is needed.
+func NewServeClient(addr, token string) *ServeClient {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &ServeClient{
+		url:   "http://" + addr + grpcPath,
+		token: token,
+		http:  &http.Client{Transport: &http.Transport{Protocols: &protocols}},
+	}
+}
+
+// SubmitJob queues a course for download.
+func (c *ServeClient) SubmitJob(ctx context.Context, req SubmitRequest) (*Job, error) {
+	var j Job
+	err := c.call(ctx, "SubmitJob", marshalSubmitRequest(req), func(msg []byte) (err error) {
+		j, err = unmarshalJob(msg)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+// WatchJob calls fn with each of a job's events, from the start, until the job is finished, ctx is done, or fn
+// returns an error.
+func (c *ServeClient) WatchJob(ctx context.Context, id string, fn func(Event) error) error {
+	return c.call(ctx, "WatchJob", marshalID(id), func(msg []byte) error {
+		ev, err := unmarshalEvent(msg)
+		if err != nil {
+			return err
+		}
+		return fn(ev)
+	})
+}
+
+// ListJobs lists the jobs of the client's token (every job, for the daemon's), oldest first.
+func (c *ServeClient) ListJobs(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	err := c.call(ctx, "ListJobs", nil, func(msg []byte) (err error) {
+		jobs, err = unmarshalJobs(msg)
+		return err
+	})
+
+	return jobs, err
+}
+
+// Cancel cancels a queued or running job.
+func (c *ServeClient) Cancel(ctx context.Context, id string) (*Job, error) {
+	var j Job
+	err := c.call(ctx, "Cancel", marshalID(id), func(msg []byte) (err error) {
+		j, err = unmarshalJob(msg)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+// call calls method with the request message req, handing each response message to recv.
+func (c *ServeClient) call(ctx context.Context, method string, req []byte, recv func(msg []byte) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+method, bytes.NewReader(grpcFrame(req)))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	r.Header.Set("Content-Type", "application/grpc")
+	r.Header.Set("Te", "trailers")
+	if c.token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return &RPCError{Code: grpcUnavailable, Message: err.Error()}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return &RPCError{Code: grpcUnknown, Message: resp.Status}
+	}
+	// A call failing straight away answers with its status in the headers.
+	if err := grpcStatus(resp.Header); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := readGRPCMessage(resp.Body)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return &RPCError{Code: grpcInternal, Message: err.Error()}
+		}
+		if err := recv(msg); err != nil {
+			return err
+		}
+	}
+
+	if resp.Trailer.Get("Grpc-Status") == "" {
+		return &RPCError{Code: grpcInternal, Message: "the call ended without a status"}
+	}
+
+	return grpcStatus(resp.Trailer)
+}
+
+// grpcStatus returns the error of the status in h, if it has one and it isn't OK.
+func grpcStatus(h http.Header) error {
+	status := h.Get("Grpc-Status")
+	if status == "" || status == "0" {
+		return nil
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		code = grpcUnknown
+	}
+	msg, err := url.PathUnescape(h.Get("Grpc-Message"))
+	if err != nil {
+		msg = h.Get("Grpc-Message")
+	}
+
+	return &RPCError{Code: code, Message: msg}
+}
@@ -0,0 +1,332 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// harMaxBody is the largest response body kept in a HAR, so media segments don't bloat it.
+const harMaxBody = 5 << 20
+
+// HAR is an HTTP Archive (1.2) of the browser's traffic, as written by -har.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the body of a HAR.
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Pages   []HARPage  `json:"pages"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator is the tool that wrote a HAR.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HARPage is a page of a HAR, here a visit to an item (or the table of contents), which its entries refer to.
+type HARPage struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     map[string]int `json:"pageTimings"`
+}
+
+// HAREntry is a request and its response.
+type HAREntry struct {
+	PageRef         string         `json:"pageref,omitempty"`
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	Time            float64        `json:"time"` // Milliseconds.
+	Request         HARRequest     `json:"request"`
+	Response        HARResponse    `json:"response"`
+	Cache           struct{}       `json:"cache"`
+	Timings         map[string]any `json:"timings"`
+	ResourceType    string         `json:"_resourceType,omitempty"`
+	Error           string         `json:"_error,omitempty"`
+	started         time.Time      // On Chrome's monotonic clock.
+}
+
+// HARRequest is the request of an entry.
+type HARRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Headers     []HARNameVal `json:"headers"`
+	QueryString []HARNameVal `json:"queryString"`
+	Cookies     []HARNameVal `json:"cookies"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+// HARResponse is the response of an entry.
+type HARResponse struct {
+	Status      int64        `json:"status"`
+	StatusText  string       `json:"statusText"`
+	HTTPVersion string       `json:"httpVersion"`
+	Headers     []HARNameVal `json:"headers"`
+	Cookies     []HARNameVal `json:"cookies"`
+	Content     HARContent   `json:"content"`
+	RedirectURL string       `json:"redirectURL"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int64        `json:"bodySize"`
+}
+
+// HARContent is the body of a response; Text is only kept for text, up to harMaxBody.
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+// HARNameVal is a header or query parameter.
+type HARNameVal struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harRecorder records the traffic of the browser's tabs into a HAR, grouped by the item each tab was visiting.
+type harRecorder struct {
+	filename string
+	mu       sync.Mutex
+	har      HAR
+	current  map[string]string // The page each tab is on.
+	pending  map[network.RequestID]*HAREntry
+}
+
+type harKey struct{}
+
+func newHARRecorder(filename string) *harRecorder {
+	return &harRecorder{
+		filename: filename,
+		har:      HAR{Log: HARLog{Version: "1.2", Creator: HARCreator{Name: "lld", Version: currentVersion()}}},
+		current:  map[string]string{},
+		pending:  map[network.RequestID]*HAREntry{},
+	}
+}
+
+// withHAR returns ctx with the recorder to group the traffic of its visits with, if any.
+func withHAR(ctx context.Context, h *harRecorder) context.Context {
+	if h == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, harKey{}, h)
+}
+
+func harFrom(ctx context.Context) *harRecorder {
+	h, _ := ctx.Value(harKey{}).(*harRecorder)
+	return h
+}
+
+func tabID(ctx context.Context) string {
+	if c := chromedp.FromContext(ctx); c != nil && c.Target != nil {
+		return string(c.Target.TargetID)
+	}
+
+	return ""
+}
+
+// startPage starts a page of the HAR for what the tab of ctx visits next, saving the HAR so far.
+func (h *harRecorder) startPage(ctx context.Context, title string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	id := fmt.Sprintf("page_%d", len(h.har.Log.Pages)+1)
+	h.har.Log.Pages = append(h.har.Log.Pages, HARPage{StartedDateTime: time.Now(), ID: id, Title: title, PageTimings: map[string]int{}})
+	h.current[tabID(ctx)] = id
+	h.mu.Unlock()
+	if err := h.save(); err != nil {
+		logln(ctx, errorText(err))
+	}
+}
+
+// listen records the traffic of the tab of ctx, from now on (so not that of logging in).
+func (h *harRecorder) listen(ctx context.Context) error {
+	if h == nil {
+		return nil
+	}
+	tab := tabID(ctx)
+	chromedp.ListenTarget(ctx, func(ev any) {
+		switch e := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			h.request(tab, e)
+		case *network.EventResponseReceived:
+			h.response(e.RequestID, e.Response)
+		case *network.EventLoadingFinished:
+			if entry := h.finish(e.RequestID, e.Timestamp, e.EncodedDataLength, ""); entry != nil {
+				go h.body(ctx, e.RequestID, entry)
+			}
+		case *network.EventLoadingFailed:
+			if entry := h.finish(e.RequestID, e.Timestamp, 0, e.ErrorText); entry != nil {
+				h.add(entry)
+			}
+		}
+	})
+
+	return chromedp.Run(ctx, network.Enable())
+}
+
+func (h *harRecorder) request(tab string, e *network.EventRequestWillBeSent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if prev, ok := h.pending[e.RequestID]; ok && e.RedirectResponse != nil {
+		// The same request ID carries on after a redirect, so the redirect is an entry of its own.
+		setHARResponse(prev, e.RedirectResponse)
+		prev.Response.RedirectURL = e.Request.URL
+		prev.Time = millis(e.Timestamp, prev.started)
+		h.har.Log.Entries = append(h.har.Log.Entries, *prev)
+	}
+	entry := &HAREntry{
+		PageRef:         h.current[tab],
+		StartedDateTime: time.Now(),
+		Request: HARRequest{
+			Method:      e.Request.Method,
+			URL:         e.Request.URL + e.Request.URLFragment,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     harHeaders(e.Request.Headers),
+			QueryString: harQuery(e.Request.URL),
+			Cookies:     []HARNameVal{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response:     HARResponse{Headers: []HARNameVal{}, Cookies: []HARNameVal{}, HeadersSize: -1, BodySize: -1},
+		Timings:      map[string]any{"send": 0, "wait": 0, "receive": 0},
+		ResourceType: string(e.Type),
+	}
+	if e.WallTime != nil {
+		entry.StartedDateTime = e.WallTime.Time()
+	}
+	if e.Timestamp != nil {
+		entry.started = e.Timestamp.Time()
+	}
+	h.pending[e.RequestID] = entry
+}
+
+func (h *harRecorder) response(id network.RequestID, resp *network.Response) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if entry, ok := h.pending[id]; ok {
+		setHARResponse(entry, resp)
+	}
+}
+
+func setHARResponse(entry *HAREntry, resp *network.Response) {
+	entry.Response.Status = resp.Status
+	entry.Response.StatusText = resp.StatusText
+	entry.Response.HTTPVersion = strings.ToUpper(resp.Protocol)
+	entry.Response.Headers = harHeaders(resp.Headers)
+	entry.Response.Content.MimeType = resp.MimeType
+}
+
+// finish takes the entry of the request off the pending ones, once it's loaded or failed.
+func (h *harRecorder) finish(id network.RequestID, ts *cdp.MonotonicTime, size float64, errText string) *HAREntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.pending[id]
+	if !ok {
+		return nil
+	}
+	delete(h.pending, id)
+	entry.Time = millis(ts, entry.started)
+	entry.Timings["wait"] = entry.Time
+	entry.Response.BodySize = int64(size)
+	entry.Response.Content.Size = int64(size)
+	entry.Error = errText
+
+	return entry
+}
+
+// body adds the entry with its response body, if it's text.
+func (h *harRecorder) body(ctx context.Context, id network.RequestID, entry *HAREntry) {
+	if textMime(entry.Response.Content.MimeType) && entry.Response.BodySize <= harMaxBody {
+		_ = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			b, err := network.GetResponseBody(id).Do(ctx)
+			if err == nil {
+				entry.Response.Content.Text = string(b)
+				entry.Response.Content.Size = int64(len(b))
+			}
+			return err
+		}))
+	}
+	h.add(entry)
+}
+
+func (h *harRecorder) add(entry *HAREntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.har.Log.Entries = append(h.har.Log.Entries, *entry)
+}
+
+// save writes the HAR recorded so far.
+func (h *harRecorder) save() error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	slices.SortStableFunc(h.har.Log.Entries, func(a, b HAREntry) int { return a.StartedDateTime.Compare(b.StartedDateTime) })
+
+	return writeJSONFile(h.filename, h.har)
+}
+
+// millis is how long it's been from started to ts, in milliseconds.
+func millis(ts *cdp.MonotonicTime, started time.Time) float64 {
+	if ts == nil || started.IsZero() {
+		return 0
+	}
+
+	return float64(ts.Time().Sub(started).Microseconds()) / 1000
+}
+
+func textMime(mime string) bool {
+	return strings.HasPrefix(mime, "text/") || strings.Contains(mime, "json") || strings.Contains(mime, "javascript") ||
+		strings.Contains(mime, "xml")
+}
+
+// harRedacted are the headers whose values are left out of a HAR, as they'd give the session away.
+func harRedacted() []string {
+	return []string{"authorization", "cookie", "set-cookie", "csrf-token", "x-li-identity"}
+}
+
+func harHeaders(headers network.Headers) []HARNameVal {
+	out := make([]HARNameVal, 0, len(headers))
+	for name, v := range headers {
+		value := fmt.Sprint(v)
+		if slices.Contains(harRedacted(), strings.ToLower(name)) {
+			value = "REDACTED"
+		}
+		out = append(out, HARNameVal{Name: name, Value: value})
+	}
+	slices.SortFunc(out, func(a, b HARNameVal) int { return strings.Compare(a.Name, b.Name) })
+
+	return out
+}
+
+func harQuery(u string) []HARNameVal {
+	out := []HARNameVal{}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return out
+	}
+	for name, values := range parsed.Query() {
+		for _, v := range values {
+			out = append(out, HARNameVal{Name: name, Value: v})
+		}
+	}
+	slices.SortFunc(out, func(a, b HARNameVal) int { return strings.Compare(a.Name, b.Name) })
+
+	return out
+}
@@ -0,0 +1,83 @@
+package lld
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// sessionCheckTTL is how long a check of the session is trusted, as it's a request to LinkedIn.
+const sessionCheckTTL = 5 * time.Minute
+
+// Health is the body of GET /readyz: "ok", or why each of the checks failed.
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// sessionCheck caches the last check of the daemon's session.
+type sessionCheck struct {
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// handleHealthz tells that the daemon is up, for liveness probes.
+func handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, Health{Status: "ok"})
+}
+
+// handleReadyz tells whether the daemon can run jobs, for readiness probes: its browser is alive, its session is
+// still logged in, and the -queue responds.
+func (s *server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	checks := map[string]error{
+		"browser": s.checkBrowser(),
+		"session": s.checkSession(ctx),
+		"queue":   s.checkQueue(ctx),
+	}
+	health, status := Health{Status: "ok", Checks: map[string]string{}}, http.StatusOK
+	for name, err := range checks {
+		health.Checks[name] = "ok"
+		if err != nil {
+			health.Checks[name] = err.Error()
+			health.Status, status = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(w, status, health)
+}
+
+func (s *server) checkBrowser() error {
+	_, err := chromedp.Targets(s.browser)
+
+	return err
+}
+
+// checkSession asks LinkedIn whether the daemon's session still works, at most every sessionCheckTTL.
+func (s *server) checkSession(ctx context.Context) error {
+	s.sessionCheck.mu.Lock()
+	defer s.sessionCheck.mu.Unlock()
+	if time.Since(s.sessionCheck.checked) < sessionCheckTTL {
+		return s.sessionCheck.err
+	}
+
+	cookies, err := sessionCookies(s.browser)
+	if err == nil {
+		_, err = whoami(ctx, learningBaseFrom(s.browser), cookies)
+	}
+	s.sessionCheck.checked, s.sessionCheck.err = time.Now(), err
+
+	return err
+}
+
+func (s *server) checkQueue(ctx context.Context) error {
+	_, err := s.queue.get(ctx, "0")
+
+	return err
+}
@@ -0,0 +1,79 @@
+package lld
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Hook events, also given to the hook as LLD_EVENT.
+const (
+	hookVideoDownloaded = "on_video_downloaded"
+	hookCourseComplete  = "on_course_complete"
+	hookFailure         = "on_failure"
+)
+
+// runHook runs the user's command for event through the shell, with env (KEY=value) describing what happened on top of
+// the environment. Its output is logged; a failing hook is only warned about, as the download itself went fine.
+func runHook(ctx context.Context, command, event string, env ...string) {
+	if command == "" {
+		return
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	cmd.Env = append(append(os.Environ(), "LLD_EVENT="+event), env...)
+	cmd.Stdout = logWriter{ctx: ctx}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Run(); err != nil {
+		logf(ctx, tr("⚠️ %s hook failed: %v"), event, err)
+	}
+}
+
+// itemHooks runs -on-video-downloaded for an item that was saved, or -on-failure for one that wasn't.
+func itemHooks(ctx context.Context, video VideoEntry, opts *options, err error) {
+	env := []string{
+		"LLD_SECTION=" + video.Section,
+		"LLD_TITLE=" + video.Title,
+		"LLD_TYPE=" + video.Type,
+		"LLD_URL=" + video.Href,
+		"LLD_FILE=" + video.filename,
+		"LLD_FILES=" + strings.Join(itemFiles(video.filename), string(os.PathListSeparator)),
+	}
+	if err != nil {
+		runHook(ctx, opts.onFailure, hookFailure, append(env, "LLD_ERROR="+err.Error(), "LLD_ERROR_CODE="+errorCode(err))...)
+		return
+	}
+	runHook(ctx, opts.onVideoDownloaded, hookVideoDownloaded, env...)
+}
+
+// courseHooks runs -on-course-complete for a course that was downloaded (if only partly, see LLD_FAILED), or
+// -on-failure for one that couldn't be.
+func courseHooks(ctx context.Context, opts *options, courseURL, dir string, course *Course, err error) {
+	env := []string{"LLD_COURSE_URL=" + courseURL, "LLD_COURSE_DIR=" + dir}
+	if err != nil {
+		runHook(ctx, opts.onFailure, hookFailure, append(env, "LLD_ERROR="+err.Error(), "LLD_ERROR_CODE="+errorCode(err))...)
+		return
+	}
+	runHook(ctx, opts.onCourseComplete, hookCourseComplete,
+		append(env, "LLD_COURSE_TITLE="+course.Title, "LLD_FAILED="+strconv.Itoa(len(course.failures)))...)
+}
+
+// logWriter logs what's written to it, line by line, with the fields of ctx.
+type logWriter struct {
+	ctx context.Context //nolint:containedctx // Only for the fields of the messages.
+}
+
+func (w logWriter) Write(b []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		if line != "" {
+			logln(w.ctx, line)
+		}
+	}
+
+	return len(b), nil
+}
@@ -0,0 +1,102 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// activeHours is the -active-hours window of the day, in local time, that items are downloaded in, e.g. at night to
+// leave the bandwidth to others during the day. It may wrap around midnight.
+type activeHours struct {
+	start, end int // Minutes since midnight; end is excluded.
+
+	mu        sync.Mutex
+	announced time.Time     // When the pause last logged about ends, so that the tabs don't all log it.
+	paused    time.Duration // How long the run was paused for in all.
+}
+
+// parseActiveHours parses a window like 22:00-06:00.
+func parseActiveHours(s string) (*activeHours, error) {
+	from, to, ok := strings.Cut(s, "-")
+	start, err1 := parseClock(from)
+	end, err2 := parseClock(to)
+	if !ok || err1 != nil || err2 != nil || start == end {
+		return nil, fmt.Errorf("unsupported -active-hours %q, expected a window like 22:00-06:00", s)
+	}
+
+	return &activeHours{start: start, end: end}, nil
+}
+
+// parseClock parses a time of day like 06:00, returning the minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains is whether t is inside the window.
+func (a *activeHours) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if a.start < a.end {
+		return a.start <= m && m < a.end
+	}
+
+	return m >= a.start || m < a.end
+}
+
+// opens is when the window next opens after t.
+func (a *activeHours) opens(t time.Time) time.Time {
+	for day := t.Day(); ; day++ {
+		next := time.Date(t.Year(), t.Month(), day, a.start/60, a.start%60, 0, 0, t.Location())
+		// time.Date puts a start that the clocks skip, going forward for DST, before the gap rather than past it.
+		if skipped := (a.start - next.Hour()*60 - next.Minute() + 24*60) % (24 * 60); skipped > 0 {
+			next = next.Add(time.Duration(skipped) * time.Minute)
+		}
+		if next.After(t) {
+			return next
+		}
+	}
+}
+
+// wait pauses until the window is open, if it isn't, or ctx is done. Items already being downloaded are let finish.
+func (a *activeHours) wait(ctx context.Context) {
+	if a == nil {
+		return
+	}
+	announced, start := false, time.Now()
+	for now := time.Now(); !a.contains(now); now = time.Now() {
+		until := a.opens(now)
+		a.mu.Lock()
+		if !a.announced.Equal(until) {
+			a.announced, announced = until, true
+			logf(ctx, tr("😴 Outside -active-hours, pausing until %s.\n"), until.Format("Mon 15:04"))
+		}
+		a.mu.Unlock()
+		if err := sleepCtx(ctx, time.Until(until)); err != nil {
+			return
+		}
+	}
+	if announced {
+		a.mu.Lock()
+		a.paused += time.Since(start)
+		a.mu.Unlock()
+		logln(ctx, tr("⏰ Inside -active-hours, resuming."))
+	}
+}
+
+// pausedFor is how long the run was paused outside the window in all.
+func (a *activeHours) pausedFor() time.Duration {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.paused
+}
@@ -0,0 +1,132 @@
+package lld_test
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // For the DST changes of America/New_York.
+
+	"github.com/jh125486/lld"
+)
+
+// newYork is a time zone with DST: it springs forward at 02:00 on 2026-03-08, and falls back at 02:00 on 2026-11-01.
+func newYork(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loc
+}
+
+func activeHours(t *testing.T, window string) *lld.ActiveHours {
+	t.Helper()
+	a, err := lld.ParseActiveHours(window)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestParseActiveHours(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		window  string
+		wantErr bool
+	}{
+		{window: "22:00-06:00"},
+		{window: "09:00-17:30"},
+		{window: "9:00 - 17:30"},
+		{window: "00:00-23:59"},
+		{window: "", wantErr: true},
+		{window: "22:00", wantErr: true},
+		{window: "22:00-22:00", wantErr: true},
+		{window: "24:00-06:00", wantErr: true},
+		{window: "22:00-06:60", wantErr: true},
+		{window: "10pm-6am", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			t.Parallel()
+			if _, err := lld.ParseActiveHours(tt.window); (err != nil) != tt.wantErr {
+				t.Errorf("parseActiveHours(%q) error = %v, want error %t", tt.window, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActiveHoursContains(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		window string
+		at     string // Local time in New York.
+		want   bool
+	}{
+		{window: "09:00-17:00", at: "2026-10-16 08:59", want: false},
+		{window: "09:00-17:00", at: "2026-10-16 09:00", want: true},
+		{window: "09:00-17:00", at: "2026-10-16 16:59", want: true},
+		{window: "09:00-17:00", at: "2026-10-16 17:00", want: false},
+		{window: "22:00-06:00", at: "2026-10-16 21:59", want: false},
+		{window: "22:00-06:00", at: "2026-10-16 22:00", want: true},
+		{window: "22:00-06:00", at: "2026-10-16 23:59", want: true},
+		{window: "22:00-06:00", at: "2026-10-17 00:00", want: true},
+		{window: "22:00-06:00", at: "2026-10-17 05:59", want: true},
+		{window: "22:00-06:00", at: "2026-10-17 06:00", want: false},
+		{window: "22:00-06:00", at: "2026-10-17 12:00", want: false},
+		{window: "01:00-03:00", at: "2026-03-08 01:59", want: true},
+		{window: "01:00-03:00", at: "2026-03-08 03:00", want: false}, // An hour after 01:59, as 02:00 became 03:00.
+		{window: "01:00-03:00", at: "2026-11-01 01:30", want: true},  // Either of them.
+	}
+	loc := newYork(t)
+	for _, tt := range tests {
+		t.Run(tt.window+" "+tt.at, func(t *testing.T) {
+			t.Parallel()
+			at, err := time.ParseInLocation(time.DateOnly+" 15:04", tt.at, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := activeHours(t, tt.window).Contains(at); got != tt.want {
+				t.Errorf("contains(%s) = %t, want %t", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveHoursOpens(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		window string
+		at     string // In New York, with its zone.
+		want   string
+	}{
+		{name: "later today", window: "22:00-06:00", at: "2026-10-16 12:00 EDT", want: "2026-10-16 22:00 EDT"},
+		{name: "after midnight", window: "22:00-06:00", at: "2026-10-17 02:00 EDT", want: "2026-10-17 22:00 EDT"},
+		{name: "tomorrow", window: "22:00-06:00", at: "2026-10-16 23:00 EDT", want: "2026-10-17 22:00 EDT"},
+		{name: "as it opens", window: "22:00-06:00", at: "2026-10-16 22:00 EDT", want: "2026-10-17 22:00 EDT"},
+		{name: "over the fall back", window: "22:00-06:00", at: "2026-10-31 23:00 EDT", want: "2026-11-01 22:00 EST"},
+		{name: "in the repeated hour", window: "01:30-02:00", at: "2026-11-01 00:00 EDT", want: "2026-11-01 01:30 EDT"},
+		{name: "over the spring forward", window: "22:00-06:00", at: "2026-03-07 23:00 EST", want: "2026-03-08 22:00 EDT"},
+		{name: "in the skipped hour", window: "02:30-05:00", at: "2026-03-07 12:00 EST", want: "2026-03-08 03:30 EDT"},
+	}
+	loc := newYork(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			const layout = time.DateOnly + " 15:04 MST"
+			at, err := time.ParseInLocation(layout, tt.at, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := activeHours(t, tt.window)
+			got := a.Opens(at)
+			if got.Format(layout) != tt.want {
+				t.Errorf("opens(%s) = %s, want %s", tt.at, got.Format(layout), tt.want)
+			}
+			if !a.Contains(got) {
+				t.Errorf("opens(%s) = %s, outside %s", tt.at, got.Format(layout), tt.window)
+			}
+		})
+	}
+}
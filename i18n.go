@@ -0,0 +1,552 @@
+package lld
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// translations holds the active language's messages keyed by their English format string; nil means English.
+var translations map[string]string //nolint:gochecknoglobals // Set once from -lang before any output.
+
+// tr translates a user-facing message (usually a format string), falling back to the English original.
+func tr(msg string) string {
+	if t, ok := translations[msg]; ok {
+		return t
+	}
+
+	return msg
+}
+
+// setLang selects the message catalog. An empty lang is taken from the environment, quietly falling back to English.
+func setLang(lang string) error {
+	explicit := lang != ""
+	if !explicit {
+		lang = envLang()
+	}
+	if lang == "en" {
+		translations = nil
+		return nil
+	}
+
+	cats := catalogs()
+	t, ok := cats[lang]
+	switch {
+	case ok:
+		translations = t
+	case explicit:
+		langs := []string{"en"}
+		for l := range cats {
+			langs = append(langs, l)
+		}
+		slices.Sort(langs)
+
+		return fmt.Errorf("❌ unsupported language %q (supported: %s)", lang, strings.Join(langs, ", "))
+	default:
+		translations = nil
+	}
+
+	return nil
+}
+
+// envLang returns the two-letter language from the usual locale variables, e.g. "de" for LANG=de_DE.UTF-8.
+func envLang() string {
+	for _, k := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(k); len(v) >= 2 {
+			return strings.ToLower(v[:2])
+		}
+	}
+
+	return "en"
+}
+
+// catalogs are the translations of every message passed to tr, of the fixed error messages errorText translates, and of
+// the item kinds, stages, job statuses, and license types formatted into them, by language. TestCatalogs checks none of
+// the messages passed to tr are missing.
+//
+//nolint:lll // Each message stays on one line, to be found by its English text.
+func catalogs() map[string]map[string]string {
+	return map[string]map[string]string{
+		"es": {
+			"video":            "vídeo",
+			"audio":            "audio",
+			"document":         "documento",
+			"transcript":       "transcripción",
+			"media":            "multimedia",
+			"quiz":             "cuestionario",
+			"postprocess":      "posprocesado",
+			"done":             "terminado",
+			"failed":           "fallido",
+			"canceled":         "cancelado",
+			"individual":       "individual",
+			"enterprise (SSO)": "empresa (SSO)",
+
+			"❌ You must specify at least one of -transcripts or -videos to download.": "❌ Debe indicar al menos -transcripts o -videos para descargar.",
+			"You must specify at least one of -transcripts or -videos to download.":   "Debe indicar al menos -transcripts o -videos para descargar.",
+			"🚀 Logging in via SSO...":                           "🚀 Iniciando sesión mediante SSO...",
+			"✅ Logged in.":                                      "✅ Sesión iniciada.",
+			"✅ All courses info saved.":                         "✅ Toda la información de los cursos se ha guardado.",
+			"📖 Parsing course details.":                         "📖 Analizando los detalles del curso.",
+			"📚 Parsing course structure.":                       "📚 Analizando la estructura del curso.",
+			"👤 Parsing author page.":                            "👤 Analizando la página del autor.",
+			"❌ Failed to parse author: %v":                      "❌ No se pudo analizar el autor: %v",
+			"👤 %s has %d course(s)\n":                           "👤 %s tiene %d curso(s)\n",
+			"💾 author index saved: %s\n":                        "💾 índice de autores guardado: %s\n",
+			"⚠️ Failed to parse course details: %v":             "⚠️ No se pudieron analizar los detalles del curso: %v",
+			"🎯 Found %d video(s) across %d sections\n":          "🎯 Se encontraron %d vídeo(s) en %d secciones\n",
+			"🔗 Found %d related course(s)\n":                    "🔗 Se encontraron %d curso(s) relacionado(s)\n",
+			"🔗 Following related course: %s\n":                  "🔗 Siguiendo el curso relacionado: %s\n",
+			"💾 course saved: %s\n":                              "💾 curso guardado: %s\n",
+			"💾 README saved: %s\n":                              "💾 README guardado: %s\n",
+			"💾 transcript saved: %s\n":                          "💾 transcripción guardada: %s\n",
+			"💾 %s saved: %s\n":                                  "💾 %s guardado: %s\n",
+			"%v -> skipping.":                                   "%v -> omitiendo.",
+			"🔒 protected content, transcript-only: %s":          "🔒 contenido protegido, solo transcripción: %s",
+			"👆 No video source yet, clicking play...":           "👆 Aún no hay fuente de vídeo, pulsando reproducir...",
+			"🚧 Rate limited. Sleeping a minute and retrying...": "🚧 Límite de peticiones alcanzado. Esperando un minuto y reintentando...",
+			"❌ navigation failed (%v), retrying\n":              "❌ la navegación falló (%v), reintentando\n",
+			"empty video URL found":                             "la URL del vídeo está vacía",
+			"empty audio URL found":                             "la URL del audio está vacía",
+			"empty document URL found":                          "la URL del documento está vacía",
+
+			" or ":                                  " o ",
+			"Removed":                               "Eliminados",
+			"Would remove":                          "Se eliminarían",
+			"a learning path":                       "una ruta de aprendizaje",
+			"a listing page":                        "una página de listado",
+			"an instructor's page (see lld author)": "la página de un instructor (ver lld author)",
+			"checksum mismatch":                     "la suma de comprobación no coincide",
+			"course: want %q (%s), got %q (%s)":     "curso: se esperaba %q (%s), se obtuvo %q (%s)",
+			"dangling link":                         "enlace roto",
+			"empty directory":                       "directorio vacío",
+			"empty file":                            "archivo vacío",
+			"item %d (%s):\n  want %s\n  got  %s":   "elemento %d (%s):\n  se esperaba %s\n  se obtuvo   %s",
+			"items: want %d, got %d":                "elementos: se esperaban %d, se obtuvieron %d",
+			"missing":                               "falta",
+			"size %d, expected %d":                  "tamaño %d, se esperaba %d",
+			"stale temp file":                       "archivo temporal obsoleto",
+			"unexpected df output":                  "salida inesperada de df",
+
+			"↩️ Server doesn't support ranges, falling back to a single connection.": "↩️ El servidor no admite rangos, se usa una sola conexión.",
+
+			"⏩ Resuming from %s: %s\n": "⏩ Reanudando desde %s: %s\n",
+
+			"⏭️ Already saved, skipping (-refresh to redo it).":                     "⏭️ Ya guardado, omitiendo (-refresh para rehacerlo).",
+			"⏭️ The %s stage is already done, skipping it (-refresh to redo it).\n": "⏭️ La etapa %s ya está hecha, omitiéndola (-refresh para rehacerla).\n",
+			"⏭️ skipping (filtered out): %s: %s\n":                                  "⏭️ omitiendo (filtrado): %s: %s\n",
+
+			"⏰ Inside -active-hours, resuming.": "⏰ Dentro de -active-hours, reanudando.",
+
+			"⏱️ %d/%d done, about %s left (until around %s)\n": "⏱️ %d/%d hechos, quedan unos %s (hasta las %s aprox.)\n",
+
+			"⏳ Session cookie expires %s (in %d days)\n": "⏳ La cookie de sesión caduca el %s (en %d días)\n",
+
+			"⏸️ Job %s paused.\n": "⏸️ Trabajo %s en pausa.\n",
+
+			"▶️ Job %s resumed.\n": "▶️ Trabajo %s reanudado.\n",
+
+			"☁️ Uploading %s\n": "☁️ Subiendo %s\n",
+
+			"⚠️ %s hook failed: %v": "⚠️ el hook %s falló: %v",
+			"⚠️ Couldn't sample any video to estimate the course's size, skipping the disk space check.":  "⚠️ No se pudo muestrear ningún vídeo para estimar el tamaño del curso, se omite la comprobación de espacio en disco.",
+			"⚠️ Couldn't tell the free disk space (%v), skipping the disk space check.\n":                 "⚠️ No se pudo saber el espacio libre en disco (%v), se omite la comprobación de espacio en disco.\n",
+			"⚠️ Files already saved may be outdated: run again with -resync-updated (or -refresh -sync).": "⚠️ Los archivos ya guardados pueden estar desactualizados: vuelva a ejecutar con -resync-updated (o -refresh -sync).",
+			"⚠️ No usable stored session, logging in again: %v":                                           "⚠️ No hay una sesión guardada utilizable, iniciando sesión de nuevo: %v",
+			"⚠️ None of the damaged files belong to an item: download the course again to repair them.":   "⚠️ Ninguno de los archivos dañados pertenece a un elemento: descargue el curso de nuevo para repararlos.",
+			"⚠️ Not in the fixtures (so failed): %s\n":                                                    "⚠️ No está en las grabaciones (así que falló): %s\n",
+			"⚠️ The selectors in %s are for version %d, not %d; they may be outdated.\n":                  "⚠️ Los selectores de %s son de la versión %d, no de la %d; pueden estar desactualizados.\n",
+			"⚠️ bad proxy settings in the environment: %v\n":                                              "⚠️ configuración de proxy no válida en el entorno: %v\n",
+			"⚠️ failed to cache the course structure: %v":                                                 "⚠️ no se pudo guardar en caché la estructura del curso: %v",
+			"⚠️ failed to claim job %s: %v":                                                               "⚠️ no se pudo tomar el trabajo %s: %v",
+			"⚠️ failed to dedupe %s: %v":                                                                  "⚠️ no se pudo deduplicar %s: %v",
+			"⚠️ failed to export traces: %v":                                                              "⚠️ no se pudieron exportar las trazas: %v",
+			"⚠️ failed to list jobs: %v":                                                                  "⚠️ no se pudieron listar los trabajos: %v",
+			"⚠️ failed to log %s: %v":                                                                     "⚠️ no se pudo registrar %s: %v",
+			"⚠️ failed to notify systemd: %v":                                                             "⚠️ no se pudo notificar a systemd: %v",
+			"⚠️ failed to open another tab: %v":                                                           "⚠️ no se pudo abrir otra pestaña: %v",
+			"⚠️ failed to punctuate the transcript, keeping it as is: %v":                                 "⚠️ no se pudo puntuar la transcripción, se deja como está: %v",
+			"⚠️ failed to read %s: %v\n":                                                                  "⚠️ no se pudo leer %s: %v\n",
+			"⚠️ failed to read captions: %v":                                                              "⚠️ no se pudieron leer los subtítulos: %v",
+			"⚠️ failed to reload config, keeping the previous one: %v":                                    "⚠️ no se pudo recargar la configuración, se mantiene la anterior: %v",
+			"⚠️ failed to save job %s: %v":                                                                "⚠️ no se pudo guardar el trabajo %s: %v",
+			"⚠️ failed to save the ETag of %s: %v":                                                        "⚠️ no se pudo guardar el ETag de %s: %v",
+			"⚠️ failed to save the download total: %v\n":                                                  "⚠️ no se pudo guardar el total descargado: %v\n",
+			"⚠️ failed to save the progress of %s: %v\n":                                                  "⚠️ no se pudo guardar el progreso de %s: %v\n",
+			"⚠️ failed to store the session in the OS keychain: %v":                                       "⚠️ no se pudo guardar la sesión en el llavero del sistema: %v",
+			"⚠️ failed to take a screenshot: %v":                                                          "⚠️ no se pudo hacer una captura de pantalla: %v",
+			"⚠️ failed to write memory profile: %v":                                                       "⚠️ no se pudo escribir el perfil de memoria: %v",
+			"⚠️ pprof server failed: %v":                                                                  "⚠️ el servidor pprof falló: %v",
+
+			"⛔ -max-bytes reached, not following %d more related course(s).\n":     "⛔ Se alcanzó -max-bytes, no se siguen %d curso(s) relacionado(s) más.\n",
+			"⛔ Stopped at -max-bytes %s: run again (or lld retry) for the rest.\n": "⛔ Detenido en -max-bytes %s: vuelva a ejecutar (o lld retry) para el resto.\n",
+
+			"✅ %s unchanged, skipping: %s\n":       "✅ %s sin cambios, omitiendo: %s\n",
+			"✅ All %d file(s) verified.\n":         "✅ Los %d archivo(s) verificados.\n",
+			"✅ All failed items saved.":            "✅ Todos los elementos fallidos se han guardado.",
+			"✅ Extracted %d item(s), %d failed\n":  "✅ %d elemento(s) extraído(s), %d fallido(s)\n",
+			"✅ Logged in as %s\n":                  "✅ Sesión iniciada como %s\n",
+			"✅ Replay matches the golden index.":   "✅ La reproducción coincide con el índice de referencia.",
+			"✅ Updated to %s.\n":                   "✅ Actualizado a %s.\n",
+			"✅ You're running the latest version.": "✅ Está usando la última versión.",
+
+			"❌ %d of %d file(s) failed verification":                                  "❌ %d de %d archivo(s) no superaron la verificación",
+			"-punctuate llm needs -llm-url and -llm-model":                            "-punctuate llm necesita -llm-url y -llm-model",
+			"❌ Parquet can't be written to stdout, -o must be a file.":                "❌ Parquet no se puede escribir en la salida estándar, -o debe ser un archivo.",
+			"❌ bad -start: %v":                                                        "❌ -start no válido: %v",
+			"❌ failed to create file %s: %v":                                          "❌ no se pudo crear el archivo %s: %v",
+			"❌ failed to listen on %s: %v":                                            "❌ no se pudo escuchar en %s: %v",
+			"❌ failed to remove the stored session: %v":                               "❌ no se pudo eliminar la sesión guardada: %v",
+			"❌ failed to write PID file: %v":                                          "❌ no se pudo escribir el archivo PID: %v",
+			"give either -course or -video, not both":                                 "indique -course o -video, no ambos",
+			"no course given: -course takes a course URL or slug":                     "no se indicó ningún curso: -course admite la URL o el slug de un curso",
+			"❌ no stored session (log in with -session first): %v":                    "❌ no hay ninguna sesión guardada (inicie sesión antes con -session): %v",
+			"❌ no table of contents in the fixtures: give the course with -course":    "❌ no hay índice en las grabaciones: indique el curso con -course",
+			"no videos found in the course's table of contents":                       "no se encontraron vídeos en el índice del curso",
+			"replay differs from the golden index:\n":                                 "la reproducción difiere del índice de referencia:\n",
+			"❌ server failed: %v":                                                     "❌ el servidor falló: %v",
+			"the LLM sent no reply":                                                   "el LLM no envió ninguna respuesta",
+			"the stored session is empty":                                             "la sesión guardada está vacía",
+			"❌ the stored session no longer works: %v":                                "❌ la sesión guardada ya no funciona: %v",
+			"❌ -archive-stdout takes a single course, but %d are to be downloaded":    "❌ -archive-stdout admite un solo curso, pero hay %d por descargar",
+			"-encrypt can't be used with -dedupe, whose pool keeps files unencrypted": "-encrypt no se puede usar con -dedupe, cuyo depósito guarda los archivos sin cifrar",
+			"❌ unsupported -format %q, expected csv, parquet, or jsonl":               "❌ -format %q no admitido, se esperaba csv, parquet o jsonl",
+			"❌ unsupported -format %q, expected json or cookies.txt":                  "❌ -format %q no admitido, se esperaba json o cookies.txt",
+			"❌ usage: lld auth export|status|logout":                                  "❌ uso: lld auth export|status|logout",
+
+			"⬆️ A newer version is available: %s (%s). Run `lld self-update` to install it.\n": "⬆️ Hay una versión más reciente: %s (%s). Ejecute `lld self-update` para instalarla.\n",
+
+			"⬇️ Updating to %s...\n": "⬇️ Actualizando a %s...\n",
+
+			"🆕 The course was updated since it was downloaded: %s -> %s\n": "🆕 El curso se ha actualizado desde que se descargó: %s -> %s\n",
+
+			"🌐 Translated into %s: %s\n": "🌐 Traducido al %s: %s\n",
+			"🌐 Using proxy %s\n":         "🌐 Usando el proxy %s\n",
+
+			"🎞️ Subtitles embedded: %s\n": "🎞️ Subtítulos incrustados: %s\n",
+
+			"🎫 License: %s\n": "🎫 Licencia: %s\n",
+
+			"🎯 %d of %d item(s) left after filtering\n": "🎯 Quedan %d de %d elemento(s) tras filtrar\n",
+			"🎯 Found %s in section %s\n":                "🎯 Se encontró %s en la sección %s\n",
+
+			"🏁 Job %s %s\n": "🏁 Trabajo %s: %s\n",
+
+			"👋 Server stopped.":         "👋 Servidor detenido.",
+			"👋 Stored session removed.": "👋 Sesión guardada eliminada.",
+
+			"💾 %d damaged item(s) queued in %s, repair them with: lld retry %s\n": "💾 %d elemento(s) dañado(s) en cola en %s, repárelos con: lld retry %s\n",
+			"💾 %d failed item(s) listed in %s, retry them with: lld retry %s\n":   "💾 %d elemento(s) fallido(s) listado(s) en %s, reinténtelos con: lld retry %s\n",
+			"💾 %d transcript line(s) of %d course(s) saved: %s\n":                 "💾 %d línea(s) de transcripción de %d curso(s) guardada(s): %s\n",
+			"💾 Archive saved: %s\n":                                               "💾 Archivo comprimido guardado: %s\n",
+			"💾 TOC saved: %s\n":                                                   "💾 Índice guardado: %s\n",
+			"💾 captions saved: %s\n":                                              "💾 subtítulos guardados: %s\n",
+			"💾 checksums saved: %s\n":                                             "💾 sumas de comprobación guardadas: %s\n",
+			"💾 flashcards saved: %s\n":                                            "💾 tarjetas de estudio guardadas: %s\n",
+			"💾 index saved: %s\n":                                                 "💾 índice guardado: %s\n",
+			"💾 player saved: %s\n":                                                "💾 reproductor guardado: %s\n",
+			"💾 quiz saved: %s\n":                                                  "💾 cuestionario guardado: %s\n",
+			"💾 screen text saved: %s\n":                                           "💾 texto en pantalla guardado: %s\n",
+			"💾 study calendar saved: %s\n":                                        "💾 calendario de estudio guardado: %s\n",
+			"💾 study plan saved: %s\n":                                            "💾 plan de estudio guardado: %s\n",
+			"💾 summary saved: %s\n":                                               "💾 resumen guardado: %s\n",
+
+			"📅 %d day(s) of study, from %s to %s\n": "📅 %d día(s) de estudio, del %s al %s\n",
+
+			"📏 Sampling %s to estimate the course's size\n": "📏 Muestreando %s para estimar el tamaño del curso\n",
+			"📏 The videos need about %s, and %s is free.\n": "📏 Los vídeos necesitan unos %s y hay %s libres.\n",
+
+			"📖 %d words across %d transcript(s), about %d min of reading\n": "📖 %d palabras en %d transcripción(es), unos %d min de lectura\n",
+
+			"📝 Wrote %s\n": "📝 Escrito %s\n",
+
+			"📦 Starting job %s: %s\n": "📦 Iniciando el trabajo %s: %s\n",
+
+			"📶 Downloaded %s\n":                     "📶 Descargado %s\n",
+			"📶 Downloaded %s, %s in all since %s\n": "📶 Descargado %s, %s en total desde el %s\n",
+
+			"📸 screenshot saved: %s\n": "📸 captura de pantalla guardada: %s\n",
+
+			"📼 Replaying %s\n": "📼 Reproduciendo %s\n",
+
+			"🔁 Retrying %d failed item(s) of %s\n": "🔁 Reintentando %d elemento(s) fallido(s) de %s\n",
+
+			"🔄 Config reloaded.":               "🔄 Configuración recargada.",
+			"🔄 Re-syncing the updated course.": "🔄 Resincronizando el curso actualizado.",
+
+			"🔌 Exporting with %s\n": "🔌 Exportando con %s\n",
+			"🔌 No plugins in %s\n":  "🔌 No hay plugins en %s\n",
+
+			"🔐 Encrypted: %s\n":                    "🔐 Cifrado: %s\n",
+			"🔐 Session stored in the OS keychain.": "🔐 Sesión guardada en el llavero del sistema.",
+
+			"🔑 Logging in as %s.\n":                  "🔑 Iniciando sesión como %s.\n",
+			"🔑 Restored the stored session.":         "🔑 Sesión guardada restaurada.",
+			"🔑 URL expired (%s), re-extracting...\n": "🔑 La URL caducó (%s), extrayéndola de nuevo...\n",
+
+			"🔗 Already in the pool, linking: %s\n": "🔗 Ya está en el almacén común, enlazando: %s\n",
+			"🔗 Exported to %s\n":                   "🔗 Exportado a %s\n",
+
+			"🔥 Subtitles burned in: %s\n": "🔥 Subtítulos incrustados en la imagen: %s\n",
+
+			"🗃️ Using the course structure cached %s ago (-refresh to re-parse).\n": "🗃️ Usando la estructura del curso guardada en caché hace %s (-refresh para volver a analizarla).\n",
+
+			"🗜️ Archiving %s\n": "🗜️ Archivando %s\n",
+
+			"😴 Outside -active-hours, pausing until %s.\n": "😴 Fuera de -active-hours, en pausa hasta las %s.\n",
+
+			"🚧 Throttled (%s), retrying in %v...\n": "🚧 Limitado (%s), reintentando en %v...\n",
+			"still rate limited, giving up":         "el límite de peticiones persiste, abandonando",
+
+			"🛰️ Listening on %s\n": "🛰️ Escuchando en %s\n",
+
+			"🧹 %s %d file(s) or directories, and %d index entries.\n": "🧹 %s %d archivo(s) o directorio(s), y %d entrada(s) del índice.\n",
+			"🧹 %s: index entry of a deleted file\n":                   "🧹 %s: entrada del índice de un archivo eliminado\n",
+			"🧹 %s: left over from an interrupted run\n":               "🧹 %s: restos de una ejecución interrumpida\n",
+
+			"🩹 The %s selector %q no longer matches, using a fallback (%s).\n": "🩹 El selector %s %q ya no coincide, usando una alternativa (%s).\n",
+
+			"🩺 Serving pprof on http://%s/debug/pprof/\n": "🩺 Sirviendo pprof en http://%s/debug/pprof/\n",
+		},
+		"de": {
+			"video":            "Video",
+			"audio":            "Audio",
+			"document":         "Dokument",
+			"transcript":       "Transkript",
+			"media":            "Medien",
+			"quiz":             "Quiz",
+			"postprocess":      "Nachbearbeitung",
+			"done":             "erledigt",
+			"failed":           "fehlgeschlagen",
+			"canceled":         "abgebrochen",
+			"individual":       "Einzelperson",
+			"enterprise (SSO)": "Unternehmen (SSO)",
+
+			"❌ You must specify at least one of -transcripts or -videos to download.": "❌ Mindestens -transcripts oder -videos muss angegeben werden.",
+			"You must specify at least one of -transcripts or -videos to download.":   "Mindestens -transcripts oder -videos muss angegeben werden.",
+			"🚀 Logging in via SSO...":                           "🚀 Anmeldung über SSO...",
+			"✅ Logged in.":                                      "✅ Angemeldet.",
+			"✅ All courses info saved.":                         "✅ Alle Kursinformationen gespeichert.",
+			"📖 Parsing course details.":                         "📖 Kursdetails werden ausgelesen.",
+			"📚 Parsing course structure.":                       "📚 Kursstruktur wird ausgelesen.",
+			"👤 Parsing author page.":                            "👤 Autorenseite wird ausgelesen.",
+			"❌ Failed to parse author: %v":                      "❌ Autor konnte nicht ausgelesen werden: %v",
+			"👤 %s has %d course(s)\n":                           "👤 %s hat %d Kurs(e)\n",
+			"💾 author index saved: %s\n":                        "💾 Autorenindex gespeichert: %s\n",
+			"⚠️ Failed to parse course details: %v":             "⚠️ Kursdetails konnten nicht ausgelesen werden: %v",
+			"🎯 Found %d video(s) across %d sections\n":          "🎯 %d Video(s) in %d Abschnitten gefunden\n",
+			"🔗 Found %d related course(s)\n":                    "🔗 %d verwandte(n) Kurs(e) gefunden\n",
+			"🔗 Following related course: %s\n":                  "🔗 Verwandter Kurs wird verfolgt: %s\n",
+			"💾 course saved: %s\n":                              "💾 Kurs gespeichert: %s\n",
+			"💾 README saved: %s\n":                              "💾 README gespeichert: %s\n",
+			"💾 transcript saved: %s\n":                          "💾 Transkript gespeichert: %s\n",
+			"💾 %s saved: %s\n":                                  "💾 %s gespeichert: %s\n",
+			"%v -> skipping.":                                   "%v -> wird übersprungen.",
+			"🔒 protected content, transcript-only: %s":          "🔒 geschützter Inhalt, nur Transkript: %s",
+			"👆 No video source yet, clicking play...":           "👆 Noch keine Videoquelle, Wiedergabe wird gestartet...",
+			"🚧 Rate limited. Sleeping a minute and retrying...": "🚧 Anfragelimit erreicht. Eine Minute warten und erneut versuchen...",
+			"❌ navigation failed (%v), retrying\n":              "❌ Navigation fehlgeschlagen (%v), neuer Versuch\n",
+			"empty video URL found":                             "leere Video-URL gefunden",
+			"empty audio URL found":                             "leere Audio-URL gefunden",
+			"empty document URL found":                          "leere Dokument-URL gefunden",
+
+			" or ":                                  " oder ",
+			"Removed":                               "Entfernt",
+			"Would remove":                          "Würde entfernen",
+			"a learning path":                       "ein Lernpfad",
+			"a listing page":                        "eine Übersichtsseite",
+			"an instructor's page (see lld author)": "die Seite eines Trainers (siehe lld author)",
+			"checksum mismatch":                     "Prüfsumme stimmt nicht überein",
+			"course: want %q (%s), got %q (%s)":     "Kurs: erwartet %q (%s), erhalten %q (%s)",
+			"dangling link":                         "verwaister Link",
+			"empty directory":                       "leeres Verzeichnis",
+			"empty file":                            "leere Datei",
+			"item %d (%s):\n  want %s\n  got  %s":   "Element %d (%s):\n  erwartet %s\n  erhalten %s",
+			"items: want %d, got %d":                "Elemente: erwartet %d, erhalten %d",
+			"missing":                               "fehlt",
+			"size %d, expected %d":                  "Größe %d, erwartet %d",
+			"stale temp file":                       "veraltete temporäre Datei",
+			"unexpected df output":                  "unerwartete Ausgabe von df",
+
+			"↩️ Server doesn't support ranges, falling back to a single connection.": "↩️ Der Server unterstützt keine Bereiche, es wird eine einzelne Verbindung verwendet.",
+
+			"⏩ Resuming from %s: %s\n": "⏩ Fortsetzung ab %s: %s\n",
+
+			"⏭️ Already saved, skipping (-refresh to redo it).":                     "⏭️ Bereits gespeichert, wird übersprungen (-refresh zum Wiederholen).",
+			"⏭️ The %s stage is already done, skipping it (-refresh to redo it).\n": "⏭️ Der Schritt %s ist bereits erledigt und wird übersprungen (-refresh zum Wiederholen).\n",
+			"⏭️ skipping (filtered out): %s: %s\n":                                  "⏭️ wird übersprungen (herausgefiltert): %s: %s\n",
+
+			"⏰ Inside -active-hours, resuming.": "⏰ Innerhalb von -active-hours, es geht weiter.",
+
+			"⏱️ %d/%d done, about %s left (until around %s)\n": "⏱️ %d/%d erledigt, noch etwa %s (bis etwa %s)\n",
+
+			"⏳ Session cookie expires %s (in %d days)\n": "⏳ Das Sitzungscookie läuft am %s ab (in %d Tagen)\n",
+
+			"⏸️ Job %s paused.\n": "⏸️ Auftrag %s angehalten.\n",
+
+			"▶️ Job %s resumed.\n": "▶️ Auftrag %s fortgesetzt.\n",
+
+			"☁️ Uploading %s\n": "☁️ %s wird hochgeladen\n",
+
+			"⚠️ %s hook failed: %v": "⚠️ Hook %s fehlgeschlagen: %v",
+			"⚠️ Couldn't sample any video to estimate the course's size, skipping the disk space check.":  "⚠️ Kein Video konnte zur Schätzung der Kursgröße geprüft werden, die Speicherplatzprüfung wird übersprungen.",
+			"⚠️ Couldn't tell the free disk space (%v), skipping the disk space check.\n":                 "⚠️ Freier Speicherplatz unbekannt (%v), die Speicherplatzprüfung wird übersprungen.\n",
+			"⚠️ Files already saved may be outdated: run again with -resync-updated (or -refresh -sync).": "⚠️ Bereits gespeicherte Dateien sind möglicherweise veraltet: erneut mit -resync-updated (oder -refresh -sync) ausführen.",
+			"⚠️ No usable stored session, logging in again: %v":                                           "⚠️ Keine verwendbare gespeicherte Sitzung, erneute Anmeldung: %v",
+			"⚠️ None of the damaged files belong to an item: download the course again to repair them.":   "⚠️ Keine der beschädigten Dateien gehört zu einem Element: den Kurs zur Reparatur erneut herunterladen.",
+			"⚠️ Not in the fixtures (so failed): %s\n":                                                    "⚠️ Nicht in den Aufzeichnungen (daher fehlgeschlagen): %s\n",
+			"⚠️ The selectors in %s are for version %d, not %d; they may be outdated.\n":                  "⚠️ Die Selektoren in %s sind für Version %d, nicht %d; sie sind möglicherweise veraltet.\n",
+			"⚠️ bad proxy settings in the environment: %v\n":                                              "⚠️ ungültige Proxy-Einstellungen in der Umgebung: %v\n",
+			"⚠️ failed to cache the course structure: %v":                                                 "⚠️ Kursstruktur konnte nicht zwischengespeichert werden: %v",
+			"⚠️ failed to claim job %s: %v":                                                               "⚠️ Auftrag %s konnte nicht übernommen werden: %v",
+			"⚠️ failed to dedupe %s: %v":                                                                  "⚠️ %s konnte nicht dedupliziert werden: %v",
+			"⚠️ failed to export traces: %v":                                                              "⚠️ Traces konnten nicht exportiert werden: %v",
+			"⚠️ failed to list jobs: %v":                                                                  "⚠️ Aufträge konnten nicht aufgelistet werden: %v",
+			"⚠️ failed to log %s: %v":                                                                     "⚠️ %s konnte nicht protokolliert werden: %v",
+			"⚠️ failed to notify systemd: %v":                                                             "⚠️ systemd konnte nicht benachrichtigt werden: %v",
+			"⚠️ failed to open another tab: %v":                                                           "⚠️ Weiterer Tab konnte nicht geöffnet werden: %v",
+			"⚠️ failed to punctuate the transcript, keeping it as is: %v":                                 "⚠️ Transkript konnte nicht interpunktiert werden, es bleibt unverändert: %v",
+			"⚠️ failed to read %s: %v\n":                                                                  "⚠️ %s konnte nicht gelesen werden: %v\n",
+			"⚠️ failed to read captions: %v":                                                              "⚠️ Untertitel konnten nicht gelesen werden: %v",
+			"⚠️ failed to reload config, keeping the previous one: %v":                                    "⚠️ Konfiguration konnte nicht neu geladen werden, die vorherige bleibt: %v",
+			"⚠️ failed to save job %s: %v":                                                                "⚠️ Auftrag %s konnte nicht gespeichert werden: %v",
+			"⚠️ failed to save the ETag of %s: %v":                                                        "⚠️ ETag von %s konnte nicht gespeichert werden: %v",
+			"⚠️ failed to save the download total: %v\n":                                                  "⚠️ Download-Summe konnte nicht gespeichert werden: %v\n",
+			"⚠️ failed to save the progress of %s: %v\n":                                                  "⚠️ Fortschritt von %s konnte nicht gespeichert werden: %v\n",
+			"⚠️ failed to store the session in the OS keychain: %v":                                       "⚠️ Sitzung konnte nicht im Schlüsselbund des Systems gespeichert werden: %v",
+			"⚠️ failed to take a screenshot: %v":                                                          "⚠️ Bildschirmfoto konnte nicht erstellt werden: %v",
+			"⚠️ failed to write memory profile: %v":                                                       "⚠️ Speicherprofil konnte nicht geschrieben werden: %v",
+			"⚠️ pprof server failed: %v":                                                                  "⚠️ pprof-Server fehlgeschlagen: %v",
+
+			"⛔ -max-bytes reached, not following %d more related course(s).\n":     "⛔ -max-bytes erreicht, %d weitere(r) verwandte(r) Kurs(e) wird/werden nicht verfolgt.\n",
+			"⛔ Stopped at -max-bytes %s: run again (or lld retry) for the rest.\n": "⛔ Bei -max-bytes %s angehalten: für den Rest erneut ausführen (oder lld retry).\n",
+
+			"✅ %s unchanged, skipping: %s\n":       "✅ %s unverändert, wird übersprungen: %s\n",
+			"✅ All %d file(s) verified.\n":         "✅ Alle %d Datei(en) geprüft.\n",
+			"✅ All failed items saved.":            "✅ Alle fehlgeschlagenen Elemente gespeichert.",
+			"✅ Extracted %d item(s), %d failed\n":  "✅ %d Element(e) ausgelesen, %d fehlgeschlagen\n",
+			"✅ Logged in as %s\n":                  "✅ Angemeldet als %s\n",
+			"✅ Replay matches the golden index.":   "✅ Die Wiedergabe stimmt mit dem Referenzindex überein.",
+			"✅ Updated to %s.\n":                   "✅ Auf %s aktualisiert.\n",
+			"✅ You're running the latest version.": "✅ Sie verwenden die neueste Version.",
+
+			"❌ %d of %d file(s) failed verification":                                  "❌ %d von %d Datei(en) haben die Prüfung nicht bestanden",
+			"-punctuate llm needs -llm-url and -llm-model":                            "-punctuate llm benötigt -llm-url und -llm-model",
+			"❌ Parquet can't be written to stdout, -o must be a file.":                "❌ Parquet kann nicht auf die Standardausgabe geschrieben werden, -o muss eine Datei sein.",
+			"❌ bad -start: %v":                                                        "❌ ungültiges -start: %v",
+			"❌ failed to create file %s: %v":                                          "❌ Datei %s konnte nicht erstellt werden: %v",
+			"❌ failed to listen on %s: %v":                                            "❌ Lauschen auf %s fehlgeschlagen: %v",
+			"❌ failed to remove the stored session: %v":                               "❌ Gespeicherte Sitzung konnte nicht entfernt werden: %v",
+			"❌ failed to write PID file: %v":                                          "❌ PID-Datei konnte nicht geschrieben werden: %v",
+			"give either -course or -video, not both":                                 "entweder -course oder -video angeben, nicht beides",
+			"no course given: -course takes a course URL or slug":                     "kein Kurs angegeben: -course erwartet eine Kurs-URL oder einen Kurs-Slug",
+			"❌ no stored session (log in with -session first): %v":                    "❌ keine gespeicherte Sitzung (zuerst mit -session anmelden): %v",
+			"❌ no table of contents in the fixtures: give the course with -course":    "❌ kein Inhaltsverzeichnis in den Aufzeichnungen: den Kurs mit -course angeben",
+			"no videos found in the course's table of contents":                       "keine Videos im Inhaltsverzeichnis des Kurses gefunden",
+			"replay differs from the golden index:\n":                                 "die Wiedergabe weicht vom Referenzindex ab:\n",
+			"❌ server failed: %v":                                                     "❌ Server fehlgeschlagen: %v",
+			"the LLM sent no reply":                                                   "das LLM hat nicht geantwortet",
+			"the stored session is empty":                                             "die gespeicherte Sitzung ist leer",
+			"❌ the stored session no longer works: %v":                                "❌ die gespeicherte Sitzung funktioniert nicht mehr: %v",
+			"❌ -archive-stdout takes a single course, but %d are to be downloaded":    "❌ -archive-stdout nimmt nur einen Kurs, aber %d sind herunterzuladen",
+			"-encrypt can't be used with -dedupe, whose pool keeps files unencrypted": "-encrypt kann nicht mit -dedupe verwendet werden, dessen Pool die Dateien unverschlüsselt behält",
+			"❌ unsupported -format %q, expected csv, parquet, or jsonl":               "❌ nicht unterstütztes -format %q, erwartet csv, parquet oder jsonl",
+			"❌ unsupported -format %q, expected json or cookies.txt":                  "❌ nicht unterstütztes -format %q, erwartet json oder cookies.txt",
+			"❌ usage: lld auth export|status|logout":                                  "❌ Verwendung: lld auth export|status|logout",
+
+			"⬆️ A newer version is available: %s (%s). Run `lld self-update` to install it.\n": "⬆️ Eine neuere Version ist verfügbar: %s (%s). Mit `lld self-update` installieren.\n",
+
+			"⬇️ Updating to %s...\n": "⬇️ Aktualisierung auf %s...\n",
+
+			"🆕 The course was updated since it was downloaded: %s -> %s\n": "🆕 Der Kurs wurde seit dem Herunterladen aktualisiert: %s -> %s\n",
+
+			"🌐 Translated into %s: %s\n": "🌐 Übersetzt nach %s: %s\n",
+			"🌐 Using proxy %s\n":         "🌐 Proxy %s wird verwendet\n",
+
+			"🎞️ Subtitles embedded: %s\n": "🎞️ Untertitel eingebettet: %s\n",
+
+			"🎫 License: %s\n": "🎫 Lizenz: %s\n",
+
+			"🎯 %d of %d item(s) left after filtering\n": "🎯 %d von %d Element(en) nach dem Filtern übrig\n",
+			"🎯 Found %s in section %s\n":                "🎯 %s in Abschnitt %s gefunden\n",
+
+			"🏁 Job %s %s\n": "🏁 Auftrag %s: %s\n",
+
+			"👋 Server stopped.":         "👋 Server beendet.",
+			"👋 Stored session removed.": "👋 Gespeicherte Sitzung entfernt.",
+
+			"💾 %d damaged item(s) queued in %s, repair them with: lld retry %s\n": "💾 %d beschädigte(s) Element(e) in %s vorgemerkt, reparieren mit: lld retry %s\n",
+			"💾 %d failed item(s) listed in %s, retry them with: lld retry %s\n":   "💾 %d fehlgeschlagene(s) Element(e) in %s aufgelistet, erneut versuchen mit: lld retry %s\n",
+			"💾 %d transcript line(s) of %d course(s) saved: %s\n":                 "💾 %d Transkriptzeile(n) aus %d Kurs(en) gespeichert: %s\n",
+			"💾 Archive saved: %s\n":                                               "💾 Archiv gespeichert: %s\n",
+			"💾 TOC saved: %s\n":                                                   "💾 Inhaltsverzeichnis gespeichert: %s\n",
+			"💾 captions saved: %s\n":                                              "💾 Untertitel gespeichert: %s\n",
+			"💾 checksums saved: %s\n":                                             "💾 Prüfsummen gespeichert: %s\n",
+			"💾 flashcards saved: %s\n":                                            "💾 Lernkarten gespeichert: %s\n",
+			"💾 index saved: %s\n":                                                 "💾 Index gespeichert: %s\n",
+			"💾 player saved: %s\n":                                                "💾 Player gespeichert: %s\n",
+			"💾 quiz saved: %s\n":                                                  "💾 Quiz gespeichert: %s\n",
+			"💾 screen text saved: %s\n":                                           "💾 Bildschirmtext gespeichert: %s\n",
+			"💾 study calendar saved: %s\n":                                        "💾 Lernkalender gespeichert: %s\n",
+			"💾 study plan saved: %s\n":                                            "💾 Lernplan gespeichert: %s\n",
+			"💾 summary saved: %s\n":                                               "💾 Zusammenfassung gespeichert: %s\n",
+
+			"📅 %d day(s) of study, from %s to %s\n": "📅 %d Lerntag(e), vom %s bis %s\n",
+
+			"📏 Sampling %s to estimate the course's size\n": "📏 %s wird geprüft, um die Kursgröße zu schätzen\n",
+			"📏 The videos need about %s, and %s is free.\n": "📏 Die Videos brauchen etwa %s, und %s sind frei.\n",
+
+			"📖 %d words across %d transcript(s), about %d min of reading\n": "📖 %d Wörter in %d Transkript(en), etwa %d Min. Lesezeit\n",
+
+			"📝 Wrote %s\n": "📝 %s geschrieben\n",
+
+			"📦 Starting job %s: %s\n": "📦 Auftrag %s wird gestartet: %s\n",
+
+			"📶 Downloaded %s\n":                     "📶 %s heruntergeladen\n",
+			"📶 Downloaded %s, %s in all since %s\n": "📶 %s heruntergeladen, insgesamt %s seit %s\n",
+
+			"📸 screenshot saved: %s\n": "📸 Bildschirmfoto gespeichert: %s\n",
+
+			"📼 Replaying %s\n": "📼 %s wird wiedergegeben\n",
+
+			"🔁 Retrying %d failed item(s) of %s\n": "🔁 %d fehlgeschlagene(s) Element(e) von %s wird/werden erneut versucht\n",
+
+			"🔄 Config reloaded.":               "🔄 Konfiguration neu geladen.",
+			"🔄 Re-syncing the updated course.": "🔄 Der aktualisierte Kurs wird neu synchronisiert.",
+
+			"🔌 Exporting with %s\n": "🔌 Export mit %s\n",
+			"🔌 No plugins in %s\n":  "🔌 Keine Plugins in %s\n",
+
+			"🔐 Encrypted: %s\n":                    "🔐 Verschlüsselt: %s\n",
+			"🔐 Session stored in the OS keychain.": "🔐 Sitzung im Schlüsselbund des Systems gespeichert.",
+
+			"🔑 Logging in as %s.\n":                  "🔑 Anmeldung als %s.\n",
+			"🔑 Restored the stored session.":         "🔑 Gespeicherte Sitzung wiederhergestellt.",
+			"🔑 URL expired (%s), re-extracting...\n": "🔑 URL abgelaufen (%s), wird neu ermittelt...\n",
+
+			"🔗 Already in the pool, linking: %s\n": "🔗 Bereits im Pool, wird verlinkt: %s\n",
+			"🔗 Exported to %s\n":                   "🔗 Nach %s exportiert\n",
+
+			"🔥 Subtitles burned in: %s\n": "🔥 Untertitel eingebrannt: %s\n",
+
+			"🗃️ Using the course structure cached %s ago (-refresh to re-parse).\n": "🗃️ Die vor %s zwischengespeicherte Kursstruktur wird verwendet (-refresh zum erneuten Auslesen).\n",
+
+			"🗜️ Archiving %s\n": "🗜️ %s wird archiviert\n",
+
+			"😴 Outside -active-hours, pausing until %s.\n": "😴 Außerhalb von -active-hours, Pause bis %s.\n",
+
+			"🚧 Throttled (%s), retrying in %v...\n": "🚧 Gedrosselt (%s), neuer Versuch in %v...\n",
+			"still rate limited, giving up":         "Anfragelimit besteht weiterhin, Abbruch",
+
+			"🛰️ Listening on %s\n": "🛰️ Lauscht auf %s\n",
+
+			"🧹 %s %d file(s) or directories, and %d index entries.\n": "🧹 %s: %d Datei(en) oder Verzeichnisse und %d Indexeinträge.\n",
+			"🧹 %s: index entry of a deleted file\n":                   "🧹 %s: Indexeintrag einer gelöschten Datei\n",
+			"🧹 %s: left over from an interrupted run\n":               "🧹 %s: Überbleibsel eines abgebrochenen Laufs\n",
+
+			"🩹 The %s selector %q no longer matches, using a fallback (%s).\n": "🩹 Der Selektor %s %q passt nicht mehr, es wird ein Ersatz verwendet (%s).\n",
+
+			"🩺 Serving pprof on http://%s/debug/pprof/\n": "🩺 pprof wird unter http://%s/debug/pprof/ bereitgestellt\n",
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package lld_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/jh125486/lld"
+)
+
+// formattedMessages are translated with tr where they're formatted into messages, rather than as literals: item kinds,
+// checkpoint stages, job statuses, and license types.
+var formattedMessages = []string{ //nolint:gochecknoglobals // Test table.
+	"video", "audio", "document", "transcript", "media", "quiz", "postprocess",
+	"done", "failed", "canceled", "individual", "enterprise (SSO)",
+}
+
+var verbRE = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// trMessages returns the messages passed to tr as constant strings in the package's sources.
+func trMessages(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return true
+			}
+			if fn, ok := call.Fun.(*ast.Ident); !ok || fn.Name != "tr" {
+				return true
+			}
+			if msg, ok := constantString(call.Args[0]); ok {
+				msgs = append(msgs, msg)
+			}
+			return true
+		})
+	}
+
+	return msgs
+}
+
+// constantString is the value of e if it's a string literal, or literals joined with +.
+func constantString(e ast.Expr) (string, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		x, ok := constantString(e.X)
+		if !ok || e.Op != token.ADD {
+			return "", false
+		}
+		y, ok := constantString(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return constantString(e.X)
+	}
+
+	return "", false
+}
+
+// hasWords is whether msg has anything to translate besides its formatting verbs.
+func hasWords(msg string) bool {
+	return strings.ContainsFunc(verbRE.ReplaceAllString(msg, ""), unicode.IsLetter)
+}
+
+func TestCatalogs(t *testing.T) {
+	t.Parallel()
+	msgs := append(trMessages(t), formattedMessages...)
+	if len(msgs) < len(formattedMessages)+100 {
+		t.Fatalf("found only %d messages passed to tr", len(msgs)-len(formattedMessages))
+	}
+
+	for lang, catalog := range lld.Catalogs() {
+		t.Run(lang, func(t *testing.T) {
+			t.Parallel()
+			for _, msg := range msgs {
+				translated, ok := catalog[msg]
+				switch {
+				case !ok && hasWords(msg):
+					t.Errorf("%q isn't translated", msg)
+				case !ok:
+				case strings.Join(verbRE.FindAllString(translated, -1), "") != strings.Join(verbRE.FindAllString(msg, -1), ""):
+					t.Errorf("%q is translated as %q, with different formatting verbs", msg, translated)
+				case strings.HasSuffix(translated, "\n") != strings.HasSuffix(msg, "\n"):
+					t.Errorf("%q is translated as %q, with a different line ending", msg, translated)
+				}
+			}
+		})
+	}
+}
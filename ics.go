@@ -0,0 +1,98 @@
+package lld
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// writePlanICS writes the days as an iCalendar file, with an event per day's study block starting at the time of
+// day at (e.g. 18:30), describing its items with links to their files in dir.
+func writePlanICS(index *Index, days []StudyDay, dir, at, out string) error {
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return fmt.Errorf("bad -at: %w", err)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", out, err)
+	}
+
+	var sb strings.Builder
+	line := func(s string) { sb.WriteString(foldICS(s) + "\r\n") }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//lld//study plan//EN")
+	line("CALSCALE:GREGORIAN")
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for i, d := range days {
+		start := time.Date(d.Date.Year(), d.Date.Month(), d.Date.Day(), clock.Hour(), clock.Minute(), 0, 0, d.Date.Location())
+		sum := sha256.Sum256([]byte(index.Course + start.Format(time.DateOnly)))
+		var desc strings.Builder
+		for _, item := range d.Items {
+			fmt.Fprintf(&desc, "%s: %s (%s)\n", item.Section, item.Title, studyTime(itemLength(item.VideoEntry)))
+			if f, ok := mainFile(item); ok {
+				desc.WriteString(fileURL(filepath.Join(abs, filepath.FromSlash(f.Path))) + "\n")
+			}
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + hex.EncodeToString(sum[:8]) + "@lld")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + start.UTC().Format("20060102T150405Z"))
+		line("DTEND:" + start.Add(max(d.Length, time.Minute)).UTC().Format("20060102T150405Z"))
+		line("SUMMARY:" + escapeICS(fmt.Sprintf("Study: %s (%d/%d)", index.Title, i+1, len(days))))
+		line("DESCRIPTION:" + escapeICS(strings.TrimSpace(desc.String())))
+		if len(d.Items) > 0 {
+			if f, ok := mainFile(d.Items[0]); ok {
+				line("URL:" + fileURL(filepath.Join(abs, filepath.FromSlash(f.Path))))
+			}
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	if err := os.WriteFile(out, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", out, err)
+	}
+	log.Printf(tr("💾 study calendar saved: %s\n"), out)
+
+	return nil
+}
+
+// fileURL is the file:// URL of the absolute path file.
+func fileURL(file string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(file)}
+	if !strings.HasPrefix(u.Path, "/") { // A Windows drive.
+		u.Path = "/" + u.Path
+	}
+
+	return u.String()
+}
+
+// escapeICS escapes text for an iCalendar TEXT value.
+func escapeICS(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldICS folds a content line into lines of at most 75 octets, as iCalendar requires, without splitting characters.
+func foldICS(s string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > 75 {
+			sb.WriteString("\r\n ")
+			n = 1
+		}
+		sb.WriteRune(r)
+		n += size
+	}
+
+	return sb.String()
+}
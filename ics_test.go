@@ -0,0 +1,64 @@
+package lld_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+func TestEscapeICS(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "Go Essential Training", want: "Go Essential Training"},
+		{s: "Maps, slices; and arrays", want: `Maps\, slices\; and arrays`},
+		{s: `C:\Courses`, want: `C:\\Courses`},
+		{s: "1. Intro\n2. Setup", want: `1. Intro\n2. Setup`},
+		{s: `\,`, want: `\\\,`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			t.Parallel()
+			if got := lld.EscapeICS(tt.s); got != tt.want {
+				t.Errorf("escapeICS(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldICS(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "short", s: "SUMMARY:Intro", want: "SUMMARY:Intro"},
+		{name: "75 octets", s: strings.Repeat("a", 75), want: strings.Repeat("a", 75)},
+		{name: "76 octets", s: strings.Repeat("a", 76), want: strings.Repeat("a", 75) + "\r\n a"},
+		{
+			name: "several lines",
+			s:    strings.Repeat("a", 160),
+			want: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n " + strings.Repeat("a", 11),
+		},
+		{name: "multibyte character", s: strings.Repeat("a", 74) + "é", want: strings.Repeat("a", 74) + "\r\n é"},
+		{name: "multibyte character that fits", s: strings.Repeat("a", 73) + "é", want: strings.Repeat("a", 73) + "é"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := lld.FoldICS(tt.s)
+			if got != tt.want {
+				t.Errorf("foldICS(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+			for line := range strings.SplitSeq(got, "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("foldICS(%q) has a line of %d octets", tt.s, len(line))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Index is index.json: every file produced for a course, so tools needn't reverse engineer the file names.
+type Index struct {
+	Course    string      `json:"course"`
+	Title     string      `json:"title"`
+	Generated time.Time   `json:"generated"`
+	Files     []IndexFile `json:"files"` // Course level files, like course.json.
+	Items     []IndexItem `json:"items"`
+}
+
+// IndexItem is a TOC item with the files saved for it.
+type IndexItem struct {
+	VideoEntry
+	Files     []IndexFile  `json:"files"`
+	Screen    []ScreenText `json:"screen,omitempty"` // From -ocr.
+	Error     string       `json:"error,omitempty"`
+	ErrorCode string       `json:"errorCode,omitempty"` // See errorKinds.
+}
+
+// IndexFile is a single saved file; Path is relative to the course directory, with forward slashes.
+type IndexFile struct {
+	Path      string    `json:"path"`
+	Kind      string    `json:"kind"`
+	Encrypted bool      `json:"encrypted,omitempty"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	Modified  time.Time `json:"modified"`
+}
+
+// fileKind classifies a saved file by its extension.
+func fileKind(name string) string {
+	name = strings.TrimSuffix(name, ageExt)
+	if strings.HasSuffix(name, ".quiz.json") || strings.HasSuffix(name, ".flashcards.md") {
+		return itemQuiz
+	}
+	if strings.HasSuffix(name, ".ocr.json") {
+		return "screen-text"
+	}
+	if strings.HasSuffix(name, transcriptDataExt) {
+		return "transcript-data"
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".mp4":
+		return itemVideo
+	case ".m4a", ".mp3", ".aac":
+		return itemAudio
+	case ".txt", ".json", ".jsonl":
+		return "transcript"
+	case ".vtt":
+		return "captions"
+	case ".md", ".html":
+		return "page"
+	}
+
+	return itemDocument
+}
+
+func indexFiles(dir string, files []string) ([]IndexFile, error) {
+	out := make([]IndexFile, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		sum, err := hashFile(file)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, IndexFile{
+			Path:      filepath.ToSlash(rel),
+			Kind:      fileKind(file),
+			Encrypted: strings.HasSuffix(file, ageExt),
+			Size:      info.Size(),
+			SHA256:    sum,
+			Modified:  info.ModTime().UTC(),
+		})
+	}
+
+	return out, nil
+}
+
+// loadIndex reads back the index.json of the course saved in dir.
+func loadIndex(dir string) (*Index, error) {
+	filename := filepath.Join(dir, "index.json")
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	var index Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return &index, nil
+}
+
+// writeIndex writes index.json for the course saved in dir.
+func writeIndex(ctx context.Context, course *Course, videos []VideoEntry, dir string) error {
+	index := Index{Course: course.URL, Title: course.Title, Generated: time.Now().UTC(), Items: make([]IndexItem, 0, len(videos))}
+	var err error
+	courseFiles := []string{
+		filepath.Join(dir, "course.json"),
+		filepath.Join(dir, "README.md"),
+		filepath.Join(dir, "TOC.md"),
+		filepath.Join(dir, "index.html"),
+	}
+	if index.Files, err = indexFiles(dir, courseFiles); err != nil {
+		return err
+	}
+	for _, v := range videos {
+		files := itemFiles(v.filename)
+		item := IndexItem{VideoEntry: v}
+		if err := course.failures[v.filename]; err != nil {
+			item.Error, item.ErrorCode = err.Error(), errorCode(err)
+		}
+		if b, err := os.ReadFile(v.filename + ".ocr.json"); err == nil {
+			_ = json.Unmarshal(b, &item.Screen)
+		}
+		if item.Files, err = indexFiles(dir, files); err != nil {
+			return err
+		}
+		index.Items = append(index.Items, item)
+	}
+
+	filename := filepath.Join(dir, "index.json")
+	if err := writeJSONFile(filename, index); err != nil {
+		return err
+	}
+	logf(ctx, tr("💾 index saved: %s\n"), filename)
+
+	return nil
+}
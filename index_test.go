@@ -0,0 +1,43 @@
+package lld_test
+
+import (
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+func TestFileKind(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "1_01_Intro.mp4", want: "video"},
+		{name: "1_01_Intro.MP4", want: "video"},
+		{name: "1_01_Intro.m4a", want: "audio"},
+		{name: "1_01_Intro.mp3", want: "audio"},
+		{name: "1_01_Intro.txt", want: "transcript"},
+		{name: "1_01_Intro.json", want: "transcript"},
+		{name: "1_01_Intro.jsonl", want: "transcript"},
+		{name: "1_01_Intro.de.txt", want: "transcript"},
+		{name: "1_01_Intro.transcript.json", want: "transcript-data"},
+		{name: "1_01_Intro.vtt", want: "captions"},
+		{name: "1_01_Intro.md", want: "page"},
+		{name: "1_01_Intro.html", want: "page"},
+		{name: "1_03_Chapter_Quiz.quiz.json", want: "quiz"},
+		{name: "1_03_Chapter_Quiz.flashcards.md", want: "quiz"},
+		{name: "1_01_Intro.ocr.json", want: "screen-text"},
+		{name: "1_02_Exercise_Files.zip", want: "document"},
+		{name: "1_02_Exercise_Files.pdf", want: "document"},
+		{name: "1_01_Intro.mp4.age", want: "video"},
+		{name: "1_03_Chapter_Quiz.quiz.json.age", want: "quiz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := lld.FileKind(tt.name); got != tt.want {
+				t.Errorf("FileKind(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
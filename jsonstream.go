@@ -0,0 +1,81 @@
+package lld
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// writeJSONFile encodes v into filename as indented JSON straight through a buffered writer, rather than marshaling
+// it all into memory first.
+func writeJSONFile(filename string, v any) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return f.Close()
+}
+
+// encodeTranscriptJSON writes video as a JSON object, streaming its captions one at a time as they can run to
+// thousands for long videos.
+func encodeTranscriptJSON(w io.Writer, video VideoEntry) error {
+	captions := video.Captions
+	video.Captions = nil
+	b, err := json.Marshal(video)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	_, _ = bw.Write(bytes.TrimSuffix(b, []byte("}")))
+	if len(captions) > 0 {
+		_, _ = bw.WriteString(`,"captions":[`)
+		enc := json.NewEncoder(bw)
+		for i, cue := range captions {
+			if i > 0 {
+				_, _ = bw.WriteString(",")
+			}
+			if err := enc.Encode(cue); err != nil {
+				return err
+			}
+		}
+		_, _ = bw.WriteString("]")
+	}
+	_, _ = bw.WriteString("}\n")
+
+	return bw.Flush()
+}
+
+// encodeTranscriptJSONL writes video as JSON Lines: the item without its captions, then a line per caption.
+func encodeTranscriptJSONL(w io.Writer, video VideoEntry) error {
+	captions := video.Captions
+	video.Captions = nil
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(video); err != nil {
+		return err
+	}
+	for _, cue := range captions {
+		if err := enc.Encode(cue); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,130 @@
+package lld
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// keychainService is what our secrets are filed under in the OS keychain.
+const keychainService = "lld"
+
+// errNoKeychain is returned when the OS keychain can't be reached, e.g. libsecret isn't installed.
+var errNoKeychain = errors.New("no OS keychain available")
+
+// keychainSet stores secret under account in the OS keychain: the macOS Keychain, libsecret (GNOME Keyring, KWallet)
+// on Linux, or a DPAPI protected file on Windows. Secrets are base64 encoded so any bytes survive the CLIs.
+func keychainSet(ctx context.Context, account, secret string) error {
+	secret = base64.StdEncoding.EncodeToString([]byte(secret))
+	switch runtime.GOOS {
+	case "darwin":
+		// Fed through stdin, so the secret never shows up in the process list.
+		return runCLI(ctx, strings.NewReader("add-generic-password -U -s "+keychainService+" -a "+account+" -w "+secret+"\n"),
+			"security", "-i")
+	case "windows":
+		file, err := dpapiFile(account)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			return err
+		}
+		return runPowerShell(ctx, file, secret, `Add-Type -AssemblyName System.Security;
+$b = [Text.Encoding]::UTF8.GetBytes([Console]::In.ReadToEnd());
+[IO.File]::WriteAllBytes($env:LLD_DPAPI_FILE, [Security.Cryptography.ProtectedData]::Protect($b, $null, 'CurrentUser'))`)
+	default:
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return errNoKeychain
+		}
+		return runCLI(ctx, strings.NewReader(secret),
+			"secret-tool", "store", "--label", keychainService+" "+account, "service", keychainService, "account", account)
+	}
+}
+
+// keychainGet returns the secret stored under account.
+func keychainGet(ctx context.Context, account string) (string, error) {
+	var (
+		out string
+		err error
+	)
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = outputCLI(ctx, "security", "find-generic-password", "-s", keychainService, "-a", account, "-w")
+	case "windows":
+		file, ferr := dpapiFile(account)
+		if ferr != nil {
+			return "", ferr
+		}
+		out, err = outputPowerShell(ctx, file, `Add-Type -AssemblyName System.Security;
+$b = [Security.Cryptography.ProtectedData]::Unprotect([IO.File]::ReadAllBytes($env:LLD_DPAPI_FILE), $null, 'CurrentUser');
+[Console]::Out.Write([Text.Encoding]::UTF8.GetString($b))`)
+	default:
+		if _, lerr := exec.LookPath("secret-tool"); lerr != nil {
+			return "", errNoKeychain
+		}
+		out, err = outputCLI(ctx, "secret-tool", "lookup", "service", keychainService, "account", account)
+	}
+	if err != nil {
+		return "", err
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(out))
+
+	return string(b), err
+}
+
+// keychainDelete removes the secret stored under account, if any.
+func keychainDelete(ctx context.Context, account string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return runCLI(ctx, nil, "security", "delete-generic-password", "-s", keychainService, "-a", account)
+	case "windows":
+		file, err := dpapiFile(account)
+		if err != nil {
+			return err
+		}
+		return os.Remove(file)
+	default:
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return errNoKeychain
+		}
+		return runCLI(ctx, nil, "secret-tool", "clear", "service", keychainService, "account", account)
+	}
+}
+
+// dpapiFile is where a Windows secret is kept, encrypted for the current user.
+func dpapiFile(account string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, keychainService, account+".dpapi"), nil
+}
+
+func powerShell(ctx context.Context, file, script string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.Env = append(os.Environ(), "LLD_DPAPI_FILE="+file)
+
+	return cmd
+}
+
+func runPowerShell(ctx context.Context, file, stdin, script string) error {
+	cmd := powerShell(ctx, file, script)
+	cmd.Stdin = strings.NewReader(stdin)
+
+	return runCmd(cmd)
+}
+
+func outputPowerShell(ctx context.Context, file, script string) (string, error) {
+	var sb strings.Builder
+	cmd := powerShell(ctx, file, script)
+	cmd.Stdout = &sb
+	err := runCmd(cmd)
+
+	return sb.String(), err
+}
@@ -0,0 +1,61 @@
+package lld
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are a handful of very common, fairly distinctive words per language, which is plenty to tell
+// the languages of LinkedIn Learning's catalog apart in a whole transcript.
+func stopwords() map[string][]string {
+	return map[string][]string{
+		"en": {"the", "and", "is", "you", "that", "of", "to", "this", "with", "we"},
+		"es": {"el", "la", "que", "de", "los", "las", "es", "en", "por", "una"},
+		"pt": {"o", "que", "não", "de", "os", "uma", "é", "em", "para", "você"},
+		"fr": {"le", "la", "les", "et", "est", "que", "des", "une", "vous", "nous"},
+		"de": {"der", "die", "und", "das", "ist", "nicht", "ein", "wir", "sie", "mit"},
+		"it": {"il", "che", "di", "la", "è", "per", "una", "sono", "non", "gli"},
+		"nl": {"de", "het", "een", "en", "van", "is", "niet", "dat", "je", "we"},
+	}
+}
+
+// iso6392 maps the languages we detect to the three-letter codes MP4 subtitle tracks use.
+func iso6392(lang string) string {
+	codes := map[string]string{"en": "eng", "es": "spa", "pt": "por", "fr": "fra", "de": "deu", "it": "ita", "nl": "nld"}
+	if c, ok := codes[lang]; ok {
+		return c
+	}
+
+	return "und"
+}
+
+// detectLanguage guesses the language of text by counting stopwords, returning an ISO 639-1 code, or "" if unsure.
+func detectLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	counts := map[string]int{}
+	for _, w := range words {
+		counts[w]++
+	}
+
+	best, bestScore, second := "", 0, 0
+	for lang, sw := range stopwords() {
+		score := 0
+		for _, w := range sw {
+			score += counts[w]
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, second = lang, score, bestScore
+		case score > second:
+			second = score
+		}
+	}
+	// Too little text, or too close a call (Spanish and Portuguese share a lot), to say.
+	if bestScore < 5 || bestScore < second*5/4 {
+		return ""
+	}
+
+	return best
+}
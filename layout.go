@@ -0,0 +1,124 @@
+package lld
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Output layouts for -layout.
+const (
+	layoutFlat     = "flat"
+	layoutSections = "sections"
+)
+
+// Item numberings for -numbering.
+const (
+	numberingPerSection = "per-section"
+	numberingGlobal     = "global"
+)
+
+// File name styles for -name-style.
+const (
+	nameStyleUnderscore = "underscore"
+	nameStyleSlug       = "slug"
+)
+
+// How -unicode treats non-ASCII characters in file names.
+const (
+	unicodeStrip    = "strip"
+	unicodeTranslit = "translit"
+	unicodeKeep     = "keep"
+)
+
+var (
+	slugRE           = regexp.MustCompile(`[^a-z0-9]+`)
+	invalidUnicodeRE = regexp.MustCompile(`[^\p{L}\p{M}\p{N}._-]+`)
+	slugUnicodeRE    = regexp.MustCompile(`[^\p{Ll}\p{Lm}\p{Lo}\p{M}\p{N}]+`)
+)
+
+// setupLayout checks -layout, -numbering, -pad, -name-style, and -unicode.
+func setupLayout(opts *options) error {
+	if !slices.Contains([]string{layoutFlat, layoutSections}, opts.layout) {
+		return fmt.Errorf("unsupported -layout %q, expected flat or sections", opts.layout)
+	}
+	if !slices.Contains([]string{numberingPerSection, numberingGlobal}, opts.numbering) {
+		return fmt.Errorf("unsupported -numbering %q, expected per-section or global", opts.numbering)
+	}
+	if !slices.Contains([]string{nameStyleUnderscore, nameStyleSlug}, opts.nameStyle) {
+		return fmt.Errorf("unsupported -name-style %q, expected underscore or slug", opts.nameStyle)
+	}
+	if !slices.Contains([]string{unicodeStrip, unicodeTranslit, unicodeKeep}, opts.unicode) {
+		return fmt.Errorf("unsupported -unicode %q, expected strip, translit, or keep", opts.unicode)
+	}
+	if opts.pad < 0 {
+		return fmt.Errorf("bad -pad %d, expected 0 or more", opts.pad)
+	}
+
+	return nil
+}
+
+// setFilenames names where each item's files go in dir, without their extension: Section.01.Title with the flat
+// -layout, or 01_Section/01_Title with a folder per section. Items are numbered within their section, or through the
+// whole course with -numbering global, zero padded to -pad digits. Where they go is recorded in their File too.
+func setFilenames(videos []VideoEntry, dir string, opts *options) {
+	name := fileNamer(opts)
+	n, section := 0, ""
+	for i, v := range videos {
+		if v.Section != section || i == 0 {
+			n++
+			section = v.Section
+		}
+		index := v.Index
+		if opts.numbering == numberingGlobal {
+			index = i + 1
+		}
+		file := name(fmt.Sprintf("%s.%0*d.%s", v.Section, opts.pad, index, v.Title))
+		if opts.layout == layoutSections {
+			file = path.Join(name(fmt.Sprintf("%0*d %s", opts.pad, n, v.Section)), name(fmt.Sprintf("%0*d %s", opts.pad, index, v.Title)))
+		}
+		videos[i].File = file
+		videos[i].filename = filepath.Join(dir, filepath.FromSlash(file))
+	}
+}
+
+// restoreFilenames sets where the items of a course saved in dir, read back from its course.json, were saved, from
+// their recorded File: whatever layout flags this run has, those are where their files are. Only items of a
+// course.json from before File was recorded are named by opts instead.
+func restoreFilenames(videos []VideoEntry, dir string, opts *options) {
+	recorded := make([]string, len(videos))
+	for i, v := range videos {
+		recorded[i] = v.File
+	}
+	setFilenames(videos, dir, opts)
+	for i, file := range recorded {
+		if file != "" {
+			videos[i].File = file
+			videos[i].filename = filepath.Join(dir, filepath.FromSlash(file))
+		}
+	}
+}
+
+// fileNamer returns what turns a title into a file name according to -name-style and -unicode. The default is just
+// sanitizeFileName, while slugs are lowercase words joined by dashes.
+func fileNamer(opts *options) func(string) string {
+	invalid, slug := invalidRE, slugRE
+	if opts.unicode == unicodeKeep {
+		invalid, slug = invalidUnicodeRE, slugUnicodeRE
+	}
+
+	return func(s string) string {
+		s = strings.TrimSpace(strings.ReplaceAll(s, "| LinkedIn Learning", ""))
+		if opts.unicode == unicodeTranslit {
+			s = transliterate(s)
+		}
+		if opts.nameStyle == nameStyleSlug {
+			return strings.Trim(slug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+		}
+
+		return invalid.ReplaceAllString(s, "_")
+	}
+}
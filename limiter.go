@@ -0,0 +1,69 @@
+package lld
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// limiter is a token bucket shared by every navigation and download of a run, so that no combination of
+// workers can hammer LinkedIn harder than -rps. A nil *limiter never waits.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens per second.
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rps float64) *limiter {
+	if rps <= 0 {
+		return nil
+	}
+
+	return &limiter{rate: rps, tokens: 1, last: time.Now()}
+}
+
+// wait blocks until a token is available. Tokens are reserved up front (going negative), so waiters are served in order.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, 1)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	return sleepCtx(ctx, delay)
+}
+
+type limiterKey struct{}
+
+func withLimiter(ctx context.Context, l *limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, l)
+}
+
+func limiterFrom(ctx context.Context) *limiter {
+	l, _ := ctx.Value(limiterKey{}).(*limiter)
+	return l
+}
+
+// navigate is chromedp.Navigate, throttled by the run's limiter.
+func navigate(u string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := limiterFrom(ctx).wait(ctx); err != nil {
+			return err
+		}
+
+		return chromedp.Navigate(u).Do(ctx)
+	})
+}
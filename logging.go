@@ -0,0 +1,121 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Log formats.
+const (
+	logText = "text"
+	logJSON = "json"
+)
+
+// jsonLogs is whether messages are JSON objects with their fields, for -log-format json.
+var jsonLogs bool //nolint:gochecknoglobals // Set once from -log-format before any output.
+
+type logFieldsKey struct{}
+
+// setupLogging applies -log-format, overriding the output setupOutput chose for JSON.
+func setupLogging(opts *options) error {
+	switch opts.logFormat {
+	case logText:
+	case logJSON:
+		jsonLogs = true
+		log.SetFlags(0)
+		log.SetOutput(&jsonWriter{w: opts.logOutput})
+	default:
+		return fmt.Errorf("unsupported -log-format %q, expected text or json", opts.logFormat)
+	}
+
+	return nil
+}
+
+// withLogFields returns ctx with the key-value pairs added to the fields of messages logged with it, e.g. the course
+// and video being worked on, so a concurrent run's interleaved output can be told apart. A key already there, like the
+// attempt of a retry, is replaced.
+func withLogFields(ctx context.Context, args ...any) context.Context {
+	old, _ := ctx.Value(logFieldsKey{}).([]any)
+	fields := make([]any, 0, len(old)+len(args))
+	for i := 0; i+1 < len(old); i += 2 {
+		if !hasLogField(args, old[i]) {
+			fields = append(fields, old[i], old[i+1])
+		}
+	}
+
+	return context.WithValue(ctx, logFieldsKey{}, append(fields, args...))
+}
+
+func hasLogField(args []any, key any) bool {
+	for i := 0; i < len(args); i += 2 {
+		if args[i] == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// logf logs like log.Printf, with the fields of ctx when they're shown.
+func logf(ctx context.Context, format string, args ...any) {
+	logMessage(ctx, fmt.Sprintf(format, args...))
+}
+
+// logln logs like log.Println, with the fields of ctx when they're shown.
+func logln(ctx context.Context, args ...any) {
+	logMessage(ctx, fmt.Sprintln(args...))
+}
+
+func logMessage(ctx context.Context, msg string) {
+	if !jsonLogs {
+		log.Print(msg)
+		return
+	}
+	fields, _ := ctx.Value(logFieldsKey{}).([]any)
+	log.Print(jsonLine(strings.TrimSuffix(msg, "\n"), fields...))
+}
+
+// jsonLine encodes a message and its fields as a line of JSON.
+func jsonLine(msg string, fields ...any) string {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				a.Value = slog.StringValue(a.Value.Time().Format(time.RFC3339Nano))
+			}
+			return a
+		},
+	})
+	slog.New(h).Info(msg, fields...)
+
+	return buf.String()
+}
+
+// jsonWriter makes JSON lines of the messages that don't have fields, so every line of -log-format json is JSON.
+type jsonWriter struct {
+	w io.Writer
+}
+
+func (j *jsonWriter) Write(b []byte) (int, error) {
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(string(b), "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "{"):
+			out.WriteString(line)
+		default:
+			out.WriteString(jsonLine(strings.TrimSuffix(line, "\n")))
+		}
+	}
+	if _, err := io.WriteString(j.w, out.String()); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
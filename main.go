@@ -1,8 +1,10 @@
-package main
+package lld
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -10,24 +12,168 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 )
 
 type VideoEntry struct {
-	Href       string `json:"href"`
-	Section    string `json:"section"`
-	Title      string `json:"title"`
-	Duration   string `json:"duration"`
-	Transcript string `json:"transcript,omitempty"`
-	filename   string
-	Index      int `json:"index"`
+	Href       string   `json:"href"`
+	Section    string   `json:"section"`
+	Title      string   `json:"title"`
+	Type       string   `json:"type"`
+	Duration   string   `json:"duration"`
+	Level      string   `json:"level,omitempty"`
+	Skills     []string `json:"skills,omitempty"`
+	Transcript string   `json:"transcript,omitempty"`
+	Captions   []Cue    `json:"captions,omitempty"`
+	Language   string   `json:"language,omitempty"` // ISO 639-1, detected from the transcript.
+	Words      int      `json:"words,omitempty"`    // Of the transcript.
+	// ReadingMinutes is how long the transcript takes to read, for planning study sessions.
+	ReadingMinutes int `json:"readingMinutes,omitempty"`
+	// File is where the item's files are saved, relative to the course directory with forward slashes, and without
+	// their extensions.
+	File     string `json:"file,omitempty"`
+	filename string
+	Index    int `json:"index"`
 }
 
+type options struct {
+	ssoURL           string
+	session          bool
+	headless         bool
+	credentialSource string
+	courseURL        string
+	videoURL         string
+	resumeFrom       string
+	only             string
+	onlyRE           *regexp.Regexp
+	exclude          string
+	excludeRE        *regexp.Regexp
+	clean            bool
+	cleanRules       string
+	cleaners         []*regexp.Regexp
+	punctuate        string
+	llmURL           string
+	llmModel         string
+	punctuator       punctuator
+	outDir           string
+	layout           string
+	numbering        string
+	pad              int
+	nameStyle        string
+	unicode          string
+	timeout          time.Duration
+	backoff          time.Duration
+	waitTimeout      time.Duration
+	dlTranscripts    bool
+	saveJSON         bool
+	// transcriptTemplate is the -transcript-template file, parsed into transcriptTmpl by setup.
+	transcriptTemplate string
+	transcriptTmpl     *template.Template
+	jsonlFile          string
+	artifacts          *artifactLog
+	harFile            string
+	har                *harRecorder
+	replay             *replayer // Set by lld replay, to answer requests from fixtures.
+	jsonLines          bool
+	dlVideos           bool
+	drmTranscripts     bool
+	readme             bool
+	toc                bool
+	player             bool
+	summaries          bool
+	embedSubs          bool
+	burnSubs           bool
+	ocr                time.Duration
+	translate          string
+	translateAPI       string
+	translateURL       string
+	translator         translator
+	lang               string
+	plain              bool
+	logFormat          string
+	logOutput          io.Writer // Where messages are logged to, os.Stderr unless a Client's WithLogger.
+	// The -on-* hooks: commands to run when an item is saved, a course is done, or either failed.
+	onVideoDownloaded string
+	onCourseComplete  string
+	onFailure         string
+	pluginsDir        string
+	selectorsFile     string     // -selectors, a file or URL.
+	selectors         *Selectors // The built-in ones with the -selectors overrides.
+	exportTo          string
+	exporters         []string // The -export-to plugins' executables.
+	noColor           bool
+	followRelated     bool
+	maxDepth          int
+	segments          int
+	tabs              int
+	downloads         int
+	hostConns         int
+	downloader        string
+	baseURL           string
+	learningBase      *url.URL // Where courses are found, from -base-url, always ending with a slash.
+	caBundle          string
+	insecure          bool
+	tlsConfig         *tls.Config
+	spkiHashes        []string
+	ipVersion         string
+	rps               float64
+	limiter           *limiter
+	blockRequests     bool
+	screenshots       bool
+	otlpEndpoint      string
+	pprofAddr         string
+	cpuProfile        string
+	memProfile        string
+	cacheTTL          time.Duration
+	refresh           bool
+	sync              bool
+	resyncUpdated     bool
+	dedupe            string
+	preflight         int
+	checksums         bool
+	archive           string
+	archiveStdout     bool
+	upload            string
+	uploader          uploader
+	encrypt           string
+	recipients        []string
+	dl                *downloader
+	maxBytes          string
+	activeHours       string
+	window            *activeHours
+	meter             *meter
+	// progress, if set, is told about each item as it's done, failed (err) or not.
+	progress func(done, total int, video VideoEntry, err error)
+	// hold, if set, is called before each item, and blocks while the run is paused.
+	hold func(ctx context.Context)
+	// track, if set, is told when the n-th item starts, with a func that skips just it, and returns a func for when
+	// it's done.
+	track func(n int, video VideoEntry, skip context.CancelFunc) (done func())
+}
+
+// TOC item types.
+const (
+	itemVideo    = "video"
+	itemAudio    = "audio"
+	itemDocument = "document"
+	itemQuiz     = "quiz"
+)
+
+// defaultSection names the one section of courses whose table of contents has none, as file names are made from it.
+// It isn't translated, so the names stay the same whatever -lang.
+const defaultSection = "Contents"
+
 var invalidRE = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
 
 func sanitizeFileName(s string) string {
@@ -36,231 +182,1155 @@ func sanitizeFileName(s string) string {
 	return invalidRE.ReplaceAllString(s, "_")
 }
 
-const videoParseJS = `(() => {
-	const sections = Array.from(document.querySelectorAll("section.classroom-toc-section"));
+// expandTOCJS expands the collapsed sections of the table of contents, and scrolls to its end until no more items
+// render, as long courses only render some of them at first. It resolves with how many items there are.
+const expandTOCJS = `async sel => {
+	const sleep = ms => new Promise(resolve => setTimeout(resolve, ms));
+	const expand = async () => {
+		for (const section of document.querySelectorAll(sel.tocSection)) {
+			const toggle = section.querySelector(sel.tocSectionToggle);
+			if (toggle?.getAttribute("aria-expanded") === "false") {
+				toggle.click();
+				await sleep(100);
+			}
+		}
+	};
+	await expand();
+	let count = -1;
+	for (let i = 0; i < 40; i++) {
+		const items = document.querySelectorAll(sel.tocItem);
+		if (items.length === count) break;
+		count = items.length;
+		items[items.length - 1]?.scrollIntoView({ block: "end" });
+		await sleep(250);
+		// Sections rendered by scrolling may be collapsed too.
+		await expand();
+	}
+	document.querySelector(sel.tocSection)?.scrollIntoView({ block: "start" });
+	return document.querySelectorAll(sel.tocItem).length;
+}`
+
+const videoParseJS = `sel => {
 	const results = [];
-	for (const section of sections) {
-		const sectionName = section.querySelector(".classroom-toc-section__toggle-title")?.innerText.trim();
-			const videos = section.querySelectorAll("li.classroom-toc-item");
+	const parse = (sectionName, videos) => {
 		let index = 0;
 		for (const video of videos) {
-			const link = video.querySelector("a.classroom-toc-item__link");
+			const link = video.querySelector(sel.tocItemLink);
 			const spans = Array.from(video.querySelectorAll("span"));
-			const title = Array.from(video.querySelector('.classroom-toc-item__title').childNodes)
-				.find(n => n.nodeType === Node.TEXT_NODE && n.textContent.trim())
- 				.textContent.trim();
-			const duration = spans.map(el => el.innerText.trim())
-				.find(text => text.toLowerCase().endsWith("video")) || "";
+			const title = (Array.from(video.querySelector(sel.tocItemTitle)?.childNodes || [])
+				.find(n => n.nodeType === Node.TEXT_NODE && n.textContent.trim())?.textContent || link?.innerText || "")
+				.trim();
+			const label = spans.map(el => el.innerText.trim())
+				.find(text => /(video|audio|document|pdf|quiz)$/i.test(text)) || "";
+			let type = "video";
+			if (/quiz$/i.test(label) || /\/quiz\b/i.test(link?.href || "") || video.querySelector('li-icon[type*="quiz"]')) {
+				type = "quiz";
+			} else if (/audio$/i.test(label) || video.querySelector('li-icon[type*="audio"]')) {
+				type = "audio";
+			} else if (/(document|pdf)$/i.test(label) || video.querySelector('li-icon[type*="document"], li-icon[type*="file"]')) {
+				type = "document";
+			}
+			const duration = /(video|audio)$/i.test(label) ? label : "";
 			if (!link) continue;
 			index++;
 			results.push({
 				href: link.href,
 				section: sectionName,
 				title: title,
+				type: type,
 				index: index,
 				duration: duration.split(' ').slice(0, -1).join('')
 			});
 		}
+	};
+	const sections = Array.from(document.querySelectorAll(sel.tocSection))
+		.filter(section => section.querySelector(sel.tocItem));
+	for (const section of sections) {
+		parse(section.querySelector(sel.tocSectionTitle)?.innerText.trim() || "", section.querySelectorAll(sel.tocItem));
 	}
+	// Short courses may list their items without any section around them.
+	if (!sections.length) parse("", document.querySelectorAll(sel.tocItem));
 	return results;
-})()`
-
-func main() {
-	ssoURL := flag.String("sso", "", "URL to the enterprise SSO sign-on.")
-	courseURL := flag.String("course", "", "URL of the the course to download.")
-	dlTranscripts := flag.Bool("transcripts", false, "Whether or not to download transcripts.")
-	saveJSON := flag.Bool("json", false, "Whether or not to output the transcript as JSON.")
-	dlVideos := flag.Bool("videos", false, "Whether or not to download videos.")
-	timeout := flag.Duration("timeout", time.Hour, "Timeout for the entire operation.")
-	backoff := flag.Duration("backoff", time.Minute, "How often to wait between backoff retries.")
+}`
+
+// commands are the subcommands available as the first argument; anything else is treated as a course download.
+func commands() map[string]func(args []string) {
+	return map[string]func(args []string){
+		"author":      runAuthor,
+		"clean":       runClean,
+		"compare":     runCompare,
+		"doctor":      runDoctor,
+		"export":      runExport,
+		"parquet":     runParquet,
+		"plan":        runPlan,
+		"plugins":     runPlugins,
+		"replay":      runReplay,
+		"auth":        runAuth,
+		"retry":       runRetry,
+		"serve":       runServe,
+		"verify":      runVerify,
+		"version":     runVersion,
+		"selectors":   runSelectors,
+		"self-update": runSelfUpdate,
+	}
+}
+
+// Main runs the lld command with os.Args, exiting when it's done.
+func Main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands()[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
+	var opts options
+	registerFlags(flag.CommandLine, &opts)
+	flag.StringVar(&opts.courseURL, "course", "", "URL of the course to download (or of one of its videos, or its slug).")
+	flag.StringVar(&opts.videoURL, "video", "", "URL of a single video to download, instead of its whole course.")
 	flag.Parse()
+	if err := setup(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
 
-	if !*dlVideos && !*dlTranscripts {
-		log.Fatal("❌ You must specify at least one of -transcripts or -videos to download.")
+	if !opts.dlVideos && !opts.dlTranscripts {
+		log.Fatal(tr("❌ You must specify at least one of -transcripts or -videos to download."))
+	}
+	if err := normalizeTarget(&opts); err != nil {
+		log.Fatal(errorText(err))
 	}
 
-	ctx, cancel := newChromeDPCtx(*timeout)
+	ctx, cancel := newChromeDPCtx(opts.timeout, &opts)
 	defer cancel()
 
-	if err := ssoLogin(ctx, *ssoURL); err != nil {
-		log.Fatal(err)
+	err := login(ctx, &opts)
+	switch {
+	case err != nil:
+	case opts.videoURL != "":
+		err = downloadSingle(ctx, &opts, opts.videoURL)
+	default:
+		err = downloadWithRelated(ctx, &opts, opts.courseURL)
 	}
-	log.Println("✅ Logged in.")
-
-	videos, err := parseCourseVideos(ctx, *courseURL)
+	shutdownTracing()
+	stopProfiling(&opts)
+	reportUsage(&opts)
 	if err != nil {
-		log.Fatalf("❌ Failed to extract video links: %v", err)
+		log.Println(errorText(err))
+		cancel()
+		os.Exit(exitCode(err))
 	}
-	log.Printf("🎯 Found %d video(s) across %d sections\n", len(videos), countSections(videos))
 
-	processVideos(ctx, videos, backoff, *dlTranscripts, *saveJSON, *dlVideos)
+	log.Println(tr("✅ All courses info saved."))
+}
+
+// registerFlags registers the flags shared by every command that logs in and downloads courses.
+func registerFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.ssoURL, "sso", "", "URL to the enterprise SSO sign-on.")
+	fs.BoolVar(&opts.session, "session", false, "Whether or not to keep the session in the OS keychain, so later runs can skip SSO.")
+	fs.BoolVar(&opts.headless, "headless", false, "Whether or not to run the browser without a window (needs a saved session).")
+	fs.StringVar(&opts.credentialSource, "credential-source", "",
+		"Where to read the session cookies from: keychain, env:NAME, op://vault/item/field, or vault:path#field.")
+	fs.StringVar(&opts.outDir, "output", ".", "Directory to save files into.")
+	fs.StringVar(&opts.layout, "layout", layoutFlat, "How to lay out a course's files: flat, or sections for a folder per section.")
+	fs.StringVar(&opts.numbering, "numbering", numberingPerSection, "How to number items in file names: per-section or global.")
+	fs.IntVar(&opts.pad, "pad", 2, "How many digits to zero pad the numbers in file names to.")
+	fs.StringVar(&opts.unicode, "unicode", unicodeStrip, "What to do with non-ASCII characters in file names: strip, translit, or keep.")
+	fs.StringVar(&opts.nameStyle, "name-style", nameStyleUnderscore, "File name style: underscore, or slug for lowercase and dash-separated.")
+	fs.StringVar(&opts.lang, "lang", "", "Language for messages (en, es, de); defaults to $LANG.")
+	fs.BoolVar(&opts.noColor, "no-color", false, "Whether or not to disable colored output (also honors $NO_COLOR).")
+	fs.StringVar(&opts.logFormat, "log-format", logText, "How to write messages: text, or json with the course, video, and attempt of each.")
+	fs.BoolVar(&opts.plain, "plain", false, "Whether or not to use plain ASCII output (automatic when not on a terminal).")
+	fs.BoolVar(&opts.dlTranscripts, "transcripts", false, "Whether or not to download transcripts.")
+	fs.BoolVar(&opts.saveJSON, "json", false, "Whether or not to output the transcript as JSON.")
+	fs.StringVar(&opts.transcriptTemplate, "transcript-template", "", "Go template file to render text transcripts with.")
+	fs.StringVar(&opts.jsonlFile, "jsonl", "", "File to append a JSON line to for everything scraped, as it's saved.")
+	fs.StringVar(&opts.harFile, "har", "", "File to record the browser's traffic to after logging in, as a HAR (for bug reports).")
+	fs.BoolVar(&opts.jsonLines, "json-lines", false, "Whether or not to output the transcript as JSON Lines, a line per caption.")
+	fs.BoolVar(&opts.dlVideos, "videos", false, "Whether or not to download videos.")
+	fs.BoolVar(&opts.drmTranscripts, "drm-transcripts", false, "Whether or not to fall back to the transcript for DRM-protected videos.")
+	fs.BoolVar(&opts.readme, "readme", false, "Whether or not to write a README.md with the course details and a linked TOC.")
+	fs.BoolVar(&opts.toc, "toc", false, "Whether or not to write a TOC.md linking each item to its saved files.")
+	fs.BoolVar(&opts.player, "player", false, "Whether or not to write an index.html for watching the course offline.")
+	fs.BoolVar(&opts.summaries, "summaries", false, "Whether or not to write a Section_N_summary.md of key points per section.")
+	fs.BoolVar(&opts.embedSubs, "embed-subs", false, "Whether or not to mux the captions into each MP4 as a subtitle track (needs ffmpeg).")
+	fs.BoolVar(&opts.burnSubs, "burn-subs", false, "Whether or not to render the captions into each MP4's frames (needs ffmpeg).")
+	fs.StringVar(&opts.translate, "translate", "", "Language (e.g. de) to also write machine translated transcripts in.")
+	fs.StringVar(&opts.translateAPI, "translate-backend", "libretranslate", "Service for -translate: deepl, google, or libretranslate.")
+	fs.StringVar(&opts.translateURL, "translate-url", "", "Endpoint of the -translate-backend, e.g. a self-hosted LibreTranslate.")
+	fs.DurationVar(&opts.ocr, "ocr", 0, "How often to sample video frames for on-screen text, e.g. 30s (0 to disable).")
+	fs.BoolVar(&opts.followRelated, "follow-related", false, "Whether or not to also download the related courses of each course.")
+	fs.IntVar(&opts.maxDepth, "max-depth", 1, "How many levels of related courses to follow with -follow-related.")
+	fs.IntVar(&opts.segments, "segments", 1, "How many parallel connections to split each download across.")
+	fs.StringVar(&opts.resumeFrom, "resume-from", "", "Start at this item of the course, as SECTION.ITEM (e.g. 3.07) or its URL.")
+	fs.StringVar(&opts.only, "only", "", "Only download items whose title or section matches this regular expression.")
+	fs.StringVar(&opts.exclude, "exclude", "", "Skip items whose title or section matches this regular expression, e.g. 'Challenge|Solution'.")
+	fs.BoolVar(&opts.clean, "clean", false, "Whether or not to remove noise like (upbeat music) and speaker labels from transcripts.")
+	fs.StringVar(&opts.cleanRules, "clean-rules", "", "File of extra regular expressions, one per line, to remove from transcripts.")
+	fs.StringVar(&opts.punctuate, "punctuate", "", "Restore punctuation and casing of transcripts: rules, or llm for -llm-url.")
+	fs.StringVar(&opts.llmURL, "llm-url", "", "OpenAI-compatible chat completions URL for -punctuate llm.")
+	fs.StringVar(&opts.llmModel, "llm-model", "", "Model to ask at -llm-url.")
+	fs.IntVar(&opts.tabs, "tabs", 1, "How many browser tabs to work through a course's items in at once.")
+	fs.IntVar(&opts.downloads, "downloads", 0, "Maximum simultaneous file downloads (0 for no limit).")
+	fs.IntVar(&opts.hostConns, "host-conns", 0, "Maximum connections per host for downloads, counting -segments (0 for no limit).")
+	fs.StringVar(&opts.maxBytes, "max-bytes", "", "Stop downloading once this much was downloaded in the run, e.g. 2.5G (0 for no limit).")
+	fs.StringVar(&opts.activeHours, "active-hours", "", "Window of the day to download in, e.g. 22:00-06:00, pausing outside it.")
+	fs.StringVar(&opts.downloader, "downloader", downloaderInternal, "What downloads the files: internal, yt-dlp, or aria2c.")
+	fs.StringVar(&opts.baseURL, "base-url", defaultBaseURL, "Home page of LinkedIn Learning, or of a Learning Hub on its own domain.")
+	fs.StringVar(&opts.caBundle, "ca-bundle", "", "PEM file of extra CA certificates to trust, e.g. a corporate TLS proxy's.")
+	fs.BoolVar(&opts.insecure, "insecure-skip-verify", false, "Whether or not to skip TLS certificate verification (unsafe).")
+	fs.StringVar(&opts.ipVersion, "ip-version", ipVersionAuto, "IP version to download over: 4, 6, or auto.")
+	fs.BoolVar(&opts.blockRequests, "block-requests", false, "Whether or not to block images, fonts, ads, and trackers after logging in.")
+	fs.BoolVar(&opts.screenshots, "screenshots", false, "Whether or not to save a screenshot of the page when an item fails.")
+	fs.StringVar(&opts.otlpEndpoint, "otlp-endpoint", "", "OTLP/HTTP URL to send traces to, e.g. http://localhost:4318/v1/traces.")
+	fs.StringVar(&opts.pprofAddr, "pprof", "", "Address to serve net/http/pprof on while running, e.g. localhost:6060.")
+	fs.StringVar(&opts.cpuProfile, "cpu-profile", "", "File to write a CPU profile of the run to.")
+	fs.StringVar(&opts.memProfile, "mem-profile", "", "File to write a heap profile to at the end of the run.")
+	fs.Float64Var(&opts.rps, "rps", 0, "Maximum page loads and HTTP requests per second, shared by everything (0 for no limit).")
+	fs.DurationVar(&opts.cacheTTL, "cache-ttl", 24*time.Hour, "How long to reuse a course's cached structure (0 to disable).")
+	fs.BoolVar(&opts.refresh, "refresh", false, "Whether or not to ignore the cached course structure and saved progress, and redo them.")
+	fs.BoolVar(&opts.resyncUpdated, "resync-updated", false, "Whether or not to -refresh and -sync courses updated since downloaded.")
+	fs.BoolVar(&opts.sync, "sync", false, "Whether or not to skip files that haven't changed on the server since the last download.")
+	fs.IntVar(&opts.preflight, "preflight", 2, "How many videos to sample to check a course fits on disk before downloading (0 to skip).")
+	fs.StringVar(&opts.dedupe, "dedupe", "", "Shared directory to store downloads in by content hash, linked into each course.")
+	fs.BoolVar(&opts.checksums, "checksums", false, "Whether or not to write a SHA256SUMS of every file of the course.")
+	fs.StringVar(&opts.archive, "archive", "", "Package each finished course into an archive: zip or tgz.")
+	fs.BoolVar(&opts.archiveStdout, "archive-stdout", false, "Whether or not to write the -archive to stdout instead of the course directory.")
+	fs.StringVar(&opts.upload, "upload", "", "Remote folder to upload each finished course to, e.g. webdavs://user@host/path.")
+	fs.StringVar(&opts.encrypt, "encrypt", "", "Encrypt every saved file with age, as age:RECIPIENT[,RECIPIENT...].")
+	fs.StringVar(&opts.onVideoDownloaded, "on-video-downloaded", "", "Command to run (with $LLD_FILE and the like) after each item is saved.")
+	fs.StringVar(&opts.onCourseComplete, "on-course-complete", "", "Command to run (with $LLD_COURSE_DIR and the like) after each course.")
+	fs.StringVar(&opts.onFailure, "on-failure", "", "Command to run (with $LLD_ERROR and the like) when an item or course fails.")
+	fs.StringVar(&opts.pluginsDir, "plugins", defaultPluginsDir(), "Directory to look for exporter plugins in.")
+	fs.StringVar(&opts.exportTo, "export-to", "", "Comma-separated plugins to hand each finished course to (see lld plugins).")
+	fs.StringVar(&opts.selectorsFile, "selectors", "", "JSON file or URL overriding the CSS selectors and page scripts (see lld selectors).")
+	fs.DurationVar(&opts.timeout, "timeout", time.Hour, "Timeout for the entire operation.")
+	fs.DurationVar(&opts.backoff, "backoff", time.Minute, "How often to wait between backoff retries.")
+	fs.DurationVar(&opts.waitTimeout, "wait-timeout", defaultWaitTimeout,
+		"How long to wait for a page to show what's expected, like the transcript, before giving up (0 to wait indefinitely).")
+}
+
+// setup applies the parsed flags; call it right after parsing.
+func setup(opts *options) error {
+	if err := setupOutput(opts); err != nil {
+		return err
+	}
+	switch opts.downloader {
+	case downloaderInternal:
+		opts.downloader = ""
+	case downloaderYtDlp, downloaderAria2c:
+	default:
+		return fmt.Errorf("unsupported -downloader %q, expected internal, yt-dlp, or aria2c", opts.downloader)
+	}
+	if opts.archive != "" && opts.archive != archiveZip && opts.archive != archiveTgz {
+		return fmt.Errorf("unsupported -archive %q, expected zip or tgz", opts.archive)
+	}
+	if opts.upload != "" {
+		u, err := newUploader(opts.upload)
+		if err != nil {
+			return err
+		}
+		opts.uploader = u
+	}
+	if opts.encrypt != "" {
+		recipients, ok := strings.CutPrefix(opts.encrypt, "age:")
+		if !ok || recipients == "" {
+			return fmt.Errorf("unsupported -encrypt %q, expected age:RECIPIENT", opts.encrypt)
+		}
+		opts.recipients = strings.Split(recipients, ",")
+		// The pool would keep the plaintext, and age's output differs every time, so there's nothing to share.
+		if opts.dedupe != "" {
+			return errors.New("-encrypt can't be used with -dedupe, whose pool keeps files unencrypted")
+		}
+	}
+	if opts.translate != "" {
+		t, err := newTranslator(opts.translateAPI, opts.translateURL)
+		if err != nil {
+			return err
+		}
+		opts.translator = t
+	}
+	if err := setupBaseURL(opts); err != nil {
+		return err
+	}
+	if err := setupNetwork(opts); err != nil {
+		return err
+	}
+	if err := setupSelectors(opts); err != nil {
+		return err
+	}
+	if err := setupFilters(opts); err != nil {
+		return err
+	}
+	if err := setupClean(opts); err != nil {
+		return err
+	}
+	if opts.punctuate != "" {
+		p, err := newPunctuator(opts.punctuate, opts.llmURL, opts.llmModel)
+		if err != nil {
+			return err
+		}
+		opts.punctuator = p
+	}
+	if err := setupLayout(opts); err != nil {
+		return err
+	}
+	if err := setupPlugins(opts); err != nil {
+		return err
+	}
+	if err := setupTranscriptTemplate(opts); err != nil {
+		return err
+	}
+	if opts.jsonlFile != "" {
+		l, err := openArtifactLog(opts.jsonlFile)
+		if err != nil {
+			return err
+		}
+		opts.artifacts = l
+	}
+	if opts.harFile != "" {
+		opts.har = newHARRecorder(opts.harFile)
+	}
+	opts.limiter = newLimiter(opts.rps)
+	if err := setupMeter(opts); err != nil {
+		return err
+	}
+	if opts.activeHours != "" {
+		w, err := parseActiveHours(opts.activeHours)
+		if err != nil {
+			return err
+		}
+		opts.window = w
+	}
+	opts.dl = newDownloader(opts)
+	setupTracing(opts)
 
-	log.Println("✅ All courses info saved.")
+	return setupProfiling(opts)
 }
 
-func processVideos(ctx context.Context, videos []VideoEntry, backoff *time.Duration, dlTranscripts, saveJSON, dlVideos bool) {
-	for i, video := range videos {
-		log.Printf("▶️ [%d/%d] %v: %s \n", i+1, len(videos), video.Section, video.Title)
-		if err := visitVideo(ctx, video.Href, *backoff, 0); err != nil {
-			log.Printf("🙅 failed to visit video: %v", err)
+// downloadWithRelated downloads courseURL into its own directory of the output directory, named after its slug, then
+// (with -follow-related) walks its related courses breadth-first up to -max-depth, each into a subdirectory of that.
+func downloadWithRelated(ctx context.Context, opts *options, courseURL string) error {
+	type job struct {
+		url, dir string
+		depth    int
+	}
+	rootSlug, err := courseSlug(opts.learningBase, courseURL)
+	if err != nil {
+		return err
+	}
+	rootDir := filepath.Join(opts.outDir, rootSlug)
+	seen := map[string]struct{}{rootSlug: {}}
+	queue := []job{{url: courseURL, dir: rootDir}}
+	var failed []error
+	for len(queue) > 0 {
+		j := queue[0]
+		queue = queue[1:]
+		if opts.meter.exhausted() {
+			logf(ctx, tr("⛔ -max-bytes reached, not following %d more related course(s).\n"), len(queue)+1)
+			break
+		}
+		if j.depth > 0 {
+			logf(ctx, tr("🔗 Following related course: %s\n"), j.url)
+		}
+		course, err := downloadCourse(ctx, opts, j.url, j.dir)
+		courseHooks(ctx, opts, j.url, j.dir, course, err)
+		if err != nil {
+			if j.depth == 0 {
+				return err
+			}
+			logf(ctx, tr("%v -> skipping."), errorText(err))
 			continue
 		}
-		if dlTranscripts {
-			if err := downloadTranscript(ctx, video, saveJSON); err != nil {
-				log.Printf("%v -> skipping.", err)
+		for _, err := range course.failures {
+			failed = append(failed, err)
+		}
+		if len(course.Related) > 0 {
+			logf(ctx, tr("🔗 Found %d related course(s)\n"), len(course.Related))
+		}
+		if !opts.followRelated || j.depth >= opts.maxDepth {
+			continue
+		}
+		for _, r := range course.Related {
+			slug, err := courseSlug(opts.learningBase, r)
+			if err != nil {
 				continue
 			}
+			if _, ok := seen[slug]; ok {
+				continue
+			}
+			seen[slug] = struct{}{}
+			queue = append(queue, job{url: r, dir: filepath.Join(rootDir, slug), depth: j.depth + 1})
+		}
+	}
+
+	// Related courses live under the root course's directory, so they end up in its archive and upload too.
+	if err := finishCourse(ctx, opts, courseURL, rootDir); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return &itemsError{errs: failed}
+	}
+
+	return nil
+}
+
+// finishCourse checksums, archives, uploads, and exports a downloaded course, as asked for.
+func finishCourse(ctx context.Context, opts *options, courseURL, dir string) error {
+	if err := writeChecksums(ctx, opts, courseURL, dir); err != nil {
+		return err
+	}
+	if err := archiveCourse(ctx, opts, courseURL, dir); err != nil {
+		return err
+	}
+
+	if err := uploadCourse(ctx, opts, courseURL, dir); err != nil {
+		return err
+	}
+
+	return exportCourse(ctx, opts, dir)
+}
+
+// downloadCourse runs the whole pipeline for a single course, saving everything into dir.
+func downloadCourse(ctx context.Context, opts *options, courseURL, dir string) (_ *Course, err error) {
+	ctx, span := startSpan(ctx, "course", "url", courseURL)
+	defer span.end(&err)
+	ctx = withLogFields(ctx, "course", courseURL)
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	// Course details are nice to have, so don't let a layout change on the landing page stop the download.
+	course, err := parseCourse(ctx, courseURL)
+	if err != nil {
+		logf(ctx, tr("⚠️ Failed to parse course details: %v"), err)
+		course = &Course{URL: courseURL}
+	}
+	if courseUpdated(ctx, course, dir) {
+		opts = resyncOptions(ctx, opts)
+	}
+
+	videos, err := parseCourseVideos(ctx, opts, courseURL, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract video links: %w", err)
+	}
+	logf(ctx, tr("🎯 Found %d video(s) across %d sections\n"), len(videos), countSections(videos))
+	for i := range videos {
+		videos[i].Level = course.Level
+		videos[i].Skills = course.Skills
+	}
+	course.Videos = videos
+	if err := writeCourseJSON(ctx, course, dir); err != nil {
+		return nil, err
+	}
+	recordCourse(ctx, opts.artifacts, course)
+
+	todo, err := planVideos(ctx, videos, opts, courseURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := preflight(ctx, opts, todo, dir); err != nil {
+		return nil, err
+	}
+	course.failures = processVideos(ctx, todo, opts)
+	if err := writeFailed(ctx, dir, courseURL, todo, course.failures); err != nil {
+		logln(ctx, errorText(err))
+	}
+	if err := saveReadingStats(ctx, course, videos, dir); err != nil {
+		return nil, err
+	}
+
+	return course, writeCourseFiles(ctx, course, videos, dir, opts)
+}
+
+// resyncOptions are the options for a course that was updated since it was downloaded: with -resync-updated, the TOC
+// is parsed again and only what changed is fetched.
+func resyncOptions(ctx context.Context, opts *options) *options {
+	if !opts.resyncUpdated {
+		logln(ctx, tr("⚠️ Files already saved may be outdated: run again with -resync-updated (or -refresh -sync)."))
+		return opts
+	}
+	logln(ctx, tr("🔄 Re-syncing the updated course."))
+	resync := *opts
+	dl := *opts.dl
+	resync.refresh, resync.sync, dl.sync = true, true, true
+	resync.dl = &dl
+
+	return &resync
+}
+
+// planVideos picks the videos to process, per -resume-from, -only, and -exclude.
+func planVideos(ctx context.Context, videos []VideoEntry, opts *options, courseURL string) ([]VideoEntry, error) {
+	todo := videos
+	// Related courses are numbered differently, so only the course asked for is resumed.
+	if opts.resumeFrom != "" && courseURL == opts.courseURL {
+		i, err := resumeIndex(videos, opts.resumeFrom)
+		if err != nil {
+			return nil, err
+		}
+		logf(ctx, tr("⏩ Resuming from %s: %s\n"), videos[i].Section, videos[i].Title)
+		todo = videos[i:]
+	}
+
+	return filterVideos(ctx, todo, opts), nil
+}
+
+// writeCourseFiles writes the course level files: the optional README.md, TOC.md, player, and summaries, and index.json.
+func writeCourseFiles(ctx context.Context, course *Course, videos []VideoEntry, dir string, opts *options) error {
+	if opts.readme {
+		if err := writeReadme(ctx, course, videos, dir); err != nil {
+			return err
+		}
+	}
+	if opts.toc {
+		if err := writeTOC(ctx, course, videos, dir); err != nil {
+			return err
 		}
-		if dlVideos {
-			if err := downloadVideo(ctx, video); err != nil {
-				log.Printf("%v -> skipping.", err)
+	}
+	if opts.player {
+		if err := writePlayer(ctx, course, videos, dir); err != nil {
+			return err
+		}
+	}
+	if opts.summaries {
+		if err := writeSummaries(ctx, videos, dir); err != nil {
+			return err
+		}
+	}
+
+	return writeIndex(ctx, course, videos, dir)
+}
+
+// processVideos works through the videos in -tabs browser tabs at once, the first being the one we're logged in with.
+// It returns the items that failed, by filename.
+func processVideos(ctx context.Context, videos []VideoEntry, opts *options) map[string]error {
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures = map[string]error{}
+		done     int
+		progress = newETA(videos, opts.window)
+	)
+	for i := range max(opts.tabs, 1) {
+		tabCtx := ctx
+		if i > 0 {
+			var cancel context.CancelFunc
+			tabCtx, cancel = chromedp.NewContext(ctx)
+			if err := prepareBrowser(tabCtx, opts); err != nil {
+				logf(ctx, tr("⚠️ failed to open another tab: %v"), err)
+				cancel()
 				continue
 			}
+			defer cancel() //nolint:gocritic // The tabs stay open until every video is done.
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if opts.hold != nil {
+					opts.hold(tabCtx)
+				}
+				opts.window.wait(tabCtx)
+				itemCtx := withLogFields(tabCtx, "section", videos[j].Section, "video", videos[j].Title)
+				err := withKind(ErrSkipped, errBudget)
+				if !opts.meter.exhausted() {
+					logf(itemCtx, "▶️ [%d/%d] %v: %s \n", j+1, len(videos), videos[j].Section, videos[j].Title)
+					err = processSkippable(itemCtx, j+1, videos[j], opts)
+				}
+				mu.Lock()
+				if err != nil {
+					failures[videos[j].filename] = err
+				}
+				done++
+				logETA(ctx, done, len(videos), progress.finish(videos[j]))
+				if opts.progress != nil {
+					opts.progress(done, len(videos), videos[j], err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range videos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := opts.har.save(); err != nil {
+		logln(ctx, errorText(err))
 	}
+
+	return failures
 }
 
-func downloadTranscript(ctx context.Context, video VideoEntry, saveJSON bool) error {
-	var lines []string
+// processSkippable processes the n-th item in a context of its own, which opts.track is given to cancel, skipping it.
+func processSkippable(ctx context.Context, n int, video VideoEntry, opts *options) error {
+	if opts.track == nil {
+		return processItem(ctx, video, opts)
+	}
+	itemCtx, skip := context.WithCancel(ctx)
+	defer skip()
+	defer opts.track(n, video, skip)()
+
+	err := processItem(itemCtx, video, opts)
+	if err != nil && itemCtx.Err() != nil && ctx.Err() == nil {
+		return withKind(ErrSkipped, err)
+	}
+
+	return err
+}
+
+// processItem downloads a single item and then post-processes what was saved, returning the first failure.
+func processItem(ctx context.Context, video VideoEntry, opts *options) (err error) {
+	ctx, span := startSpan(ctx, "item", "section", video.Section, "title", video.Title, "url", video.Href)
+	defer span.end(&err)
+
+	if err := os.MkdirAll(filepath.Dir(video.filename), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(video.filename), err)
+	}
+	cp := loadCheckpoint(video.filename, opts)
+	ctx = withCheckpoint(ctx, cp)
+	err = processVideo(ctx, video, opts)
+	if err != nil && !errors.Is(err, ErrDRM) { // Already said so.
+		logf(ctx, tr("%v -> skipping."), errorText(err))
+	}
+	if opts.screenshots {
+		saveScreenshot(ctx, video.filename+screenshotExt, err != nil && !errors.Is(err, ErrDRM))
+	}
+	if perr := cp.run(ctx, stagePostProcess, postProcessWith(opts), func() error {
+		return postProcess(ctx, video.filename, opts)
+	}); perr != nil {
+		logln(ctx, errorText(perr))
+		err = cmp.Or(err, perr)
+	}
+	if len(opts.recipients) > 0 {
+		if eerr := encryptFiles(ctx, video.filename, opts.recipients); eerr != nil {
+			logln(ctx, errorText(eerr))
+			err = cmp.Or(err, inStage(stageEncrypt, eerr))
+		}
+	}
+	itemHooks(ctx, video, opts, err)
+
+	return err
+}
+
+// screenshotExt is the suffix of the screenshot of an item's page, taken when it failed with -screenshots.
+const screenshotExt = ".failed.png"
+
+// itemFiles lists the files saved for the item with base name filename: its video, transcript, captions, and so on.
+// The screenshot of its failure isn't one of them, so it's left out of what's indexed, linked, and encrypted.
+func itemFiles(filename string) []string {
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		return nil
+	}
+	prefix := filepath.Base(filename) + "."
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) && !strings.HasSuffix(e.Name(), screenshotExt) {
+			files = append(files, filepath.Join(filepath.Dir(filename), e.Name()))
+		}
+	}
+
+	return files
+}
+
+// saveScreenshot saves a screenshot of the page to filename if failed, or removes the one of a previous failure.
+func saveScreenshot(ctx context.Context, filename string, failed bool) {
+	if !failed {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logln(ctx, errorText(err))
+		}
+		return
+	}
+	var buf []byte
+	if err := chromedp.Run(ctx, chromedp.CaptureScreenshot(&buf)); err != nil {
+		logf(ctx, tr("⚠️ failed to take a screenshot: %v"), err)
+		return
+	}
+	if err := os.WriteFile(filename, buf, 0o600); err != nil {
+		logf(ctx, tr("❌ failed to create file %s: %v"), filename, err)
+		return
+	}
+	logf(ctx, tr("📸 screenshot saved: %s\n"), filename)
+}
+
+// itemHandler saves a TOC item of some type, once its page is open.
+type itemHandler func(ctx context.Context, video VideoEntry, opts *options) error
+
+// itemHandlers route each TOC item type to what saves it. Handouts and quizzes are tiny, so they're saved whichever of
+// -transcripts/-videos was asked for.
+func itemHandlers() map[string]itemHandler {
+	return map[string]itemHandler{
+		itemVideo: processMedia,
+		itemAudio: processMedia,
+		itemDocument: func(ctx context.Context, video VideoEntry, opts *options) error {
+			return checkpointFrom(ctx).run(ctx, stageDocument, "", func() error {
+				return downloadDocument(ctx, video, opts.dl)
+			})
+		},
+		itemQuiz: func(ctx context.Context, video VideoEntry, opts *options) error {
+			return checkpointFrom(ctx).run(ctx, stageQuiz, "", func() error {
+				return downloadQuiz(ctx, video, opts)
+			})
+		},
+	}
+}
+
+// timedItem is whether items of type t play, so have a transcript and media; types lld doesn't know yet (or an empty
+// one, from a TOC cached before types) are taken for videos, which most items are.
+func timedItem(t string) bool {
+	return t != itemDocument && t != itemQuiz
+}
+
+// processVideo saves what was asked for of an item from its page, unless its checkpoint says it's all been saved.
+func processVideo(ctx context.Context, video VideoEntry, opts *options) error {
+	cp := checkpointFrom(ctx)
+	if cp.doneAll(itemStages(video, opts)) {
+		logln(ctx, tr("⏭️ Already saved, skipping (-refresh to redo it)."))
+		return nil
+	}
+	if err := visitVideo(ctx, video, opts.backoff, 0); err != nil {
+		return inStage(stageVisit, fmt.Errorf("failed to visit video: %w", err))
+	}
+	cp.mark(ctx, stageVisit, "")
+	handle, ok := itemHandlers()[video.Type]
+	if !ok {
+		handle = processMedia
+	}
+
+	return handle(ctx, video, opts)
+}
+
+// processMedia saves the transcript and media of a video or audio item, as asked for.
+func processMedia(ctx context.Context, video VideoEntry, opts *options) error {
+	cp := checkpointFrom(ctx)
+	if opts.dlTranscripts {
+		if err := cp.run(ctx, stageTranscript, "", func() error { return downloadTranscript(ctx, video, opts) }); err != nil {
+			return err
+		}
+	}
+	if opts.dlVideos {
+		return cp.run(ctx, stageMedia, "", func() error { return downloadMedia(ctx, video, opts) })
+	}
+
+	return nil
+}
+
+func downloadMedia(ctx context.Context, video VideoEntry, opts *options) error {
+	download := downloadVideo
+	if video.Type == itemAudio {
+		download = downloadAudio
+	}
+	err := download(ctx, video, opts.dl)
+	if !errors.Is(err, ErrDRM) {
+		return err
+	}
+	logf(ctx, tr("🔒 protected content, transcript-only: %s"), video.Title)
+	if !opts.drmTranscripts {
+		return err
+	}
+	// With -transcripts, the transcript stage saved it already; the item is done with it either way.
+	if !opts.dlTranscripts {
+		return downloadTranscript(ctx, video, opts)
+	}
+
+	return nil
+}
+
+// transcriptLinesJS reads the lines of the open transcript.
+const transcriptLinesJS = `sel => Array.from(document.querySelectorAll(sel.transcriptLine)).map(x => x.textContent.trim())`
+
+func downloadTranscript(ctx context.Context, video VideoEntry, opts *options) (err error) {
+	ctx, span := startSpan(ctx, "transcript")
+	defer span.end(&err)
+
+	var (
+		lines  []string
+		button string
+	)
 	if err := chromedp.Run(ctx,
-		chromedp.ScrollIntoView(`button[id*="TRANSCRIPT"]`, chromedp.ByQuery),
-		chromedp.Click(`button[id*="TRANSCRIPT"]`, chromedp.ByQuery),
-		chromedp.Sleep(2*time.Second),
-		chromedp.WaitVisible(`.content-transcript-line`, chromedp.ByQuery),
-		chromedp.Evaluate(`Array.from(document.querySelectorAll('.content-transcript-line')).map(x => x.textContent.trim())`, &lines),
+		waitReady(&button, selTranscriptButton),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return chromedp.Tasks{
+				chromedp.ScrollIntoView(button, chromedp.ByQuery),
+				chromedp.Click(button, chromedp.ByQuery),
+			}.Do(ctx)
+		}),
+		waitSettled(selTranscriptLine),
+		chromedp.Evaluate(pageScript(ctx, jsTranscript), &lines),
 	); err != nil {
-		return fmt.Errorf("⚠️ failed to scrape: %v", err)
+		return layoutError(ctx, fmt.Errorf("failed to scrape: %v", err))
+	}
+	video.Transcript = cleanText(strings.Join(lines, "\n"), opts.cleaners)
+	if opts.punctuator != nil {
+		if text, err := opts.punctuator.punctuate(ctx, video.Transcript); err != nil {
+			logf(ctx, tr("⚠️ failed to punctuate the transcript, keeping it as is: %v"), err)
+		} else {
+			video.Transcript = text
+		}
+	}
+	video.Language = detectLanguage(video.Transcript)
+	video.Words = countWords(video.Transcript)
+	video.ReadingMinutes = readingMinutes(video.Words)
+	// The timed captions are what subtitles and the -player's click-to-seek are made from.
+	if video.Captions = cleanCaptions(scrapeCaptions(ctx), opts.cleaners); len(video.Captions) > 0 {
+		if err := writeVTT(ctx, video.filename+".vtt", video.Captions, video.Language); err != nil {
+			logln(ctx, errorText(err))
+		}
+	}
+	if err := writeTranscript(ctx, video, video.filename, opts); err != nil {
+		return err
+	}
+	if opts.translator != nil {
+		if err := translateTranscript(ctx, video, opts); err != nil {
+			logf(ctx, tr("%v -> skipping."), errorText(err))
+		}
 	}
-	video.Transcript = strings.Join(lines, "\n")
 
+	return nil
+}
+
+// writeTranscript saves video's transcript as base.txt, base.json with -json, or base.jsonl with -json-lines.
+func writeTranscript(ctx context.Context, video VideoEntry, base string, opts *options) error {
 	ext := "txt"
-	if saveJSON {
+	switch {
+	case opts.jsonLines:
+		ext = "jsonl"
+	case opts.saveJSON:
 		ext = "json"
 	}
-	filename := video.filename + "." + ext
+	filename := base + "." + ext
 	f, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("❌ failed to create file %s: %w", filename, err)
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
 	}
 	defer func() {
 		_ = f.Close()
 	}()
 
-	if saveJSON {
-		if err := json.NewEncoder(f).Encode(video); err != nil {
-			return fmt.Errorf("❌ failed to write JSON: %w", err)
+	if ext != "txt" {
+		encode := encodeTranscriptJSON
+		if opts.jsonLines {
+			encode = encodeTranscriptJSONL
+		}
+		if err := encode(f, video); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
 		}
-		log.Printf("💾 transcript saved: %s\n", filename)
+		opts.artifacts.record(ctx, Artifact{Kind: artifactTranscript, Item: &video, File: filename})
+		logf(ctx, tr("💾 transcript saved: %s\n"), filename)
 
 		return nil
 	}
 
-	var sb strings.Builder
-	sb.WriteString("URL: " + video.Href + "\n")
-	sb.WriteString("Section: " + video.Section + "\n")
-	sb.WriteString("Title: " + video.Title + "\n")
-	sb.WriteString("Index: " + strconv.Itoa(video.Index) + "\n")
-	sb.WriteString("Duration: " + video.Duration + "\n")
-	sb.WriteString("Transcript:\n" + video.Transcript + "\n")
-	if _, err := f.WriteString(sb.String()); err != nil {
-		return fmt.Errorf("❌ failed to write transcript: %w", err)
+	if err := opts.transcriptTmpl.Execute(f, video); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
 	}
-	log.Printf("💾 transcript saved: %s\n", filename)
+	if err := writeTranscriptData(base, video); err != nil {
+		return err
+	}
+	opts.artifacts.record(ctx, Artifact{Kind: artifactTranscript, Item: &video, File: filename})
+	logf(ctx, tr("💾 transcript saved: %s\n"), filename)
 
 	return nil
 }
 
-func downloadVideo(ctx context.Context, video VideoEntry) error {
-	var videoURL string
-	if err := chromedp.Run(ctx,
-		chromedp.WaitVisible(`video.vjs-tech`, chromedp.ByQuery),
-		chromedp.AttributeValue(`video.vjs-tech`, "src", &videoURL, nil),
-	); err != nil {
-		return fmt.Errorf("⚠️ failed to find video: %v", err)
-	}
-	if videoURL == "" {
-		return fmt.Errorf("⚠️ empty video URL found")
-	}
+// transcriptDataExt is the suffix of the JSON copy of a transcript saved as .txt, which is what's read back (see
+// loadTranscriptEntry), as the .txt is rendered for people by whatever -transcript-template says.
+const transcriptDataExt = ".transcript.json"
 
-	filename := video.filename + ".mp4"
+func writeTranscriptData(base string, video VideoEntry) error {
+	filename := base + transcriptDataExt
 	f, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("❌ failed to create file %s: %w", filename, err)
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
 	}
 	defer func() {
 		_ = f.Close()
 	}()
+	if err := encodeTranscriptJSON(f, video); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	return nil
+}
+
+// protectedJS reports whether the player is using Encrypted Media Extensions, or is fed from a blob: URL (MSE/DRM).
+const protectedJS = `sel => {
+	const video = document.querySelector(sel.video);
+	if (!video) return false;
+	return !!video.mediaKeys || (video.currentSrc || video.src || "").startsWith("blob:") ||
+		!!document.querySelector(sel.drm);
+}`
+
+// clickToPlayJS clicks the big play button and resolves once the player fires loadedmetadata (or gives up after 15s).
+const clickToPlayJS = `sel => new Promise(resolve => {
+	const video = document.querySelector(sel.video);
+	if (!video) return resolve(false);
+	if (video.readyState >= 1) return resolve(true);
+	video.addEventListener("loadedmetadata", () => { video.pause(); resolve(true); }, { once: true });
+	setTimeout(() => resolve(false), 15000);
+	(document.querySelector(sel.playButton) || video).click();
+})`
+
+const videoSrcJS = `sel => document.querySelector(sel.video)?.currentSrc || document.querySelector(sel.video)?.src || ""`
+
+func awaitPromise(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, videoURL, http.NoBody)
+func downloadVideo(ctx context.Context, video VideoEntry, dl *downloader) error {
+	videoURL, err := videoSource(ctx)
 	if err != nil {
-		return fmt.Errorf("❌ failed to create request: %w", err)
+		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	return dl.download(ctx, &target{
+		url:      videoURL,
+		filename: video.filename + ".mp4",
+		kind:     itemVideo,
+		refresh:  evaluateString(pageScript(ctx, jsVideoSrc)),
+	})
+}
+
+// videoSource finds the URL of the video on the current page, starting playback if that's what it takes.
+func videoSource(ctx context.Context) (string, error) {
+	var (
+		videoURL  string
+		protected bool
+		player    string
+	)
+	if err := chromedp.Run(ctx,
+		waitVisible(selVideo, &player),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return chromedp.AttributeValue(player, "src", &videoURL, nil).Do(ctx)
+		}),
+		chromedp.Evaluate(pageScript(ctx, jsProtected), &protected),
+	); err != nil {
+		return "", layoutError(ctx, fmt.Errorf("failed to find video: %v", err))
+	}
+	if videoURL == "" && !protected {
+		// The source often only attaches once playback starts, so poke the player and look again.
+		logln(ctx, tr("👆 No video source yet, clicking play..."))
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(pageScript(ctx, jsClickToPlay), nil, awaitPromise),
+			chromedp.Evaluate(pageScript(ctx, jsVideoSrc), &videoURL),
+			chromedp.Evaluate(pageScript(ctx, jsProtected), &protected),
+		); err != nil {
+			return "", fmt.Errorf("failed to start playback: %v", err)
+		}
+	}
+	if protected {
+		// EME/DRM backed players can never be fetched.
+		return "", ErrDRM
+	}
+	if videoURL == "" {
+		return "", errors.New("empty video URL found")
+	}
+
+	return videoURL, nil
+}
+
+// audioSrcJS finds the source of the audio-only player, which is either a bare <audio> or the usual video.js element.
+const audioSrcJS = `sel => {
+	const media = document.querySelector(sel.audio) || document.querySelector(sel.video);
+	return media ? (media.currentSrc || media.src || media.querySelector("source")?.src || "") : "";
+}`
+
+func downloadAudio(ctx context.Context, video VideoEntry, dl *downloader) error {
+	var audioURL string
+	if err := chromedp.Run(ctx,
+		waitReady(new(string), selAudio, selVideo),
+		chromedp.Evaluate(pageScript(ctx, jsAudioSrc), &audioURL),
+	); err != nil {
+		return fmt.Errorf("failed to find audio: %v", err)
+	}
+	if audioURL == "" {
+		return errors.New("empty audio URL found")
+	}
+
+	ext := ".m4a"
+	if u, err := url.Parse(audioURL); err == nil && path.Ext(u.Path) != "" {
+		ext = path.Ext(u.Path)
+	}
+
+	return dl.download(ctx, &target{
+		url:      audioURL,
+		filename: video.filename + ext,
+		kind:     itemAudio,
+		refresh:  evaluateString(pageScript(ctx, jsAudioSrc)),
+	})
+}
+
+// documentLinkJS finds the attachment behind a document/handout item, either as a download link or an embedded viewer.
+const documentLinkJS = `sel => {
+	const link = document.querySelector(sel.documentLink);
+	if (link) return link.href;
+	const embed = document.querySelector(sel.documentEmbed);
+	return embed ? (embed.src || embed.data || "") : "";
+}`
+
+func downloadDocument(ctx context.Context, video VideoEntry, dl *downloader) error {
+	var docURL string
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(pageScript(ctx, jsDocumentLink), &docURL),
+	); err != nil {
+		return fmt.Errorf("failed to find document: %v", err)
+	}
+	if docURL == "" {
+		return errors.New("empty document URL found")
+	}
+
+	ext := ".pdf"
+	if u, err := url.Parse(docURL); err == nil && path.Ext(u.Path) != "" {
+		ext = path.Ext(u.Path)
+	}
+	// Unlike the CDN media URLs, attachments are served behind the session, so borrow the browser's cookies.
+	cookies, err := browserCookies(ctx, docURL)
 	if err != nil {
-		return fmt.Errorf("❌ failed to download video: %w", err)
+		return fmt.Errorf("failed to read cookies: %v", err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("❌ server returned status: %s", resp.Status)
+	return dl.download(ctx, &target{
+		url:      docURL,
+		filename: video.filename + ext,
+		kind:     itemDocument,
+		cookies:  cookies,
+		refresh:  evaluateString(pageScript(ctx, jsDocumentLink)),
+	})
+}
+
+// evaluateString returns a func that evaluates js on the current page, for re-extracting expired URLs.
+func evaluateString(js string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		var s string
+		err := chromedp.Run(ctx, chromedp.Evaluate(js, &s))
+
+		return s, err
 	}
+}
 
-	// Copy the response body to the file
-	if _, err = io.Copy(f, resp.Body); err != nil {
-		return fmt.Errorf("❌ failed to save video: %w", err)
+func browserCookies(ctx context.Context, u string) ([]*http.Cookie, error) {
+	var cookies []*network.Cookie
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		cookies, err = network.GetCookies().WithURLs([]string{u}).Do(ctx)
+		return err
+	})); err != nil {
+		return nil, err
 	}
 
-	log.Printf("💾 video saved: %s\n", filename)
+	out := make([]*http.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		out = append(out, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
 
-	return nil
+	return out, nil
 }
 
-func parseCourseVideos(ctx context.Context, courseURL string) ([]VideoEntry, error) {
-	log.Println("📚 Parsing course structure.")
+func parseCourseVideos(ctx context.Context, opts *options, courseURL, dir string) (_ []VideoEntry, err error) {
+	ctx, span := startSpan(ctx, "toc")
+	defer span.end(&err)
+
+	videos, ok := loadCourseCache(ctx, courseURL, opts)
+	if !ok {
+		var err error
+		if videos, err = scrapeCourseVideos(ctx, courseURL); err != nil {
+			return nil, err
+		}
+		if opts.cacheTTL > 0 {
+			if err := saveCourseCache(opts.learningBase, courseURL, videos); err != nil {
+				logf(ctx, tr("⚠️ failed to cache the course structure: %v"), err)
+			}
+		}
+	}
+	setFilenames(videos, dir, opts)
+
+	return videos, nil
+}
+
+// scrapeCourseVideos reads the TOC from the classroom page.
+func scrapeCourseVideos(ctx context.Context, courseURL string) ([]VideoEntry, error) {
+	logln(ctx, tr("📚 Parsing course structure."))
+	harFrom(ctx).startPage(ctx, "Table of contents")
 	var videos []VideoEntry
 	if err := chromedp.Run(ctx,
-		chromedp.Navigate(courseURL),
-		chromedp.WaitVisible(`section.classroom-toc-section`, chromedp.ByQuery),
-		chromedp.Sleep(time.Second),
-		chromedp.Evaluate(videoParseJS, &videos),
+		navigate(courseURL),
+		// Short courses may have items without sections.
+		waitReady(new(string), selTOCSection, selTOCItem),
+		chromedp.Evaluate(pageScript(ctx, jsExpandTOC), nil, awaitPromise),
+		waitSettled(selTOCItem),
+		chromedp.Evaluate(pageScript(ctx, jsParseTOC), &videos),
 	); err != nil {
-		return nil, err
+		return nil, layoutError(ctx, err)
+	}
+	if len(videos) == 0 {
+		return nil, withKind(ErrSelectorChanged, errors.New("no videos found in the course's table of contents"))
 	}
 	for i, v := range videos {
 		// Sigh. Sometimes LinkedIn Learning actually has bad URLs in courses.. catch them early here.
 		u, err := url.Parse(v.Href)
 		if err != nil {
-			return nil, fmt.Errorf("❌ bad url: %w", err)
+			return nil, fmt.Errorf("bad url: %w", err)
 		}
 		u.RawQuery = "" // Remove any query trash at the end.
 		videos[i].Href = u.String()
-		videos[i].filename = sanitizeFileName(fmt.Sprintf("%s.%02d.%s", v.Section, v.Index, v.Title))
+		if v.Section == "" {
+			videos[i].Section = defaultSection
+		}
 	}
 
 	return videos, nil
 }
 
+// login signs in via SSO and then prepares the browser for scraping.
+func login(ctx context.Context, opts *options) (err error) {
+	ctx, span := startSpan(ctx, "login")
+	defer span.end(&err)
+
+	if opts.session || opts.credentialSource != "" {
+		err = sessionLogin(ctx, opts)
+	} else {
+		err = ssoLogin(ctx, opts.ssoURL)
+	}
+	if err != nil {
+		return err
+	}
+	logln(ctx, tr("✅ Logged in."))
+
+	return prepareBrowser(ctx, opts)
+}
+
 func ssoLogin(ctx context.Context, u string) error {
-	log.Println("🚀 Logging in via SSO...")
-	return chromedp.Run(ctx,
-		chromedp.Navigate(u),
-		chromedp.WaitVisible(`h3.chatbot-banner-dynamic__subheading-two`, chromedp.ByQuery),
-	)
+	logln(ctx, tr("🚀 Logging in via SSO..."))
+	// Logging in is up to the user, so there's no telling how long it takes.
+	if err := chromedp.Run(withWaitTimeout(ctx, 0),
+		navigate(u),
+		waitVisible(selLoggedIn, new(string)),
+	); err != nil {
+		return withKind(ErrAuthExpired, fmt.Errorf("failed to log in: %w", err))
+	}
+
+	return nil
 }
 
-func newChromeDPCtx(to time.Duration) (context.Context, context.CancelFunc) {
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", false),
+// newChromeDPCtx starts the browser; a zero timeout leaves the context without a deadline.
+// Every navigation and download made with the context is throttled by the -rps limiter.
+func newChromeDPCtx(to time.Duration, opts *options) (context.Context, context.CancelFunc) {
+	flags := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", opts.headless),
 		chromedp.Flag("disable-gpu", false),
 		chromedp.Flag("start-maximized", true),
 	)
+	flags = append(flags, chromeNetworkFlags(opts)...)
 
-	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	ctx, chromeCancel := chromedp.NewContext(allocCtx)
-	ctx, timeoutCancel := context.WithTimeout(ctx, to)
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), flags...)
+	valuesCtx := withHAR(withSelectors(withLimiter(withLearningBase(allocCtx, opts.learningBase), opts.limiter), opts.selectors), opts.har)
+	ctx, chromeCancel := chromedp.NewContext(withWaitTimeout(valuesCtx, opts.waitTimeout))
+	timeoutCancel := context.CancelFunc(func() {})
+	if to > 0 {
+		ctx, timeoutCancel = context.WithTimeout(ctx, to)
+	}
 
 	// Return a combined cancel function that calls all cancel funcs in reverse order.
 	return ctx, func() {
@@ -270,6 +1340,43 @@ func newChromeDPCtx(to time.Duration) (context.Context, context.CancelFunc) {
 	}
 }
 
+// resumeIndex finds the item -resume-from points at: either SECTION.ITEM, both numbered from 1 in TOC order (so 3.07
+// is the seventh item of the third section), or the item's URL.
+func resumeIndex(videos []VideoEntry, from string) (int, error) {
+	var match func(n int, v VideoEntry) bool
+	if strings.Contains(from, "://") {
+		u, err := url.Parse(from)
+		if err != nil {
+			return 0, fmt.Errorf("bad url: %w", err)
+		}
+		match = func(_ int, v VideoEntry) bool {
+			vu, err := url.Parse(v.Href)
+			return err == nil && strings.TrimSuffix(vu.Path, "/") == strings.TrimSuffix(u.Path, "/")
+		}
+	} else {
+		sec, item, _ := strings.Cut(from, ".")
+		section, err1 := strconv.Atoi(sec)
+		index, err2 := strconv.Atoi(item)
+		if err1 != nil || err2 != nil {
+			return 0, fmt.Errorf("bad -resume-from %q, expected SECTION.ITEM (e.g. 3.07) or a video URL", from)
+		}
+		match = func(n int, v VideoEntry) bool { return n == section && v.Index == index }
+	}
+
+	n, current := 0, ""
+	for i, v := range videos {
+		if v.Section != current || i == 0 {
+			n++
+			current = v.Section
+		}
+		if match(n, v) {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("-resume-from %s isn't in this course", from)
+}
+
 func countSections(videos []VideoEntry) int {
 	seen := make(map[string]struct{})
 	for _, v := range videos {
@@ -282,31 +1389,49 @@ func countSections(videos []VideoEntry) int {
 // Eh. This is a bit of a hack, but LinkedIn Learning has a tendency to rate limit requests if you hit them too fast.
 const maxRetry = 6
 
-func visitVideo(ctx context.Context, href string, backoff time.Duration, count int) error {
+func visitVideo(ctx context.Context, video VideoEntry, backoff time.Duration, count int) (err error) {
+	ctx, span := startSpan(ctx, "visit", "attempt", strconv.Itoa(count+1))
+	defer span.end(&err)
+	ctx = withLogFields(ctx, "attempt", count+1)
+	harFrom(ctx).startPage(ctx, fmt.Sprintf("%s / %s (attempt %d)", video.Section, video.Title, count+1))
+
 	var (
 		rateLimited   bool
 		hasTranscript bool
 	)
 	if err := chromedp.Run(ctx,
-		chromedp.Navigate(href),
-		chromedp.Evaluate(`!!document.querySelector('.error-body')`, &rateLimited),
-		chromedp.Evaluate(`!!document.querySelector("button[id*='TRANSCRIPT']")`, &hasTranscript),
+		navigate(video.Href),
+		chromedp.Evaluate(existsJS(ctx, selRateLimited), &rateLimited),
+		chromedp.Evaluate(existsJS(ctx, selTranscriptButton), &hasTranscript),
 	); err != nil {
 		if count >= maxRetry {
-			return fmt.Errorf("❌ navigation failed, stopping: %w", err)
+			return fmt.Errorf("navigation failed, stopping: %w", err)
 		}
-		log.Printf("❌ navigation failed (%v), retrying\n", err)
+		logf(ctx, tr("❌ navigation failed (%v), retrying\n"), err)
 		time.Sleep(backoff)
 
-		return visitVideo(ctx, href, backoff, count+1)
+		return visitVideo(ctx, video, backoff, count+1)
 	}
 	if rateLimited {
-		log.Println("🚧 Rate limited. Sleeping a minute and retrying...")
+		if count >= maxRetry {
+			return withKind(ErrRateLimited, errors.New("still rate limited, giving up"))
+		}
+		logln(ctx, tr("🚧 Rate limited. Sleeping a minute and retrying..."))
 		time.Sleep(backoff)
-		return visitVideo(ctx, href, backoff, count+1)
-	} else if !hasTranscript {
-		return fmt.Errorf("⏭️ skipping (no transcript): %s", href)
+		return visitVideo(ctx, video, backoff, count+1)
+	} else if !hasTranscript && timedItem(video.Type) {
+		return withKind(ErrNoTranscript, fmt.Errorf("skipping (no transcript): %s", video.Href))
 	}
 
 	return nil
 }
+
+// layoutError marks err, from waiting on or reading the page, as a sign that LinkedIn changed its markup, unless we
+// simply ran out of time.
+func layoutError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	return withKind(ErrSelectorChanged, err)
+}
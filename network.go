@@ -0,0 +1,172 @@
+package lld
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// IP versions for -ip-version.
+const (
+	ipVersionAuto = "auto"
+	ipVersion4    = "4"
+	ipVersion6    = "6"
+)
+
+// setupNetwork applies the network flags.
+func setupNetwork(opts *options) error {
+	switch opts.ipVersion {
+	case ipVersionAuto:
+		opts.ipVersion = ""
+	case ipVersion4, ipVersion6:
+	default:
+		return fmt.Errorf("unsupported -ip-version %q, expected 4, 6, or auto", opts.ipVersion)
+	}
+	if err := setupTLS(opts); err != nil {
+		return err
+	}
+	logProxy(opts.learningBase)
+
+	return nil
+}
+
+// setupTLS applies -ca-bundle and -insecure-skip-verify, for corporate networks that intercept TLS.
+func setupTLS(opts *options) error {
+	if opts.caBundle == "" && !opts.insecure {
+		return nil
+	}
+	opts.tlsConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.insecure, //nolint:gosec // Explicitly asked for.
+	}
+	if opts.caBundle == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(opts.caBundle)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.caBundle, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(b) {
+		return fmt.Errorf("no certificates found in %s", opts.caBundle)
+	}
+	opts.tlsConfig.RootCAs = pool
+	opts.spkiHashes = spkiHashes(b)
+
+	return nil
+}
+
+// spkiHashes returns the base64 SHA-256 of each certificate's public key in the PEM bundle, which is how Chrome takes
+// extra trusted keys (it has no flag for a CA file).
+func spkiHashes(bundle []byte) []string {
+	var hashes []string
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			return hashes
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		hashes = append(hashes, base64.StdEncoding.EncodeToString(sum[:]))
+	}
+}
+
+// proxyEnv returns the proxy environment variable name, preferring the upper case spelling like Go's net/http does.
+func proxyEnv(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+
+	return os.Getenv(strings.ToLower(name))
+}
+
+// logProxy says which proxy, if any, LinkedIn Learning (at base) is reached through.
+func logProxy(base *url.URL) {
+	req, err := http.NewRequest(http.MethodGet, learningHome(base), http.NoBody) //nolint:noctx // Never sent.
+	if err != nil {
+		return
+	}
+	proxy, err := http.ProxyFromEnvironment(req)
+	switch {
+	case err != nil:
+		log.Printf(tr("⚠️ bad proxy settings in the environment: %v\n"), err)
+	case proxy != nil:
+		log.Printf(tr("🌐 Using proxy %s\n"), proxy.Redacted())
+	}
+}
+
+// newHTTPClient is the client for downloads, honoring the network flags and the proxy environment variables.
+func newHTTPClient(opts *options) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // It always is.
+	t.Proxy = http.ProxyFromEnvironment
+	t.TLSClientConfig = opts.tlsConfig
+	t.MaxConnsPerHost = opts.hostConns
+	if opts.ipVersion != "" {
+		// Some networks have IPv6 routes to the CDN that just stall, so let the user pick one family.
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, strings.TrimRight(network, "46")+opts.ipVersion, addr)
+		}
+	}
+
+	return &http.Client{Transport: t}
+}
+
+// chromeNetworkFlags are the browser flags matching the network flags. Chrome only reads the proxy environment
+// variables on some Linux desktops, so they're passed on explicitly.
+func chromeNetworkFlags(opts *options) []chromedp.ExecAllocatorOption {
+	var flags []chromedp.ExecAllocatorOption
+	if proxy := chromeProxyServer(); proxy != "" {
+		flags = append(flags, chromedp.ProxyServer(proxy))
+		if noProxy := proxyEnv("NO_PROXY"); noProxy != "" {
+			flags = append(flags, chromedp.Flag("proxy-bypass-list", strings.ReplaceAll(noProxy, ",", ";")))
+		}
+	}
+	if opts.insecure {
+		flags = append(flags, chromedp.Flag("ignore-certificate-errors", true))
+	}
+	if len(opts.spkiHashes) > 0 {
+		flags = append(flags, chromedp.Flag("ignore-certificate-errors-spki-list", strings.Join(opts.spkiHashes, ",")))
+	}
+
+	return flags
+}
+
+// chromeProxyServer turns HTTP_PROXY and HTTPS_PROXY into Chrome's per-scheme -proxy-server rules.
+func chromeProxyServer() string {
+	var rules []string
+	for _, scheme := range []string{"http", "https"} {
+		proxy := proxyEnv(strings.ToUpper(scheme) + "_PROXY")
+		if proxy == "" {
+			continue
+		}
+		// Chrome doesn't take credentials in the proxy URL; it asks for them instead.
+		if u, err := url.Parse(proxy); err == nil && u.Host != "" {
+			proxy = u.Scheme + "://" + u.Host
+		}
+		rules = append(rules, scheme+"="+proxy)
+	}
+
+	return strings.Join(rules, ";")
+}
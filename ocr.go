@@ -0,0 +1,83 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScreenText is the text read off a video frame, like the code in an editor or a slide.
+type ScreenText struct {
+	Time float64 `json:"time"` // Seconds into the video.
+	Text string  `json:"text"`
+}
+
+// ocrVideo samples a frame of video every interval with ffmpeg, reads the text off each with tesseract, and saves
+// whatever changed from one frame to the next as <name>.ocr.json.
+func ocrVideo(ctx context.Context, filename string, interval time.Duration) error {
+	video := filename + ".mp4"
+	if !exists(video) {
+		return nil
+	}
+	tmp, err := os.MkdirTemp("", "lld-ocr-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+
+	fps := "fps=1/" + strconv.FormatFloat(interval.Seconds(), 'f', -1, 64)
+	if err := runCLI(ctx, nil, "ffmpeg", "-loglevel", "error", "-i", video, "-vf", fps, filepath.Join(tmp, "%05d.png")); err != nil {
+		return fmt.Errorf("failed to sample frames of %s: %w", video, err)
+	}
+	frames, err := filepath.Glob(filepath.Join(tmp, "*.png"))
+	if err != nil {
+		return err
+	}
+
+	var screens []ScreenText
+	for i, frame := range frames {
+		text, err := outputCLI(ctx, "tesseract", frame, "stdout")
+		if err != nil {
+			return fmt.Errorf("failed to OCR %s: %w", video, err)
+		}
+		text = strings.TrimSpace(text)
+		// Slides and editors stay put for a while, so only keep what's new.
+		if text == "" || (len(screens) > 0 && screens[len(screens)-1].Text == text) {
+			continue
+		}
+		screens = append(screens, ScreenText{Time: float64(i) * interval.Seconds(), Text: text})
+	}
+
+	b, err := json.MarshalIndent(screens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	out := filename + ".ocr.json"
+	if err := os.WriteFile(out, b, 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", out, err)
+	}
+	logf(ctx, tr("💾 screen text saved: %s\n"), out)
+
+	return nil
+}
+
+// outputCLI runs an external tool and returns its stdout.
+func outputCLI(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	if err := runCmd(cmd); err != nil {
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,162 @@
+package lld
+
+import (
+	"cmp"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// endpoint is a route of the serve API, described well enough to generate its OpenAPI spec from.
+type endpoint struct {
+	method   string
+	path     string
+	id       string // The operationId.
+	summary  string
+	request  any   // A value of the request body's type, if it takes one.
+	response any   // A value of the success response's type.
+	status   int   // The success status.
+	errors   []int // The error statuses it can respond with, all with an APIError.
+	// contentType is that of the response, if it isn't JSON: for text/event-stream, the events are of the response type.
+	contentType string
+	handler     http.HandlerFunc
+}
+
+var pathParamRE = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPI generates the OpenAPI 3.1 spec of the endpoints, deriving the schemas from the Go types (and their JSON tags).
+func openAPI(endpoints []endpoint) map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]map[string]any{}
+	for _, e := range endpoints {
+		op := map[string]any{
+			"operationId": e.id,
+			"summary":     e.summary,
+			"responses":   responses(e, schemas),
+		}
+		if params := pathParamRE.FindAllStringSubmatch(e.path, -1); len(params) > 0 {
+			list := make([]any, 0, len(params))
+			for _, p := range params {
+				list = append(list, map[string]any{"name": p[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"}})
+			}
+			op["parameters"] = list
+		}
+		if e.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(e.request), schemas)}},
+			}
+		}
+		if paths[e.path] == nil {
+			paths[e.path] = map[string]any{}
+		}
+		paths[e.path][strings.ToLower(e.method)] = op
+	}
+
+	return map[string]any{
+		"openapi":    "3.1.0",
+		"info":       map[string]any{"title": "lld", "version": currentVersion()},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func responses(e endpoint, schemas map[string]any) map[string]any {
+	contentType := cmp.Or(e.contentType, "application/json")
+	schema := schemaOf(reflect.TypeOf(e.response), schemas)
+	if _, ok := e.response.([]byte); ok {
+		schema = map[string]any{"type": "string", "format": "binary"}
+	}
+	resps := map[string]any{
+		strconv.Itoa(e.status): map[string]any{
+			"description": http.StatusText(e.status),
+			"content":     map[string]any{contentType: map[string]any{"schema": schema}},
+		},
+	}
+	// Any of them can fail to reach the -queue, and needs a token once there are any (see serverConfig.Token).
+	for _, status := range append(slices.Clone(e.errors), http.StatusUnauthorized, http.StatusInternalServerError) {
+		resps[strconv.Itoa(status)] = map[string]any{
+			"description": http.StatusText(status),
+			"content":     map[string]any{"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(APIError{}), schemas)}},
+		}
+	}
+
+	return resps
+}
+
+// schemaOf returns the JSON schema of t, adding the structs it refers to to schemas.
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if typ, ok := jsonTypes()[t.Kind()]; ok {
+		return map[string]any{"type": typ}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), schemas)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = nil // Guards against recursion.
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+// jsonTypes maps the kinds of Go values to the JSON schema types they marshal to.
+func jsonTypes() map[reflect.Kind]string {
+	return map[reflect.Kind]string{
+		reflect.Bool:    "boolean",
+		reflect.Int:     "integer",
+		reflect.Int8:    "integer",
+		reflect.Int16:   "integer",
+		reflect.Int32:   "integer",
+		reflect.Int64:   "integer",
+		reflect.Uint:    "integer",
+		reflect.Uint8:   "integer",
+		reflect.Uint16:  "integer",
+		reflect.Uint32:  "integer",
+		reflect.Uint64:  "integer",
+		reflect.Float32: "number",
+		reflect.Float64: "number",
+		reflect.String:  "string",
+	}
+}
+
+// structSchema describes the exported fields of t the way encoding/json marshals them: those without omitempty are
+// always there, so they're required.
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = schemaOf(f.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{"type": "object", "properties": props, "required": required}
+}
+
+func (s *server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, openAPI(s.endpoints()))
+}
@@ -0,0 +1,124 @@
+package lld
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// setupOutput applies the flags that control how messages are rendered; call it right after parsing flags.
+func setupOutput(opts *options) error {
+	if err := setLang(opts.lang); err != nil {
+		return err
+	}
+	if opts.logOutput == nil {
+		opts.logOutput = os.Stderr
+	}
+	f, _ := opts.logOutput.(*os.File)
+	switch {
+	case opts.plain || f == nil || !isTerminal(f):
+		log.SetOutput(newPlainWriter(opts.logOutput))
+	case !opts.noColor && os.Getenv("NO_COLOR") == "":
+		log.SetOutput(&colorWriter{w: opts.logOutput})
+	default:
+		log.SetOutput(opts.logOutput)
+	}
+
+	return setupLogging(opts)
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// plainWriter swaps the emoji that prefix log messages for ASCII tags, for terminals and log aggregators that garble them.
+type plainWriter struct {
+	w io.Writer
+	r *strings.Replacer
+}
+
+func newPlainWriter(w io.Writer) *plainWriter {
+	return &plainWriter{
+		w: w,
+		r: strings.NewReplacer(
+			"✅", "[OK]",
+			"❌", "[ERROR]",
+			"⚠️", "[WARN]",
+			"🙅", "[ERROR]",
+			"🚧", "[RATE-LIMIT]",
+			"⏭️", "[SKIP]",
+			"🔒", "[DRM]",
+			"💾", "[SAVED]",
+			"🎯", "[FOUND]",
+			"▶️", "[VIDEO]",
+			"📦", "[COURSE]",
+			"🔗", "[RELATED]",
+			"🚀", "[LOGIN]",
+			"📚", "[PARSE]",
+			"📖", "[PARSE]",
+			"👤", "[AUTHOR]",
+			"👆", "[PLAY]",
+			"⬜", "[ ]",
+		),
+	}
+}
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	s := strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return -1
+		}
+		return r
+	}, p.r.Replace(string(b)))
+	if _, err := io.WriteString(p.w, s); err != nil {
+		return 0, err
+	}
+
+	// Report the original length, the caller doesn't care that we rewrote it.
+	return len(b), nil
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// colorWriter colors each log line by the status emoji it carries: green for success, yellow for skips, red for failures.
+type colorWriter struct {
+	w io.Writer
+}
+
+func (c *colorWriter) Write(b []byte) (int, error) {
+	color := lineColor(string(b))
+	if color == "" {
+		return c.w.Write(b)
+	}
+	line := strings.TrimSuffix(string(b), "\n")
+	if _, err := io.WriteString(c.w, color+line+ansiReset+"\n"); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+func lineColor(line string) string {
+	switch {
+	case strings.ContainsAny(line, "❌🙅"):
+		return ansiRed
+	case strings.Contains(line, "⚠️"), strings.Contains(line, "⏭️"), strings.ContainsAny(line, "🚧🔒"):
+		return ansiYellow
+	case strings.ContainsAny(line, "✅💾"):
+		return ansiGreen
+	default:
+		return ""
+	}
+}
+
+// isEmoji catches anything left over that the replacer doesn't know about (without touching accented text).
+func isEmoji(r rune) bool {
+	return r == 0xFE0F || (r >= 0x1F000 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) || (r >= 0x2B00 && r <= 0x2BFF)
+}
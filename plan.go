@@ -0,0 +1,165 @@
+package lld
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// minItemLength is what's planned for items without a duration or transcript to go by, like quizzes and handouts.
+const minItemLength = 5 * time.Minute
+
+// StudyDay is a day of a study plan, with the items to get through that day.
+type StudyDay struct {
+	Date   time.Time
+	Items  []IndexItem
+	Length time.Duration
+}
+
+func runPlan(args []string) {
+	var (
+		perDay   int
+		start    string
+		weekdays bool
+		out      string
+		ics      bool
+		at       string
+	)
+	flags := flag.NewFlagSet("plan", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s plan [flags] DIR\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.IntVar(&perDay, "minutes-per-day", 30, "How many minutes a day to study.")
+	flags.StringVar(&start, "start", "", "Day to start on, as YYYY-MM-DD (default today).")
+	flags.BoolVar(&weekdays, "weekdays", false, "Whether or not to only plan study on weekdays.")
+	flags.StringVar(&out, "o", "", "File to write the plan to (default PLAN.md in the course directory).")
+	flags.BoolVar(&ics, "ics", false, "Whether or not to also write the plan as an iCalendar file, next to it as .ics.")
+	flags.StringVar(&at, "at", "18:00", "Time of day to start studying, as HH:MM, for -ics.")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 || perDay <= 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	dir := flags.Arg(0)
+	from := time.Now()
+	if start != "" {
+		var err error
+		if from, err = time.ParseInLocation(time.DateOnly, start, time.Local); err != nil {
+			log.Fatalf(tr("❌ bad -start: %v"), err)
+		}
+	}
+
+	index, err := loadIndex(dir)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	days := planStudy(index.Items, time.Duration(perDay)*time.Minute, from, weekdays)
+	if out == "" {
+		out = filepath.Join(dir, "PLAN.md")
+	}
+	if err := writePlan(index, days, dir, out); err != nil {
+		log.Fatal(errorText(err))
+	}
+	if ics {
+		if err := writePlanICS(index, days, dir, at, strings.TrimSuffix(out, filepath.Ext(out))+".ics"); err != nil {
+			log.Fatal(errorText(err))
+		}
+	}
+	log.Printf(tr("📅 %d day(s) of study, from %s to %s\n"), len(days),
+		days[0].Date.Format(time.DateOnly), days[len(days)-1].Date.Format(time.DateOnly))
+}
+
+// planStudy spreads the items over days of up to perDay, in course order, starting from from. An item never spans
+// days, so one longer than perDay gets a day to itself.
+func planStudy(items []IndexItem, perDay time.Duration, from time.Time, weekdays bool) []StudyDay {
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	skip := func() {
+		for weekdays && (date.Weekday() == time.Saturday || date.Weekday() == time.Sunday) {
+			date = date.AddDate(0, 0, 1)
+		}
+	}
+	skip()
+	days := []StudyDay{{Date: date}}
+	for _, item := range items {
+		length := itemLength(item.VideoEntry)
+		day := &days[len(days)-1]
+		if len(day.Items) > 0 && day.Length+length > perDay {
+			date = date.AddDate(0, 0, 1)
+			skip()
+			days = append(days, StudyDay{Date: date})
+			day = &days[len(days)-1]
+		}
+		day.Items = append(day.Items, item)
+		day.Length += length
+	}
+
+	return days
+}
+
+// itemLength is how long an item takes: its video's duration, or the time to read its transcript.
+func itemLength(v VideoEntry) time.Duration {
+	if d, err := time.ParseDuration(strings.ReplaceAll(v.Duration, " ", "")); err == nil && d > 0 {
+		return d
+	}
+	if v.ReadingMinutes > 0 {
+		return time.Duration(v.ReadingMinutes) * time.Minute
+	}
+
+	return minItemLength
+}
+
+// studyTime formats d to the minute, e.g. "45 min" or "1h 05m".
+func studyTime(d time.Duration) string {
+	m := int(d.Round(time.Minute).Minutes())
+	if m < 60 {
+		return fmt.Sprintf("%d min", max(m, 1))
+	}
+
+	return fmt.Sprintf("%dh %02dm", m/60, m%60)
+}
+
+// mainFile is the file to study an item from: its video or audio, else its transcript, else whatever was saved.
+func mainFile(item IndexItem) (IndexFile, bool) {
+	for _, kind := range []string{itemVideo, itemAudio, "transcript", itemDocument, "page", itemQuiz} {
+		for _, f := range item.Files {
+			if f.Kind == kind && !f.Encrypted {
+				return f, true
+			}
+		}
+	}
+
+	return IndexFile{}, false
+}
+
+// writePlan writes the days as a Markdown checklist, linking each item to its file in dir.
+func writePlan(index *Index, days []StudyDay, dir, out string) error {
+	var total time.Duration
+	for _, d := range days {
+		total += d.Length
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Study plan: %s\n\n<%s>\n\n", index.Title, index.Course)
+	fmt.Fprintf(&sb, "%d items over %d days, %s in all.\n", len(index.Items), len(days), studyTime(total))
+	for _, d := range days {
+		fmt.Fprintf(&sb, "\n## %s (%s)\n\n", d.Date.Format("Mon, Jan 2 2006"), studyTime(d.Length))
+		for _, item := range d.Items {
+			title := item.Title
+			if f, ok := mainFile(item); ok {
+				title = "[" + item.Title + "](" + relPath(filepath.Dir(out), filepath.Join(dir, filepath.FromSlash(f.Path))) + ")"
+			}
+			fmt.Fprintf(&sb, "- [ ] %s: %s (%s)\n", item.Section, title, studyTime(itemLength(item.VideoEntry)))
+		}
+	}
+
+	if err := os.WriteFile(out, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", out, err)
+	}
+	log.Printf(tr("💾 study plan saved: %s\n"), out)
+
+	return nil
+}
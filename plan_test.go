@@ -0,0 +1,105 @@
+package lld_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jh125486/lld"
+)
+
+func TestPlanStudy(t *testing.T) {
+	t.Parallel()
+	// Each item is its title and duration.
+	tests := []struct {
+		name     string
+		items    []string
+		perDay   time.Duration
+		from     string
+		weekdays bool
+		want     []string // Each day as its date and the titles of its items.
+	}{
+		{
+			name:   "one day",
+			items:  []string{"a 10m", "b 4m 30s"},
+			perDay: 30 * time.Minute,
+			from:   "2026-10-14",
+			want:   []string{"2026-10-14 a b"},
+		},
+		{
+			name:   "up to perDay a day",
+			items:  []string{"a 20m", "b 10m", "c 15m", "d 10m"},
+			perDay: 30 * time.Minute,
+			from:   "2026-10-14",
+			want:   []string{"2026-10-14 a b", "2026-10-15 c d"},
+		},
+		{
+			name:   "longer than perDay",
+			items:  []string{"a 5m", "b 45m", "c 5m"},
+			perDay: 30 * time.Minute,
+			from:   "2026-10-14",
+			want:   []string{"2026-10-14 a", "2026-10-15 b", "2026-10-16 c"},
+		},
+		{
+			name:   "without a duration",
+			items:  []string{"a", "b", "c"},
+			perDay: 10 * time.Minute,
+			from:   "2026-10-14",
+			want:   []string{"2026-10-14 a b", "2026-10-15 c"},
+		},
+		{
+			name:   "over the weekend",
+			items:  []string{"a 30m", "b 30m"},
+			perDay: 30 * time.Minute,
+			from:   "2026-10-16",
+			want:   []string{"2026-10-16 a", "2026-10-17 b"},
+		},
+		{
+			name:     "weekdays",
+			items:    []string{"a 30m", "b 30m"},
+			perDay:   30 * time.Minute,
+			from:     "2026-10-16",
+			weekdays: true,
+			want:     []string{"2026-10-16 a", "2026-10-19 b"},
+		},
+		{
+			name:     "weekdays from a Sunday",
+			items:    []string{"a 30m"},
+			perDay:   30 * time.Minute,
+			from:     "2026-10-18",
+			weekdays: true,
+			want:     []string{"2026-10-19 a"},
+		},
+		{
+			name:   "no items",
+			perDay: 30 * time.Minute,
+			from:   "2026-10-14",
+			want:   []string{"2026-10-14"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			items := make([]lld.IndexItem, len(tt.items))
+			for i, item := range tt.items {
+				items[i].Title, items[i].Duration, _ = strings.Cut(item, " ")
+			}
+			from, err := time.Parse(time.DateOnly, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, day := range lld.PlanStudy(items, tt.perDay, from.Add(20*time.Hour), tt.weekdays) {
+				s := day.Date.Format(time.DateOnly)
+				for _, item := range day.Items {
+					s += " " + item.Title
+				}
+				got = append(got, s)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("planStudy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
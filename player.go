@@ -0,0 +1,151 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// playerItem is what the offline player needs to know about each TOC item.
+type playerItem struct {
+	Section string   `json:"section"`
+	Title   string   `json:"title"`
+	Src     string   `json:"src,omitempty"` // Video or audio, relative to the page.
+	Files   []string `json:"files,omitempty"`
+	Cues    []Cue    `json:"cues,omitempty"`
+}
+
+//nolint:gochecknoglobals,lll // Parsed once, and the HTML is easier to follow unwrapped.
+var playerTmpl = template.Must(template.New("player").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font-family: system-ui, sans-serif; display: grid; grid-template-columns: 18rem 1fr 22rem; height: 100vh; }
+nav, #transcript { overflow-y: auto; padding: 0 1rem; }
+nav { background: #f4f4f4; }
+nav h2 { font-size: 0.9rem; margin: 1rem 0 0.25rem; }
+nav a, #transcript p { display: block; padding: 0.25rem; border-radius: 4px; cursor: pointer; color: inherit; text-decoration: none; }
+nav a.current, #transcript p.current { background: #0a66c2; color: #fff; }
+main { padding: 1rem; overflow-y: auto; }
+video, audio { width: 100%; max-height: 70vh; background: #000; }
+</style>
+</head>
+<body>
+<nav id="toc"><h1>{{.Title}}</h1></nav>
+<main><h2 id="title"></h2><div id="media"></div><p id="files"></p></main>
+<div id="transcript"></div>
+<script>
+const items = {{.Items}};
+const toc = document.getElementById("toc");
+let section = null, media = null;
+items.forEach((item, i) => {
+	if (item.section !== section) {
+		section = item.section;
+		toc.insertAdjacentHTML("beforeend", "<h2></h2>");
+		toc.lastChild.textContent = section;
+	}
+	const a = document.createElement("a");
+	a.textContent = item.title;
+	a.href = "#" + (i + 1);
+	toc.appendChild(a);
+});
+
+function show(i) {
+	const item = items[i];
+	if (!item) return;
+	toc.querySelectorAll("a").forEach((a, j) => a.classList.toggle("current", i === j));
+	document.getElementById("title").textContent = item.title;
+	const box = document.getElementById("media");
+	box.replaceChildren();
+	media = null;
+	if (item.src) {
+		media = document.createElement(/\.(m4a|mp3|aac)$/i.test(item.src) ? "audio" : "video");
+		media.controls = true;
+		media.src = item.src;
+		media.addEventListener("ended", () => { location.hash = "#" + (i + 2); });
+		media.addEventListener("timeupdate", highlight);
+		box.appendChild(media);
+	}
+	const files = document.getElementById("files");
+	files.replaceChildren();
+	(item.files || []).forEach(f => {
+		const a = document.createElement("a");
+		a.href = f;
+		a.textContent = f;
+		files.append(a, " ");
+	});
+	const transcript = document.getElementById("transcript");
+	transcript.replaceChildren();
+	(item.cues || []).forEach(c => {
+		const p = document.createElement("p");
+		p.textContent = c.text;
+		p.onclick = () => { if (media) { media.currentTime = c.start; media.play(); } };
+		transcript.appendChild(p);
+	});
+}
+
+function highlight() {
+	const cues = items[current()].cues || [];
+	document.querySelectorAll("#transcript p").forEach((p, j) => {
+		const on = media.currentTime >= cues[j].start && media.currentTime < cues[j].end;
+		if (on && !p.classList.contains("current")) p.scrollIntoView({ block: "center", behavior: "smooth" });
+		p.classList.toggle("current", on);
+	});
+}
+
+const current = () => Math.max(0, (parseInt(location.hash.slice(1), 10) || 1) - 1);
+window.addEventListener("hashchange", () => show(current()));
+show(current());
+</script>
+</body>
+</html>
+`))
+
+// writePlayer writes index.html, a self-contained page for watching the course offline, with the captions beside the
+// video and click-to-seek.
+func writePlayer(ctx context.Context, course *Course, videos []VideoEntry, dir string) error {
+	items := make([]playerItem, 0, len(videos))
+	for _, v := range videos {
+		item := playerItem{Section: v.Section, Title: v.Title}
+		files := itemFiles(v.filename)
+		for _, f := range files {
+			name := relPath(dir, f)
+			if k := fileKind(f); (k == itemVideo || k == itemAudio) && !strings.HasSuffix(f, ageExt) {
+				item.Src = name
+			}
+			item.Files = append(item.Files, name)
+		}
+		// Encrypted captions can't be read back, so those items simply play without them.
+		if cues, err := parseVTT(v.filename + ".vtt"); err == nil {
+			item.Cues = cues
+		}
+		items = append(items, item)
+	}
+
+	title := course.Title
+	if title == "" {
+		title = course.URL
+	}
+	var buf bytes.Buffer
+	if err := playerTmpl.Execute(&buf, struct {
+		Title string
+		Items []playerItem
+	}{title, items}); err != nil {
+		return fmt.Errorf("failed to render player: %w", err)
+	}
+
+	filename := filepath.Join(dir, "index.html")
+	if err := os.WriteFile(filename, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 player saved: %s\n"), filename)
+
+	return nil
+}
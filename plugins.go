@@ -0,0 +1,186 @@
+package lld
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// pluginProtocol is the version of the JSON exchanged with plugins, bumped on incompatible changes.
+const pluginProtocol = 1
+
+// Plugin commands.
+const (
+	pluginDescribe = "describe"
+	pluginExport   = "export"
+)
+
+// PluginRequest is written to a plugin's stdin, as a single JSON object. The plugin is run with the command as its
+// argument too, so a script can tell them apart without parsing.
+type PluginRequest struct {
+	Protocol int    `json:"protocol"`
+	Command  string `json:"command"`         // describe, or export.
+	Dir      string `json:"dir,omitempty"`   // The course directory, for export.
+	Index    *Index `json:"index,omitempty"` // Everything saved in Dir, as in index.json, for export.
+}
+
+// PluginResponse is read from a plugin's stdout, as a single JSON object. Anything it writes to stderr is logged.
+type PluginResponse struct {
+	Name        string `json:"name,omitempty"`        // For describe.
+	Description string `json:"description,omitempty"` // For describe.
+	Message     string `json:"message,omitempty"`     // Logged after export, e.g. "Pushed 12 lessons".
+	URL         string `json:"url,omitempty"`         // Where the course ended up, if anywhere.
+	Error       string `json:"error,omitempty"`       // Set when it failed.
+}
+
+// defaultPluginsDir is where plugins are looked for without -plugins: lld/plugins in the user's config directory.
+func defaultPluginsDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "lld", "plugins")
+}
+
+// findPlugins returns the executables in dir by name, which is their file name without extension.
+func findPlugins(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins in %s: %w", dir, err)
+	}
+	plugins := map[string]string{}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || !executable(info) {
+			continue
+		}
+		plugins[strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))] = filepath.Join(dir, e.Name())
+	}
+
+	return plugins, nil
+}
+
+func executable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return slices.Contains([]string{".exe", ".bat", ".cmd"}, strings.ToLower(filepath.Ext(info.Name())))
+	}
+
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// setupPlugins resolves the -export-to plugins in the -plugins directory.
+func setupPlugins(opts *options) error {
+	if opts.exportTo == "" {
+		return nil
+	}
+	plugins, err := findPlugins(opts.pluginsDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(opts.exportTo, ",") {
+		file, ok := plugins[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("no plugin %q in %s (see lld plugins)", name, opts.pluginsDir)
+		}
+		opts.exporters = append(opts.exporters, file)
+	}
+
+	return nil
+}
+
+// exportCourse hands the course saved in dir to each -export-to plugin.
+func exportCourse(ctx context.Context, opts *options, dir string) error {
+	if len(opts.exporters) == 0 {
+		return nil
+	}
+	index, err := loadIndex(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range opts.exporters {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		logf(ctx, tr("🔌 Exporting with %s\n"), name)
+		resp, err := runPlugin(ctx, file, PluginRequest{Command: pluginExport, Dir: dir, Index: index})
+		if err != nil {
+			return fmt.Errorf("plugin %s failed: %w", name, err)
+		}
+		if resp.Message != "" {
+			logf(ctx, "🔌 %s: %s\n", name, resp.Message)
+		}
+		if resp.URL != "" {
+			logf(ctx, tr("🔗 Exported to %s\n"), resp.URL)
+		}
+	}
+
+	return nil
+}
+
+// runPlugin runs the plugin in file with req on its stdin, returning its response.
+func runPlugin(ctx context.Context, file string, req PluginRequest) (*PluginResponse, error) {
+	req.Protocol = pluginProtocol
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, file, req.Command)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = &stdout
+	cmd.Stderr = logWriter{ctx: ctx}
+	runErr := cmd.Run()
+
+	var resp PluginResponse
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &resp); err != nil {
+		return nil, cmp.Or(runErr, fmt.Errorf("bad response: %w", err))
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	return &resp, runErr
+}
+
+// runPlugins lists the plugins found, with what they say they do.
+func runPlugins(args []string) {
+	var dir string
+	flags := flag.NewFlagSet("plugins", flag.ExitOnError)
+	flags.StringVar(&dir, "plugins", defaultPluginsDir(), "Directory to look for plugins in.")
+	_ = flags.Parse(args)
+
+	plugins, err := findPlugins(dir)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	if len(plugins) == 0 {
+		log.Printf(tr("🔌 No plugins in %s\n"), dir)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, name := range slices.Sorted(maps.Keys(plugins)) {
+		resp, err := runPlugin(ctx, plugins[name], PluginRequest{Command: pluginDescribe})
+		if err != nil {
+			log.Printf("🔌 %s: %v\n", name, err)
+			continue
+		}
+		log.Printf("🔌 %s: %s\n", name, resp.Description)
+	}
+}
@@ -0,0 +1,82 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// postProcess runs the ffmpeg steps asked for on the item saved at filename, once it's done downloading.
+func postProcess(ctx context.Context, filename string, opts *options) error {
+	if opts.ocr > 0 {
+		// Before any subtitles get burned in, which would only muddy the text.
+		if err := ocrVideo(ctx, filename, opts.ocr); err != nil {
+			logln(ctx, errorText(err))
+		}
+	}
+
+	dir, base := filepath.Split(filename)
+	video, subs := base+".mp4", base+".vtt"
+	if !exists(filename+".mp4") || !exists(filename+".vtt") {
+		return nil
+	}
+
+	burn := opts.burnSubs && !burned(filename+".mp4")
+	if burn {
+		// Re-encodes the video with the captions drawn on; the audio is kept as is.
+		if err := ffmpeg(ctx, dir, video, "-i", video, "-vf", "subtitles="+subs, "-c:a", "copy"); err != nil {
+			return fmt.Errorf("failed to burn subtitles into %s: %w", filename+".mp4", err)
+		}
+		logf(ctx, tr("🔥 Subtitles burned in: %s\n"), filename+".mp4")
+	}
+
+	if opts.embedSubs {
+		// Keep every stream as is and add the captions as a selectable (mov_text) subtitle track, replacing the one from
+		// an earlier run, if any.
+		if err := ffmpeg(ctx, dir, video, "-i", video, "-i", subs,
+			"-map", "0", "-map", "-0:s", "-map", "1", "-c", "copy", "-c:s", "mov_text",
+			"-metadata:s:s:0", "language="+iso6392(vttLanguage(filename+".vtt"))); err != nil {
+			return fmt.Errorf("failed to embed subtitles in %s: %w", filename+".mp4", err)
+		}
+		logf(ctx, tr("🎞️ Subtitles embedded: %s\n"), filename+".mp4")
+	}
+	if burn {
+		// Last, so the marker is newer than the video however it was rewritten.
+		return os.WriteFile(burnedMarker(filename+".mp4"), nil, 0o600)
+	}
+
+	return nil
+}
+
+// burnedMarker is a hidden file recording when the subtitles were burned into video.
+func burnedMarker(video string) string {
+	return filepath.Join(filepath.Dir(video), "."+filepath.Base(video)+".burned")
+}
+
+// burned reports whether video already has the subtitles burned in, i.e. it hasn't been downloaded again since.
+func burned(video string) bool {
+	v, err := os.Stat(video)
+	if err != nil {
+		return false
+	}
+	m, err := os.Stat(burnedMarker(video))
+
+	return err == nil && !v.ModTime().After(m.ModTime())
+}
+
+// ffmpeg runs ffmpeg with args in dir, into a temporary file which then replaces out. Names are relative to dir,
+// which keeps them clear of ffmpeg's filter escaping rules.
+func ffmpeg(ctx context.Context, dir, out string, args ...string) error {
+	tmp := "." + out + ".tmp" + filepath.Ext(out)
+	args = append([]string{"-y", "-loglevel", "error"}, args...)
+	cmd := exec.CommandContext(ctx, "ffmpeg", append(args, tmp)...)
+	cmd.Dir = dir
+	if err := runCmd(cmd); err != nil {
+		_ = os.Remove(filepath.Join(dir, tmp))
+		return err
+	}
+
+	return os.Rename(filepath.Join(dir, tmp), filepath.Join(dir, out))
+}
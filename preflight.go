@@ -0,0 +1,139 @@
+package lld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// preflightMargin is how much room is left on top of the estimate, as it's only a guess from a few videos.
+const preflightMargin = 1.1
+
+// preflight estimates how much room the videos about to be downloaded into dir take, from the size of -preflight
+// sampled ones per second of video, and fails if it's more than the free disk space.
+func preflight(ctx context.Context, opts *options, videos []VideoEntry, dir string) error {
+	if !opts.dlVideos || opts.preflight <= 0 {
+		return nil
+	}
+	var media []VideoEntry
+	for _, v := range videos {
+		if timedItem(v.Type) {
+			media = append(media, v)
+		}
+	}
+	perSecond, ok := sampleBitrate(ctx, opts, media)
+	if !ok {
+		logln(ctx, tr("⚠️ Couldn't sample any video to estimate the course's size, skipping the disk space check."))
+		return nil
+	}
+	var length time.Duration
+	var saved int64
+	for _, v := range media {
+		length += itemLength(v)
+		// Files already there get overwritten, so they make room for themselves.
+		if info, err := os.Stat(v.filename + ".mp4"); err == nil {
+			saved += info.Size()
+		}
+	}
+	need := int64(perSecond*length.Seconds()*preflightMargin) - saved
+	free, err := freeSpace(dir)
+	if err != nil {
+		logf(ctx, tr("⚠️ Couldn't tell the free disk space (%v), skipping the disk space check.\n"), err)
+		return nil
+	}
+	logf(ctx, tr("📏 The videos need about %s, and %s is free.\n"), byteSize(need), byteSize(free))
+	if need > free {
+		return fmt.Errorf("not enough disk space in %s: the videos need about %s, but only %s is free "+
+			"(free up some space, or skip the check with -preflight 0)", dir, byteSize(need), byteSize(free))
+	}
+
+	return nil
+}
+
+// sampleBitrate visits up to -preflight of the videos, spread across the course, for the size of their files per second
+// of video.
+func sampleBitrate(ctx context.Context, opts *options, videos []VideoEntry) (float64, bool) {
+	var candidates []VideoEntry
+	for _, v := range videos {
+		if v.Duration != "" && v.Type != itemAudio {
+			candidates = append(candidates, v)
+		}
+	}
+	n := min(opts.preflight, len(candidates))
+	var (
+		size    int64
+		seconds float64
+	)
+	for i := range n {
+		v := candidates[i*len(candidates)/n]
+		logf(ctx, tr("📏 Sampling %s to estimate the course's size\n"), v.Title)
+		var src string
+		err := chromedp.Run(ctx, navigate(v.Href), chromedp.ActionFunc(func(ctx context.Context) (err error) {
+			src, err = videoSource(ctx)
+			return err
+		}))
+		if err != nil {
+			continue
+		}
+		fileSize, err := opts.dl.probeSize(ctx, &target{url: src})
+		if err != nil {
+			continue
+		}
+		size += fileSize
+		seconds += itemLength(v).Seconds()
+	}
+	if seconds == 0 {
+		return 0, false
+	}
+
+	return float64(size) / seconds, true
+}
+
+// freeSpace is how many bytes are available to us on the disk dir is on, as told by df (or PowerShell on Windows).
+func freeSpace(dir string) (int64, error) {
+	if runtime.GOOS == "windows" {
+		out, err := exec.Command("powershell", "-NoProfile", "-Command",
+			"(Get-Item -LiteralPath $args[0]).PSDrive.Free", dir).Output()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	}
+
+	// The POSIX format is a header, then: filesystem, 1024-blocks, used, available, capacity, and mount point.
+	out, err := exec.Command("df", "-Pk", dir).Output()
+	if err != nil {
+		return 0, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 4 {
+		return 0, errors.New("unexpected df output")
+	}
+	kb, err := strconv.ParseInt(fields[3], 10, 64)
+
+	return kb * 1024, err
+}
+
+// byteSize formats n bytes for people, e.g. 1.2 GB.
+func byteSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
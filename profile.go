@@ -0,0 +1,85 @@
+package lld
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+)
+
+// cpuProfile is the -cpu-profile being written, if any.
+var cpuProfile *os.File //nolint:gochecknoglobals // Set once from -cpu-profile.
+
+// setupProfiling starts the -cpu-profile and the -pprof listener, for diagnosing leaks of long runs, e.g. in the
+// browser tabs or download buffers.
+func setupProfiling(opts *options) error {
+	if opts.cpuProfile != "" && cpuProfile == nil {
+		f, err := os.OpenFile(opts.cpuProfile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := rpprof.StartCPUProfile(f); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		cpuProfile = f
+	}
+	if opts.pprofAddr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", opts.pprofAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.pprofAddr, err)
+	}
+	srv := &http.Server{Handler: pprofRoutes(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf(tr("⚠️ pprof server failed: %v"), err)
+		}
+	}()
+	log.Printf(tr("🩺 Serving pprof on http://%s/debug/pprof/\n"), ln.Addr())
+
+	return nil
+}
+
+// pprofRoutes serves the runtime profiles under /debug/pprof/, and the memory stats under /debug/vars.
+func pprofRoutes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return mux
+}
+
+// stopProfiling finishes the -cpu-profile and writes the -mem-profile, before exiting.
+func stopProfiling(opts *options) {
+	if cpuProfile != nil {
+		rpprof.StopCPUProfile()
+		_ = cpuProfile.Close()
+		cpuProfile = nil
+	}
+	if opts.memProfile == "" {
+		return
+	}
+	f, err := os.OpenFile(opts.memProfile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		log.Printf(tr("⚠️ failed to write memory profile: %v"), err)
+		return
+	}
+	defer f.Close()
+	runtime.GC() // Up to date with what's still reachable.
+	if err := rpprof.WriteHeapProfile(f); err != nil {
+		log.Printf(tr("⚠️ failed to write memory profile: %v"), err)
+	}
+}
@@ -0,0 +1,351 @@
+package lld
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// errBadProto is a message that isn't valid protobuf.
+var errBadProto = errors.New("malformed protobuf message")
+
+// Protobuf wire types, of those lld.proto uses, and the fixed size ones to skip.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoBuf builds a protobuf message of lld.proto. Like proto3 does, fields with their zero value are left out.
+type protoBuf []byte
+
+func (b *protoBuf) tag(field, wire int) {
+	*b = binary.AppendUvarint(*b, uint64(field)<<3|uint64(wire))
+}
+
+func (b *protoBuf) varint(field int, v int64) {
+	if v != 0 {
+		b.tag(field, wireVarint)
+		*b = binary.AppendUvarint(*b, uint64(v))
+	}
+}
+
+func (b *protoBuf) bytes(field int, v []byte) {
+	b.tag(field, wireBytes)
+	*b = binary.AppendUvarint(*b, uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *protoBuf) string(field int, s string) {
+	if s != "" {
+		b.bytes(field, []byte(s))
+	}
+}
+
+// time adds t as a google.protobuf.Timestamp, unless it's the zero time.
+func (b *protoBuf) time(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	var ts protoBuf
+	ts.varint(1, t.Unix())
+	ts.varint(2, int64(t.Nanosecond()))
+	b.bytes(field, ts)
+}
+
+// protoFields calls fn with each field of the message b: its number, and its value, a varint or the bytes.
+func protoFields(b []byte, fn func(field int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errBadProto
+		}
+		b = b[n:]
+		var (
+			v    uint64
+			data []byte
+		)
+		switch key & 7 {
+		case wireVarint:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errBadProto
+			}
+		case wireBytes:
+			size, m := binary.Uvarint(b)
+			if m <= 0 || size > uint64(len(b)-m) {
+				return errBadProto
+			}
+			n = m + int(size)
+			data = b[m:n]
+		case wireFixed64:
+			n = 8
+		case wireFixed32:
+			n = 4
+		default:
+			return errBadProto
+		}
+		if n > len(b) {
+			return errBadProto
+		}
+		b = b[n:]
+		if err := fn(int(key>>3), v, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// protoTime reads a google.protobuf.Timestamp.
+func protoTime(b []byte) (time.Time, error) {
+	var secs, nanos int64
+	err := protoFields(b, func(field int, v uint64, _ []byte) error {
+		switch field {
+		case 1:
+			secs = int64(v)
+		case 2:
+			nanos = int64(v)
+		}
+		return nil
+	})
+
+	return time.Unix(secs, nanos).UTC(), err
+}
+
+// protoTimePtr reads a google.protobuf.Timestamp for a field that's nil when it isn't set.
+func protoTimePtr(b []byte) (*time.Time, error) {
+	t, err := protoTime(b)
+	return &t, err
+}
+
+func marshalSubmitRequest(req SubmitRequest) []byte {
+	var b protoBuf
+	b.string(1, req.Course)
+	b.string(2, req.User)
+	b.varint(3, int64(req.Priority))
+
+	return b
+}
+
+func unmarshalSubmitRequest(b []byte) (SubmitRequest, error) {
+	var req SubmitRequest
+	err := protoFields(b, func(field int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			req.Course = string(data)
+		case 2:
+			req.User = string(data)
+		case 3:
+			req.Priority = int(int32(v)) //nolint:gosec // int32 in lld.proto.
+		}
+		return nil
+	})
+
+	return req, err
+}
+
+// marshalID is the message of WatchJobRequest and CancelRequest, which are just a job's ID.
+func marshalID(id string) []byte {
+	var b protoBuf
+	b.string(1, id)
+
+	return b
+}
+
+func unmarshalID(b []byte) (string, error) {
+	var id string
+	err := protoFields(b, func(field int, _ uint64, data []byte) error {
+		if field == 1 {
+			id = string(data)
+		}
+		return nil
+	})
+
+	return id, err
+}
+
+func marshalJobs(jobs []*Job) []byte {
+	var b protoBuf
+	for _, j := range jobs {
+		b.bytes(1, marshalJob(j))
+	}
+
+	return b
+}
+
+func unmarshalJobs(b []byte) ([]Job, error) {
+	var jobs []Job
+	err := protoFields(b, func(field int, _ uint64, data []byte) error {
+		if field != 1 {
+			return nil
+		}
+		j, err := unmarshalJob(data)
+		jobs = append(jobs, j)
+		return err
+	})
+
+	return jobs, err
+}
+
+func marshalJob(j *Job) []byte {
+	var b protoBuf
+	b.string(1, j.ID)
+	b.string(2, j.Course)
+	b.string(3, j.Status)
+	b.string(4, j.Error)
+	b.string(5, j.Code)
+	b.time(6, j.Created)
+	if j.Started != nil {
+		b.time(7, *j.Started)
+	}
+	if j.Finished != nil {
+		b.time(8, *j.Finished)
+	}
+	b.string(9, j.User)
+	b.varint(10, int64(j.Priority))
+	b.varint(11, int64(j.Done))
+	b.varint(12, int64(j.Total))
+	for _, f := range j.Failures {
+		var fb protoBuf
+		fb.string(1, f.Section)
+		fb.string(2, f.Title)
+		fb.string(3, f.Error)
+		fb.string(4, f.ErrorCode)
+		fb.string(5, f.Screenshot)
+		b.bytes(13, fb)
+	}
+	for _, a := range j.Active {
+		var ab protoBuf
+		ab.varint(1, int64(a.N))
+		ab.string(2, a.Section)
+		ab.string(3, a.Title)
+		b.bytes(14, ab)
+	}
+
+	return b
+}
+
+func unmarshalJob(b []byte) (Job, error) {
+	var j Job
+	err := protoFields(b, func(field int, v uint64, data []byte) error {
+		var err error
+		switch field {
+		case 1:
+			j.ID = string(data)
+		case 2:
+			j.Course = string(data)
+		case 3:
+			j.Status = string(data)
+		case 4:
+			j.Error = string(data)
+		case 5:
+			j.Code = string(data)
+		case 6:
+			j.Created, err = protoTime(data)
+		case 7:
+			j.Started, err = protoTimePtr(data)
+		case 8:
+			j.Finished, err = protoTimePtr(data)
+		case 9:
+			j.User = string(data)
+		case 10:
+			j.Priority = int(int32(v)) //nolint:gosec // int32 in lld.proto.
+		case 11:
+			j.Done = int(int32(v)) //nolint:gosec // int32 in lld.proto.
+		case 12:
+			j.Total = int(int32(v)) //nolint:gosec // int32 in lld.proto.
+		case 13:
+			var f JobFailure
+			f, err = unmarshalJobFailure(data)
+			j.Failures = append(j.Failures, f)
+		case 14:
+			var a ActiveItem
+			a, err = unmarshalActiveItem(data)
+			j.Active = append(j.Active, a)
+		}
+		return err
+	})
+
+	return j, err
+}
+
+func unmarshalJobFailure(b []byte) (JobFailure, error) {
+	var f JobFailure
+	err := protoFields(b, func(field int, _ uint64, data []byte) error {
+		switch field {
+		case 1:
+			f.Section = string(data)
+		case 2:
+			f.Title = string(data)
+		case 3:
+			f.Error = string(data)
+		case 4:
+			f.ErrorCode = string(data)
+		case 5:
+			f.Screenshot = string(data)
+		}
+		return nil
+	})
+
+	return f, err
+}
+
+func unmarshalActiveItem(b []byte) (ActiveItem, error) {
+	var a ActiveItem
+	err := protoFields(b, func(field int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			a.N = int(int32(v)) //nolint:gosec // int32 in lld.proto.
+		case 2:
+			a.Section = string(data)
+		case 3:
+			a.Title = string(data)
+		}
+		return nil
+	})
+
+	return a, err
+}
+
+func marshalEvent(ev Event) []byte {
+	var b protoBuf
+	b.time(1, ev.Time)
+	b.string(2, ev.Type)
+	b.string(3, ev.Message)
+	b.string(4, ev.Status)
+	b.varint(5, int64(ev.Done))
+	b.varint(6, int64(ev.Total))
+	b.string(7, ev.Error)
+	b.string(8, ev.ErrorCode)
+
+	return b
+}
+
+func unmarshalEvent(b []byte) (Event, error) {
+	var ev Event
+	err := protoFields(b, func(field int, v uint64, data []byte) error {
+		var err error
+		switch field {
+		case 1:
+			ev.Time, err = protoTime(data)
+		case 2:
+			ev.Type = string(data)
+		case 3:
+			ev.Message = string(data)
+		case 4:
+			ev.Status = string(data)
+		case 5:
+			ev.Done = int(int32(v)) //nolint:gosec // int32 in lld.proto.
+		case 6:
+			ev.Total = int(int32(v)) //nolint:gosec // int32 in lld.proto.
+		case 7:
+			ev.Error = string(data)
+		case 8:
+			ev.ErrorCode = string(data)
+		}
+		return err
+	})
+
+	return ev, err
+}
@@ -0,0 +1,173 @@
+package lld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// -punctuate modes.
+const (
+	punctuateRules = "rules"
+	punctuateLLM   = "llm"
+)
+
+// punctuator turns a transcript's caption fragments into punctuated, properly cased prose for -punctuate.
+type punctuator interface {
+	punctuate(ctx context.Context, text string) (string, error)
+}
+
+// newPunctuator picks the -punctuate mode. The LLM's API key comes from $LLD_LLM_KEY, so it stays out of ps.
+func newPunctuator(mode, endpoint, model string) (punctuator, error) {
+	switch mode {
+	case punctuateRules:
+		return rulePunctuator{}, nil
+	case punctuateLLM:
+		if endpoint == "" || model == "" {
+			return nil, errors.New("-punctuate llm needs -llm-url and -llm-model")
+		}
+		return &llmPunctuator{endpoint: endpoint, model: model, key: os.Getenv("LLD_LLM_KEY")}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported -punctuate %q, expected rules or llm", mode)
+}
+
+// sentencesPerParagraph is how many sentences rulePunctuator puts in a paragraph.
+const sentencesPerParagraph = 5
+
+// rulePunctuator restores what it safely can without a model: sentence breaks where a fragment without punctuation
+// is followed by a capitalized one, capitals at the start of sentences and for "I", and paragraphs.
+type rulePunctuator struct{}
+
+func (rulePunctuator) punctuate(_ context.Context, text string) (string, error) {
+	var words []string
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if n := len(words); n > 0 && !endsSentence(words[n-1]) && startsUpper(fields[0]) && !isPronounI(fields[0]) {
+			words[n-1] = strings.TrimRight(words[n-1], ",;:") + "."
+		}
+		words = append(words, fields...)
+	}
+	if len(words) == 0 {
+		return text, nil
+	}
+	if last := words[len(words)-1]; !endsSentence(last) {
+		words[len(words)-1] = strings.TrimRight(last, ",;:") + "."
+	}
+
+	var sb strings.Builder
+	start, sentences, sep := true, 0, ""
+	for _, w := range words {
+		if start || isPronounI(w) {
+			w = capitalize(w)
+		}
+		sb.WriteString(sep + w)
+		sep = " "
+		if start = endsSentence(w); start {
+			if sentences++; sentences%sentencesPerParagraph == 0 {
+				sep = "\n\n"
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func endsSentence(word string) bool {
+	w := strings.TrimRight(word, `"')]`)
+
+	return w != "" && strings.ContainsRune(".!?", rune(w[len(w)-1]))
+}
+
+func startsUpper(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+
+	return unicode.IsUpper(r)
+}
+
+// isPronounI reports whether word is "I", or a contraction of it like "i'm".
+func isPronounI(word string) bool {
+	w := strings.ToLower(strings.TrimRight(word, ".,!?;:"))
+
+	return w == "i" || strings.HasPrefix(w, "i'") || strings.HasPrefix(w, "i’")
+}
+
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// llmChunk is about how many characters of transcript go in one request, to stay well within context windows.
+const llmChunk = 6000
+
+const punctuatePrompt = "Restore the punctuation, capitalization, and paragraph breaks of this lecture transcript. " +
+	"Do not add, remove, reorder, or change any words. Reply with the transcript only."
+
+// llmPunctuator asks an OpenAI-compatible chat completions endpoint (e.g. a local Ollama or vLLM) to punctuate.
+type llmPunctuator struct {
+	endpoint, model, key string
+}
+
+func (l *llmPunctuator) punctuate(ctx context.Context, text string) (string, error) {
+	var out []string
+	for _, chunk := range chunkLines(text, llmChunk) {
+		var resp struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		req := map[string]any{
+			"model":       l.model,
+			"temperature": 0,
+			"messages": []map[string]string{
+				{"role": "system", "content": punctuatePrompt},
+				{"role": "user", "content": chunk},
+			},
+		}
+		var header http.Header
+		if l.key != "" {
+			header = http.Header{"Authorization": {"Bearer " + l.key}}
+		}
+		if err := postJSON(ctx, l.endpoint, header, req, &resp); err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", errors.New("the LLM sent no reply")
+		}
+		out = append(out, strings.TrimSpace(resp.Choices[0].Message.Content))
+	}
+
+	return strings.Join(out, "\n\n"), nil
+}
+
+// chunkLines splits text into chunks of whole lines, of up to about size characters each.
+func chunkLines(text string, size int) []string {
+	var chunks []string
+	var sb strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if sb.Len() > 0 && sb.Len()+len(line) > size {
+			chunks = append(chunks, sb.String())
+			sb.Reset()
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(line)
+	}
+	if sb.Len() > 0 {
+		chunks = append(chunks, sb.String())
+	}
+
+	return chunks
+}
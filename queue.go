@@ -0,0 +1,414 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// jobQueue is where serve keeps its jobs: in memory for a lone daemon, or in SQLite or Redis to outlive it and to be
+// shared by several daemons, each running the queued jobs it claims first. Stored jobs carry a version, so that
+// concurrent changes don't overwrite each other, see updateJob.
+type jobQueue interface {
+	// add stores a new job, giving it its ID.
+	add(ctx context.Context, j *Job) error
+	// get returns the job with the given ID, or nil.
+	get(ctx context.Context, id string) (*Job, error)
+	// list returns every job, oldest first.
+	list(ctx context.Context) ([]*Job, error)
+	// put saves j, unless it was changed since j was read, returning whether it did.
+	put(ctx context.Context, j *Job) (bool, error)
+}
+
+// redisJobsKey is where the jobs are kept in Redis, see redisQueue.
+const redisJobsKey = "lld:jobs"
+
+var errNoJob = errors.New("no such job")
+
+// conflictError is a change that doesn't apply to the job as it is (anymore).
+type conflictError struct {
+	msg string
+}
+
+func (e *conflictError) Error() string {
+	return e.msg
+}
+
+// newJobQueue opens the -queue: memory, sqlite:FILE, or redis://HOST[:PORT][/DB] (anything redis-cli -u takes).
+func newJobQueue(spec string) (jobQueue, error) {
+	switch {
+	case spec == "" || spec == "memory":
+		return &memQueue{jobs: map[string]storedJob{}}, nil
+	case strings.HasPrefix(spec, "sqlite:"):
+		q := &sqliteQueue{file: strings.TrimPrefix(spec, "sqlite:")}
+		_, err := q.exec(context.Background(), `CREATE TABLE IF NOT EXISTS jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT, version INTEGER NOT NULL, job TEXT NOT NULL)`)
+		return q, err
+	case strings.HasPrefix(spec, "redis://"), strings.HasPrefix(spec, "rediss://"):
+		q, err := newRedisQueue(spec)
+		if err != nil {
+			return nil, err
+		}
+		_, err = q.eval(context.Background(), "return redis.call('PING')", "")
+		return q, err
+	}
+
+	return nil, fmt.Errorf("unsupported queue %q, expected memory, sqlite:FILE, or redis://HOST", spec)
+}
+
+// updateJob applies change to the stored job, over again whenever another change got saved first, until it's saved
+// or change returns an error, e.g. a conflictError as the job isn't in a state it applies to anymore.
+func updateJob(ctx context.Context, q jobQueue, id string, change func(*Job) error) (*Job, error) {
+	for {
+		j, err := q.get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if j == nil {
+			return nil, errNoJob
+		}
+		if err := change(j); err != nil {
+			return nil, err
+		}
+		ok, err := q.put(ctx, j)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return j, nil
+		}
+	}
+}
+
+// storedJob is a job as it's stored, in JSON.
+type storedJob struct {
+	version int
+	data    []byte
+}
+
+func (sj storedJob) job(id string) (*Job, error) {
+	var j Job
+	if err := json.Unmarshal(sj.data, &j); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", id, err)
+	}
+	j.ID, j.version = id, sj.version
+
+	return &j, nil
+}
+
+// memQueue keeps the jobs in memory, for a daemon of its own.
+type memQueue struct {
+	mu   sync.Mutex
+	ids  []string
+	jobs map[string]storedJob
+}
+
+func (q *memQueue) add(_ context.Context, j *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	b, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	j.ID, j.version = strconv.Itoa(len(q.ids)+1), 1
+	q.ids = append(q.ids, j.ID)
+	q.jobs[j.ID] = storedJob{version: j.version, data: b}
+
+	return nil
+}
+
+func (q *memQueue) get(_ context.Context, id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sj, ok := q.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return sj.job(id)
+}
+
+func (q *memQueue) list(_ context.Context) ([]*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]*Job, 0, len(q.ids))
+	for _, id := range q.ids {
+		j, err := q.jobs[id].job(id)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+func (q *memQueue) put(_ context.Context, j *Job) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.jobs[j.ID].version != j.version {
+		return false, nil
+	}
+	b, err := json.Marshal(j)
+	if err != nil {
+		return false, err
+	}
+	j.version++
+	q.jobs[j.ID] = storedJob{version: j.version, data: b}
+
+	return true, nil
+}
+
+// sqliteQueue keeps the jobs in a SQLite database, through the sqlite3 CLI; several daemons on the same machine (or
+// sharing a filesystem with working locks) can use it.
+type sqliteQueue struct {
+	file string
+}
+
+// exec runs SQL, returning the rows of the last statement.
+func (q *sqliteQueue) exec(ctx context.Context, sql string) ([]map[string]any, error) {
+	var stdout bytes.Buffer
+	// Fed through stdin, as the jobs can get long.
+	cmd := exec.CommandContext(ctx, "sqlite3", "-json", "-cmd", ".timeout 5000", q.file)
+	cmd.Stdin = strings.NewReader(sql + ";\n")
+	cmd.Stdout = &stdout
+	if err := runCmd(cmd); err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", q.file, err)
+	}
+	var rows []map[string]any
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err := json.Unmarshal(out, &rows); err != nil {
+			return nil, fmt.Errorf("failed to query %s: %w", q.file, err)
+		}
+	}
+
+	return rows, nil
+}
+
+// sqlQuote quotes s as an SQL string literal.
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (q *sqliteQueue) add(ctx context.Context, j *Job) error {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	rows, err := q.exec(ctx, "INSERT INTO jobs (version, job) VALUES (1, "+sqlQuote(string(b))+") RETURNING id")
+	if err != nil {
+		return err
+	}
+	if len(rows) != 1 {
+		return fmt.Errorf("failed to query %s: %w", q.file, errNoJob)
+	}
+	j.ID, j.version = fmt.Sprint(rows[0]["id"]), 1
+
+	return nil
+}
+
+func (q *sqliteQueue) get(ctx context.Context, id string) (*Job, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, nil //nolint:nilerr // A job ID is always a number.
+	}
+	jobs, err := q.query(ctx, "WHERE id = "+strconv.Itoa(n))
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	return jobs[0], nil
+}
+
+func (q *sqliteQueue) list(ctx context.Context) ([]*Job, error) {
+	return q.query(ctx, "ORDER BY id")
+}
+
+func (q *sqliteQueue) query(ctx context.Context, clause string) ([]*Job, error) {
+	rows, err := q.exec(ctx, "SELECT id, version, job FROM jobs "+clause)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(rows))
+	for _, row := range rows {
+		version, _ := row["version"].(float64)
+		data, _ := row["job"].(string)
+		j, err := storedJob{version: int(version), data: []byte(data)}.job(fmt.Sprint(row["id"]))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+func (q *sqliteQueue) put(ctx context.Context, j *Job) (bool, error) {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return false, err
+	}
+	rows, err := q.exec(ctx, fmt.Sprintf("UPDATE jobs SET version = version + 1, job = %s WHERE id = %d AND version = %d RETURNING id",
+		sqlQuote(string(b)), atoi(j.ID), j.version))
+	if err != nil || len(rows) == 0 {
+		return false, err
+	}
+	j.version++
+
+	return true, nil
+}
+
+// atoi is strconv.Atoi for what's known to be a number.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// redisQueue keeps the jobs in Redis, through redis-cli, for daemons on several machines to share: the job IDs are
+// listed in lld:jobs, and each job is a hash of its version and JSON at lld:jobs:ID.
+type redisQueue struct {
+	url      string // Without its credentials, which are kept apart.
+	user     string
+	password string
+}
+
+// newRedisQueue splits the credentials off the -queue URL, as redis-cli's command line is for anyone to see in the
+// process list. A URL with just one of them, like redis://secret@host, has the password, as redis-cli takes it.
+func newRedisQueue(spec string) (*redisQueue, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported queue %q, expected memory, sqlite:FILE, or redis://HOST", spec)
+	}
+	q := &redisQueue{user: u.User.Username()}
+	if password, ok := u.User.Password(); ok {
+		q.password = password
+	} else {
+		q.user, q.password = "", q.user
+	}
+	u.User = nil
+	q.url = u.String()
+
+	return q, nil
+}
+
+// eval runs a Lua script on the server, which makes each operation atomic, returning its reply line by line. A job's
+// JSON, if given, goes last in ARGV through stdin, and the password through the environment, both out of the process
+// list.
+func (q *redisQueue) eval(ctx context.Context, script, job string, args ...string) ([]string, error) {
+	argv := []string{"-u", q.url}
+	if q.user != "" {
+		argv = append(argv, "--user", q.user)
+	}
+	if job != "" {
+		argv = append(argv, "-x")
+	}
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "redis-cli", append(append(argv, "EVAL", script, "1", redisJobsKey), args...)...)
+	cmd.Stdin = strings.NewReader(job)
+	cmd.Stdout = &stdout
+	if q.password != "" {
+		cmd.Env = append(os.Environ(), "REDISCLI_AUTH="+q.password)
+	}
+	if err := runCmd(cmd); err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", q.url, err)
+	}
+	out := stdout.String()
+	// redis-cli doesn't always exit with an error for error replies.
+	if strings.HasPrefix(out, "ERR") || strings.HasPrefix(out, "(error)") {
+		return nil, fmt.Errorf("failed to query %s: %s", q.url, strings.TrimSpace(out))
+	}
+	out = strings.TrimSuffix(out, "\n")
+	if out == "" {
+		return nil, nil
+	}
+
+	return strings.Split(out, "\n"), nil
+}
+
+func (q *redisQueue) add(ctx context.Context, j *Job) error {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	lines, err := q.eval(ctx, `local id = redis.call('INCR', KEYS[1] .. ':next')
+redis.call('HSET', KEYS[1] .. ':' .. id, 'version', 1, 'job', ARGV[1])
+redis.call('RPUSH', KEYS[1], id)
+return id`, string(b))
+	if err != nil {
+		return err
+	}
+	if len(lines) != 1 {
+		return fmt.Errorf("failed to query %s: %w", q.url, errNoJob)
+	}
+	j.ID, j.version = lines[0], 1
+
+	return nil
+}
+
+func (q *redisQueue) get(ctx context.Context, id string) (*Job, error) {
+	jobs, err := q.query(ctx, `local ids = {ARGV[1]}`, id)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	return jobs[0], nil
+}
+
+func (q *redisQueue) list(ctx context.Context) ([]*Job, error) {
+	return q.query(ctx, `local ids = redis.call('LRANGE', KEYS[1], 0, -1)`)
+}
+
+// query returns the jobs with the ids a script prefix picks, replied as their ID, version, and JSON in turn.
+func (q *redisQueue) query(ctx context.Context, ids string, args ...string) ([]*Job, error) {
+	lines, err := q.eval(ctx, ids+`
+local reply = {}
+for _, id in ipairs(ids) do
+	local job = redis.call('HMGET', KEYS[1] .. ':' .. id, 'version', 'job')
+	if job[1] then
+		table.insert(reply, id)
+		table.insert(reply, job[1])
+		table.insert(reply, job[2])
+	end
+end
+return reply`, "", args...)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(lines)/3)
+	for i := 0; i+2 < len(lines); i += 3 {
+		j, err := storedJob{version: atoi(lines[i+1]), data: []byte(lines[i+2])}.job(lines[i])
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+func (q *redisQueue) put(ctx context.Context, j *Job) (bool, error) {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return false, err
+	}
+	lines, err := q.eval(ctx, `local key = KEYS[1] .. ':' .. ARGV[1]
+if redis.call('HGET', key, 'version') ~= ARGV[2] then
+	return 0
+end
+redis.call('HSET', key, 'version', tonumber(ARGV[2]) + 1, 'job', ARGV[3])
+return 1`, string(b), j.ID, strconv.Itoa(j.version))
+	if err != nil || len(lines) != 1 || lines[0] != "1" {
+		return false, err
+	}
+	j.version++
+
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package lld_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+func TestUpdateJob(t *testing.T) {
+	t.Parallel()
+	errChange := errors.New("change failed")
+	tests := []struct {
+		name         string
+		id           string
+		interfere    bool  // Whether another change is saved while the first is being made.
+		changeErr    error // What the change returns.
+		wantErr      error
+		wantCalls    int
+		wantStatus   string
+		wantPriority int
+	}{
+		{name: "saved", id: "1", wantCalls: 1, wantStatus: "running"},
+		{name: "retried after another change", id: "1", interfere: true, wantCalls: 2, wantStatus: "running", wantPriority: 5},
+		{name: "change fails", id: "1", changeErr: errChange, wantErr: errChange, wantCalls: 1, wantStatus: "queued"},
+		{name: "no such job", id: "2", wantErr: lld.ErrNoJob, wantStatus: "queued"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+			q, err := lld.NewJobQueue("memory")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := lld.AddJob(ctx, q, &lld.Job{Course: "go-essential-training", Status: "queued"}); err != nil {
+				t.Fatal(err)
+			}
+
+			calls := 0
+			j, err := lld.UpdateJob(ctx, q, tt.id, func(j *lld.Job) error {
+				calls++
+				if tt.interfere && calls == 1 {
+					if _, err := lld.UpdateJob(ctx, q, j.ID, func(j *lld.Job) error {
+						j.Priority = 5
+						return nil
+					}); err != nil {
+						t.Fatal(err)
+					}
+				}
+				j.Status = "running"
+				return tt.changeErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("change called %d times, want %d", calls, tt.wantCalls)
+			}
+			if err == nil && (j.Status != tt.wantStatus || j.Priority != tt.wantPriority) {
+				t.Errorf("UpdateJob() = %s, priority %d, want %s, priority %d", j.Status, j.Priority, tt.wantStatus, tt.wantPriority)
+			}
+
+			stored, err := lld.GetJob(ctx, q, "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.wantStatus || stored.Priority != tt.wantPriority {
+				t.Errorf("stored job = %s, priority %d, want %s, priority %d", stored.Status, stored.Priority, tt.wantStatus, tt.wantPriority)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// QuizQuestion is a single question of a chapter quiz. Answer and Explanation are only known once the quiz has been
+// taken, since that's when LinkedIn Learning marks them.
+type QuizQuestion struct {
+	Question    string   `json:"question"`
+	Choices     []string `json:"choices"`
+	Answer      string   `json:"answer,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+}
+
+const quizParseJS = `() => {
+	const text = el => (el?.innerText || "").trim();
+	return Array.from(document.querySelectorAll('[class*="quiz-question"]:not([class*="quiz-question__"])'))
+		.map(q => ({
+			question: text(q.querySelector('[class*="question__header"], [class*="question__title"], legend, h2, h3')),
+			choices: Array.from(q.querySelectorAll('[class*="question__option"], label')).map(text).filter(Boolean),
+			answer: text(q.querySelector('[class*="--correct"], [class*="correct-answer"]')),
+			explanation: text(q.querySelector('[class*="feedback"], [class*="explanation"]')),
+		}))
+		.filter(q => q.question);
+}`
+
+// downloadQuiz saves the questions of a chapter quiz as <name>.quiz.json, and as <name>.flashcards.md to practice with.
+func downloadQuiz(ctx context.Context, video VideoEntry, opts *options) error {
+	var questions []QuizQuestion
+	// The questions render after the page loads, and a quiz without any is reported below.
+	if err := poll(ctx, "quiz", func(ctx context.Context) (bool, error) {
+		err := chromedp.Evaluate(pageScript(ctx, jsParseQuiz), &questions).Do(ctx)
+		return len(questions) > 0, err
+	}); err != nil && !errors.Is(err, errWaitTimeout) {
+		return fmt.Errorf("failed to scrape: %v", err)
+	}
+	if len(questions) == 0 {
+		return fmt.Errorf("skipping (no quiz questions found): %s", video.Href)
+	}
+
+	b, err := json.MarshalIndent(questions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	filename := video.filename + ".quiz.json"
+	if err := os.WriteFile(filename, b, 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 quiz saved: %s\n"), filename)
+	opts.artifacts.record(ctx, Artifact{Kind: artifactQuiz, Item: &video, Quiz: questions, File: filename})
+
+	filename = video.filename + ".flashcards.md"
+	if err := os.WriteFile(filename, []byte(flashcards(video, questions)), 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 flashcards saved: %s\n"), filename)
+
+	return nil
+}
+
+// flashcards renders the questions as Q&A Markdown, with each answer folded away until clicked.
+func flashcards(video VideoEntry, questions []QuizQuestion) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s: %s\n", video.Section, video.Title)
+	for i, q := range questions {
+		fmt.Fprintf(&sb, "\n## Q%d. %s\n\n", i+1, q.Question)
+		for j, c := range q.Choices {
+			fmt.Fprintf(&sb, "%c. %s\n", 'A'+j, c)
+		}
+		answer := q.Answer
+		if answer == "" {
+			answer = "_Not known yet: take the quiz once, then download it again._"
+		}
+		sb.WriteString("\n<details><summary>Answer</summary>\n\n" + answer + "\n")
+		if q.Explanation != "" {
+			sb.WriteString("\n" + q.Explanation + "\n")
+		}
+		sb.WriteString("\n</details>\n")
+	}
+
+	return sb.String()
+}
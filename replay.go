@@ -0,0 +1,322 @@
+package lld
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// replayMaxMisses is how many of the requests that weren't in the fixtures are listed after a replay.
+const replayMaxMisses = 20
+
+// replayer answers the browser's requests from recorded fixtures instead of the network.
+type replayer struct {
+	mu      sync.Mutex
+	byURL   map[string][]*HAREntry // By method and URL, in the order they were recorded.
+	byPath  map[string][]*HAREntry // By method and URL without the query, for requests whose query varies.
+	served  map[string]int         // How many of each key's entries have been served.
+	misses  []string
+	courses []string // The URLs of recorded tables of contents.
+}
+
+func newReplayer() *replayer {
+	return &replayer{byURL: map[string][]*HAREntry{}, byPath: map[string][]*HAREntry{}, served: map[string]int{}}
+}
+
+// loadFixture adds a fixture: a HAR file as written by -har, or URL=FILE to serve an HTML file (say, a page saved from
+// the browser) at URL.
+func (r *replayer) loadFixture(fixture string) error {
+	if u, file, ok := strings.Cut(fixture, "="); ok && strings.Contains(u, "://") {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		r.add(&HAREntry{
+			Request: HARRequest{Method: http.MethodGet, URL: u},
+			Response: HARResponse{
+				Status:  http.StatusOK,
+				Headers: []HARNameVal{{Name: "Content-Type", Value: "text/html; charset=utf-8"}},
+				Content: HARContent{MimeType: "text/html", Text: string(b)},
+			},
+		})
+
+		return nil
+	}
+
+	b, err := os.ReadFile(fixture)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", fixture, err)
+	}
+	var har HAR
+	if err := json.Unmarshal(b, &har); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", fixture, err)
+	}
+	tocPages := map[string]bool{}
+	for _, p := range har.Log.Pages {
+		tocPages[p.ID] = p.Title == "Table of contents"
+	}
+	for i := range har.Log.Entries {
+		e := &har.Log.Entries[i]
+		if e.Error != "" {
+			continue
+		}
+		r.add(e)
+		if tocPages[e.PageRef] && e.ResourceType == string(network.ResourceTypeDocument) {
+			r.courses = append(r.courses, e.Request.URL)
+		}
+	}
+
+	return nil
+}
+
+func (r *replayer) add(e *HAREntry) {
+	key, path := e.Request.Method+" "+e.Request.URL, e.Request.Method+" "+withoutQuery(e.Request.URL)
+	r.byURL[key] = append(r.byURL[key], e)
+	r.byPath[path] = append(r.byPath[path], e)
+}
+
+func withoutQuery(u string) string {
+	u, _, _ = strings.Cut(u, "#")
+	u, _, _ = strings.Cut(u, "?")
+
+	return u
+}
+
+// match returns the recorded response for a request: the next one recorded for its URL, or failing that for its URL
+// without the query, the last one repeating once they've all been served (e.g. for retries).
+func (r *replayer) match(method, u string) *HAREntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entries := r.byURL[method+" "+u]; len(entries) > 0 {
+		return r.next("url "+method+" "+u, entries)
+	}
+	if entries := r.byPath[method+" "+withoutQuery(u)]; len(entries) > 0 {
+		return r.next("path "+method+" "+withoutQuery(u), entries)
+	}
+	if len(r.misses) < replayMaxMisses && !slices.Contains(r.misses, method+" "+u) {
+		r.misses = append(r.misses, method+" "+u)
+	}
+
+	return nil
+}
+
+// missed returns the requests that weren't in the fixtures, up to replayMaxMisses.
+func (r *replayer) missed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.misses)
+}
+
+func (r *replayer) next(key string, entries []*HAREntry) *HAREntry {
+	i := min(r.served[key], len(entries)-1)
+	r.served[key]++
+
+	return entries[i]
+}
+
+// serve answers every request of the tab of ctx from the fixtures, failing those that aren't in them.
+func (r *replayer) serve(ctx context.Context) error {
+	chromedp.ListenTarget(ctx, func(ev any) {
+		e, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		go func() {
+			entry := r.match(e.Request.Method, e.Request.URL+e.Request.URLFragment)
+			if entry == nil {
+				_ = chromedp.Run(ctx, fetch.FailRequest(e.RequestID, network.ErrorReasonInternetDisconnected))
+				return
+			}
+			_ = chromedp.Run(ctx, fulfill(e.RequestID, entry))
+		}()
+	})
+
+	return chromedp.Run(ctx, fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}}))
+}
+
+// fulfill responds to the paused request with the recorded response.
+func fulfill(id fetch.RequestID, entry *HAREntry) chromedp.Action {
+	headers := make([]*fetch.HeaderEntry, 0, len(entry.Response.Headers))
+	for _, h := range entry.Response.Headers {
+		// The recorded body is already decoded, and its length may differ from what was sent.
+		if slices.Contains([]string{"content-encoding", "content-length"}, strings.ToLower(h.Name)) || h.Value == "REDACTED" {
+			continue
+		}
+		headers = append(headers, &fetch.HeaderEntry{Name: h.Name, Value: h.Value})
+	}
+	status := entry.Response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return fetch.FulfillRequest(id, status).
+		WithResponseHeaders(headers).
+		WithBody(base64.StdEncoding.EncodeToString([]byte(entry.Response.Content.Text)))
+}
+
+func runReplay(args []string) {
+	var (
+		opts             options
+		courseURL        string
+		golden           string
+		update, explicit bool
+	)
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s replay [flags] FIXTURE...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	registerFlags(flags, &opts)
+	flags.StringVar(&courseURL, "course", "", "Course to replay (default the one whose table of contents was recorded).")
+	flags.StringVar(&golden, "golden", "", "index.json to compare what was extracted with, exiting with 1 when it differs.")
+	flags.BoolVar(&update, "update", false, "Whether or not to write what was extracted to -golden instead of comparing.")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	flags.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "output" })
+
+	r := newReplayer()
+	for _, fixture := range flags.Args() {
+		if err := r.loadFixture(fixture); err != nil {
+			log.Fatal(errorText(err))
+		}
+	}
+	if courseURL == "" && len(r.courses) > 0 {
+		courseURL = r.courses[0]
+	}
+	if courseURL == "" {
+		log.Fatal(tr("❌ no table of contents in the fixtures: give the course with -course"))
+	}
+	if !explicit {
+		dir, err := os.MkdirTemp("", "lld-replay-")
+		if err != nil {
+			log.Fatal(errorText(err))
+		}
+		opts.outDir = dir
+	}
+	if err := setup(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+	defer shutdownTracing()
+	defer stopProfiling(&opts)
+	// Only the fixtures are replayed, so the cached TOC mustn't stand in for them, nor be overwritten by them.
+	opts.replay, opts.cacheTTL = r, 0
+
+	err := replay(&opts, courseURL, golden, update)
+	if !explicit {
+		_ = os.RemoveAll(opts.outDir)
+	}
+	if err != nil {
+		log.Print(errorText(err))
+		os.Exit(1)
+	}
+}
+
+// replay downloads courseURL with every request answered from opts.replay, then checks what was extracted against
+// golden (or writes it there with update).
+func replay(opts *options, courseURL, golden string, update bool) error {
+	ctx, cancel := newChromeDPCtx(opts.timeout, opts)
+	defer cancel()
+	if err := prepareBrowser(ctx, opts); err != nil {
+		return err
+	}
+	logf(ctx, tr("📼 Replaying %s\n"), courseURL)
+	course, err := downloadCourse(ctx, opts, courseURL, opts.outDir)
+	if misses := opts.replay.missed(); len(misses) > 0 {
+		logf(ctx, tr("⚠️ Not in the fixtures (so failed): %s\n"), strings.Join(misses, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	index, err := loadIndex(opts.outDir)
+	if err != nil {
+		return err
+	}
+	normalizeIndex(index)
+	logf(ctx, tr("✅ Extracted %d item(s), %d failed\n"), len(index.Items), len(course.failures))
+
+	switch {
+	case golden == "":
+		return nil
+	case update:
+		if err := writeJSONFile(golden, index); err != nil {
+			return err
+		}
+		logf(ctx, tr("📝 Wrote %s\n"), golden)
+		return nil
+	}
+	want, err := readGolden(golden)
+	if err != nil {
+		return err
+	}
+
+	return compareIndex(want, index)
+}
+
+func readGolden(filename string) (*Index, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	var index Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return &index, nil
+}
+
+// normalizeIndex clears what differs between otherwise identical runs: when, and where, they ran.
+func normalizeIndex(index *Index) {
+	index.Generated = time.Time{}
+	for i := range index.Files {
+		index.Files[i].Modified = time.Time{}
+	}
+	for i := range index.Items {
+		for j := range index.Items[i].Files {
+			index.Items[i].Files[j].Modified = time.Time{}
+		}
+	}
+}
+
+// compareIndex logs every item of got that differs from want, returning an error if any do.
+func compareIndex(want, got *Index) error {
+	normalizeIndex(want)
+	var diffs []string
+	if want.Title != got.Title || want.Course != got.Course {
+		diffs = append(diffs, fmt.Sprintf(tr("course: want %q (%s), got %q (%s)"), want.Title, want.Course, got.Title, got.Course))
+	}
+	if len(want.Items) != len(got.Items) {
+		diffs = append(diffs, fmt.Sprintf(tr("items: want %d, got %d"), len(want.Items), len(got.Items)))
+	}
+	for i := range min(len(want.Items), len(got.Items)) {
+		w, _ := json.Marshal(want.Items[i])
+		g, _ := json.Marshal(got.Items[i])
+		if string(w) != string(g) {
+			diffs = append(diffs, fmt.Sprintf(tr("item %d (%s):\n  want %s\n  got  %s"), i+1, got.Items[i].Title, w, g))
+		}
+	}
+	if len(diffs) > 0 {
+		return errors.New("replay differs from the golden index:\n" + strings.Join(diffs, "\n"))
+	}
+	log.Println(tr("✅ Replay matches the golden index."))
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// failedFileName is where a course's failed items are listed, for `lld retry`.
+const failedFileName = "failed.json"
+
+// Failed is failed.json.
+type Failed struct {
+	Course   string    `json:"course"`
+	Failures []Failure `json:"failures"`
+}
+
+// Failure is an item that couldn't be saved. File is the item's path in the course directory, without extension.
+type Failure struct {
+	Video     VideoEntry `json:"video"`
+	File      string     `json:"file"`
+	Stage     string     `json:"stage,omitempty"`
+	ErrorCode string     `json:"errorCode"` // See errorKinds.
+	Error     string     `json:"error"`
+	Attempts  int        `json:"attempts"`
+}
+
+// writeFailed lists the failed items of videos in dir's failed.json, counting the attempts across runs, or removes it
+// once nothing failed.
+func writeFailed(ctx context.Context, dir, courseURL string, videos []VideoEntry, failures map[string]error) error {
+	filename := filepath.Join(dir, failedFileName)
+	if len(failures) == 0 {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	attempts := map[string]int{}
+	if prev, err := loadFailed(filename); err == nil {
+		for _, f := range prev.Failures {
+			attempts[f.File] = f.Attempts
+		}
+	}
+	failed := Failed{Course: courseURL}
+	for _, v := range videos {
+		ferr, ok := failures[v.filename]
+		if !ok {
+			continue
+		}
+		file, err := filepath.Rel(dir, v.filename)
+		if err != nil {
+			return err
+		}
+		file = filepath.ToSlash(file)
+		failed.Failures = append(failed.Failures, Failure{
+			Video:     v,
+			File:      file,
+			Stage:     stageOf(ferr),
+			ErrorCode: errorCode(ferr),
+			Error:     ferr.Error(),
+			Attempts:  attempts[file] + 1,
+		})
+	}
+
+	b, err := json.MarshalIndent(failed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	if err := os.WriteFile(filename, b, 0o600); err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filename, err)
+	}
+	logf(ctx, tr("💾 %d failed item(s) listed in %s, retry them with: lld retry %s\n"), len(failed.Failures), filename, filename)
+
+	return nil
+}
+
+func loadFailed(filename string) (*Failed, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var failed Failed
+	if err := json.Unmarshal(b, &failed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return &failed, nil
+}
+
+// runRetry re-attempts just the items listed in a failed.json, without walking the whole course again.
+func runRetry(args []string) {
+	var opts options
+	flags := flag.NewFlagSet("retry", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s retry [flags] failed.json\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	registerFlags(flags, &opts)
+	_ = flags.Parse(args)
+	if err := setup(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if !opts.dlVideos && !opts.dlTranscripts {
+		log.Fatal(tr("❌ You must specify at least one of -transcripts or -videos to download."))
+	}
+	filename := flags.Arg(0)
+	failed, err := loadFailed(filename)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	dir := filepath.Dir(filename)
+	videos := make([]VideoEntry, 0, len(failed.Failures))
+	for _, f := range failed.Failures {
+		v := f.Video
+		v.filename = filepath.Join(dir, filepath.FromSlash(f.File))
+		videos = append(videos, v)
+	}
+
+	ctx, cancel := newChromeDPCtx(opts.timeout, &opts)
+	defer cancel()
+
+	if err := login(ctx, &opts); err != nil {
+		logln(ctx, errorText(err))
+		cancel()
+		os.Exit(exitCode(err))
+	}
+	logf(ctx, tr("🔁 Retrying %d failed item(s) of %s\n"), len(videos), failed.Course)
+	failures := processVideos(ctx, videos, &opts)
+	if err := writeFailed(ctx, dir, failed.Course, videos, failures); err != nil {
+		logln(ctx, errorText(err))
+	}
+	if err := refreshIndex(ctx, dir, failures, &opts); err != nil {
+		logln(ctx, errorText(err))
+	}
+	shutdownTracing()
+	stopProfiling(&opts)
+	reportUsage(&opts)
+
+	if len(failures) > 0 {
+		err := &itemsError{}
+		for _, e := range failures {
+			err.errs = append(err.errs, e)
+		}
+		logln(ctx, errorText(err))
+		cancel()
+		os.Exit(exitCode(err))
+	}
+	logln(ctx, tr("✅ All failed items saved."))
+}
+
+// refreshIndex rewrites index.json from the course.json in dir, to pick up the retried items where they were saved.
+func refreshIndex(ctx context.Context, dir string, failures map[string]error, opts *options) error {
+	filename := filepath.Join(dir, "course.json")
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	var course Course
+	if err := json.Unmarshal(b, &course); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	restoreFilenames(course.Videos, dir, opts)
+	course.failures = failures
+
+	return writeIndex(ctx, &course, course.Videos, dir)
+}
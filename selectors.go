@@ -0,0 +1,313 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// selectorsVersion is that of the built-in selectors, bumped whenever a release changes them, so an override written
+// for older ones can be flagged as likely outdated.
+const selectorsVersion = 1
+
+// The CSS selectors, by name. Each is an ordered list of candidates, the first to match being used, so a single
+// change of LinkedIn's doesn't break lld. Besides CSS, a candidate can be SELECTOR:has-text("TEXT"), for the elements
+// matching SELECTOR whose text or aria-label contains TEXT, ignoring case.
+const (
+	selLoggedIn         = "loggedIn"         // Only shows up on LinkedIn Learning once signed in.
+	selHeading          = "heading"          // Waited for on course and author pages.
+	selTOCSection       = "tocSection"       // A section of the table of contents of a course.
+	selTOCSectionTitle  = "tocSectionTitle"  // Within tocSection.
+	selTOCSectionToggle = "tocSectionToggle" // Within tocSection, expands or collapses it.
+	selTOCItem          = "tocItem"          // An item of a section.
+	selTOCItemLink      = "tocItemLink"      // Within tocItem.
+	selTOCItemTitle     = "tocItemTitle"     // Within tocItem.
+	selTranscriptButton = "transcriptButton" // Opens the transcript of an item.
+	selTranscriptLine   = "transcriptLine"   // A line of the open transcript.
+	selRateLimited      = "rateLimited"      // The error page shown when rate limited.
+	selVideo            = "video"            // The player.
+	selPlayButton       = "playButton"       // Starts the player.
+	selDRM              = "drm"              // Classes of a player using DRM.
+	selAudio            = "audio"            // The player of audio-only items, if not the video one.
+	selDocumentLink     = "documentLink"     // The download link of a document item.
+	selDocumentEmbed    = "documentEmbed"    // The viewer of a document item, without a link.
+)
+
+// The page scripts, by name. Each is a JS function evaluated on the page, given the CSS selectors by name (as sel),
+// each resolved to its first matching candidate.
+const (
+	jsExpandTOC    = "expandTOC"
+	jsParseTOC     = "parseTOC"
+	jsParseCourse  = "parseCourse"
+	jsParseAuthor  = "parseAuthor"
+	jsParseQuiz    = "parseQuiz"
+	jsTranscript   = "transcriptLines"
+	jsShowMore     = "showMore"
+	jsCaptions     = "captions"
+	jsProtected    = "protected"
+	jsClickToPlay  = "clickToPlay"
+	jsVideoSrc     = "videoSrc"
+	jsAudioSrc     = "audioSrc"
+	jsDocumentLink = "documentLink"
+)
+
+// Selectors are the CSS selectors and page scripts lld finds its way around LinkedIn's pages with. Any of them can be
+// overridden with -selectors, to hotfix a change of LinkedIn's without waiting for a release.
+type Selectors struct {
+	Version int                   `json:"version"`
+	CSS     map[string]Candidates `json:"css"`
+	Scripts map[string]string     `json:"scripts"`
+	healed  sync.Map              // The names of those whose first candidate didn't match, already warned about.
+}
+
+// Candidates are the ordered candidates of a CSS selector; in JSON, a single one can be given as a string.
+type Candidates []string
+
+// UnmarshalJSON accepts a string as well as a list.
+func (c *Candidates) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*c = Candidates{one}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(c))
+}
+
+type selectorsKey struct{}
+
+func defaultSelectors() *Selectors {
+	return &Selectors{
+		Version: selectorsVersion,
+		CSS: map[string]Candidates{
+			selLoggedIn:         {`h3.chatbot-banner-dynamic__subheading-two`, `[class*="chatbot-banner-dynamic"]`, `.global-nav__me`},
+			selHeading:          {`h1`},
+			selTOCSection:       {`section.classroom-toc-section`, `section[class*="toc-section"]`},
+			selTOCSectionTitle:  {`.classroom-toc-section__toggle-title`, `[class*="toc-section__toggle-title"]`, `h2`},
+			selTOCSectionToggle: {`button.classroom-toc-section__toggle`, `button[class*="toc-section__toggle"]`, `button[aria-expanded]`},
+			selTOCItem:          {`li.classroom-toc-item`, `li[class*="toc-item"]`},
+			selTOCItemLink:      {`a.classroom-toc-item__link`, `a[class*="toc-item__link"]`, `a[href*="/learning/"]`},
+			selTOCItemTitle:     {`.classroom-toc-item__title`, `[class*="toc-item__title"]`},
+			selTranscriptButton: {`button[id*="TRANSCRIPT"]`, `button[aria-label*="transcript" i]`, `button:has-text("Transcript")`},
+			selTranscriptLine:   {`.content-transcript-line`, `[class*="transcript-line"]`, `[class*="transcript"] p`},
+			selRateLimited:      {`.error-body`},
+			selVideo:            {`video.vjs-tech`, `video`},
+			selPlayButton:       {`.vjs-big-play-button`, `button[aria-label*="play" i]`},
+			selDRM:              {`.vjs-eme, .vjs-drm`},
+			selAudio:            {`audio`},
+			selDocumentLink:     {`a[download], a[href*=".pdf"], a[data-control-name*="download"]`, `a:has-text("Download")`},
+			selDocumentEmbed:    {`iframe[src*=".pdf"], embed[src], object[data]`},
+		},
+		Scripts: map[string]string{
+			jsExpandTOC:    expandTOCJS,
+			jsParseTOC:     videoParseJS,
+			jsParseCourse:  courseParseJS,
+			jsParseAuthor:  authorParseJS,
+			jsParseQuiz:    quizParseJS,
+			jsTranscript:   transcriptLinesJS,
+			jsShowMore:     showMoreJS,
+			jsCaptions:     captionsJS,
+			jsProtected:    protectedJS,
+			jsClickToPlay:  clickToPlayJS,
+			jsVideoSrc:     videoSrcJS,
+			jsAudioSrc:     audioSrcJS,
+			jsDocumentLink: documentLinkJS,
+		},
+	}
+}
+
+// setupSelectors applies the -selectors overrides, from a file or an http(s) URL, on top of the built-in selectors.
+func setupSelectors(opts *options) error {
+	opts.selectors = defaultSelectors()
+	if opts.selectorsFile == "" {
+		return nil
+	}
+	b, err := readSelectors(opts)
+	if err != nil {
+		return fmt.Errorf("failed to read selectors from %s: %w", opts.selectorsFile, err)
+	}
+	var overrides Selectors
+	if err := json.Unmarshal(b, &overrides); err != nil {
+		return fmt.Errorf("failed to parse selectors from %s: %w", opts.selectorsFile, err)
+	}
+	if overrides.Version != selectorsVersion {
+		logf(context.Background(), tr("⚠️ The selectors in %s are for version %d, not %d; they may be outdated.\n"),
+			opts.selectorsFile, overrides.Version, selectorsVersion)
+	}
+	if err := mergeSelectors(opts.selectors.CSS, overrides.CSS); err != nil {
+		return err
+	}
+
+	return mergeSelectors(opts.selectors.Scripts, overrides.Scripts)
+}
+
+func readSelectors(opts *options) ([]byte, error) {
+	if !strings.HasPrefix(opts.selectorsFile, "http://") && !strings.HasPrefix(opts.selectorsFile, "https://") {
+		return os.ReadFile(opts.selectorsFile)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.selectorsFile, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newHTTPClient(opts).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// mergeSelectors overrides those in dst, refusing names it doesn't know, which are surely typos.
+func mergeSelectors[V any](dst, overrides map[string]V) error {
+	for name, s := range overrides {
+		if _, ok := dst[name]; !ok {
+			return fmt.Errorf("unknown selector %q, expected one of: %s", name,
+				strings.Join(slices.Sorted(maps.Keys(dst)), ", "))
+		}
+		dst[name] = s
+	}
+
+	return nil
+}
+
+// withSelectors returns ctx with the selectors to use on pages browsed with it.
+func withSelectors(ctx context.Context, s *Selectors) context.Context {
+	if s == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, selectorsKey{}, s)
+}
+
+func selectorsFrom(ctx context.Context) *Selectors {
+	if s, ok := ctx.Value(selectorsKey{}).(*Selectors); ok {
+		return s
+	}
+
+	return defaultSelectors()
+}
+
+// matchJS is a page function returning the elements a candidate matches, none if it isn't valid CSS.
+const matchJS = `c => {
+	try {
+		const m = /^(.*):has-text\((["']?)(.*)\2\)$/.exec(c);
+		if (!m) return Array.from(document.querySelectorAll(c));
+		const text = m[3].toLowerCase();
+		return Array.from(document.querySelectorAll(m[1] || "*")).filter(el =>
+			((el.innerText || "") + " " + (el.getAttribute("aria-label") || "")).toLowerCase().includes(text));
+	} catch {
+		return [];
+	}
+}`
+
+// resolveJS is a page function resolving the candidates of the selector called name to the first that matches, or ""
+// if none does. A :has-text candidate resolves to an attribute it marks the matching elements with.
+const resolveJS = `(candidates, name) => {
+	const match = ` + matchJS + `;
+	const mark = "data-lld-" + name.toLowerCase();
+	for (const c of candidates) {
+		const els = match(c);
+		if (!els.length) continue;
+		if (!c.includes(":has-text(")) return c;
+		document.querySelectorAll("[" + mark + "]").forEach(el => el.removeAttribute(mark));
+		els.forEach(el => el.setAttribute(mark, ""));
+		return "[" + mark + "]";
+	}
+	return "";
+}`
+
+// resolveScript is a script resolving the selector called name on the page, as resolveJS.
+func resolveScript(ctx context.Context, name string) string {
+	candidates, _ := json.Marshal(selectorsFrom(ctx).CSS[name])
+	n, _ := json.Marshal(name)
+
+	return "(" + resolveJS + ")(" + string(candidates) + ", " + string(n) + ")"
+}
+
+// pageScript returns the page script called name, called with the resolved CSS selectors and the path of -base-url,
+// ready to evaluate. Selectors that match nothing fall back to their first plain CSS candidate.
+func pageScript(ctx context.Context, name string) string {
+	s := selectorsFrom(ctx)
+	css, _ := json.Marshal(s.CSS)
+
+	base, _ := json.Marshal(learningBaseFrom(ctx).Path)
+
+	return `((resolve, css) => (` + s.Scripts[name] + `)(Object.fromEntries(Object.entries(css).map(([k, v]) =>
+	[k, resolve(v, k) || v.find(c => !c.includes(":has-text(")) || ":not(*)"])), ` + string(base) + `))(` + resolveJS + `, ` +
+		string(css) + `)`
+}
+
+// existsJS is a script reporting whether anything on the page matches the selector called name.
+func existsJS(ctx context.Context, name string) string {
+	return resolveScript(ctx, name) + ` !== ""`
+}
+
+// waitReady waits for any of the selectors called names to match, in order, storing the matching candidate in
+// *resolved for the actions that follow.
+func waitReady(resolved *string, names ...string) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		return poll(ctx, strings.Join(names, tr(" or ")), func(ctx context.Context) (bool, error) {
+			for _, name := range names {
+				var s string
+				if err := chromedp.Evaluate(resolveScript(ctx, name), &s).Do(ctx); err != nil {
+					return false, err
+				}
+				if s != "" {
+					healed(ctx, name, s)
+					*resolved = s
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+	}
+}
+
+// waitVisible waits for the selector called name to match something visible, storing the candidate in *resolved.
+func waitVisible(name string, resolved *string) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		if err := waitReady(resolved, name).Do(ctx); err != nil {
+			return err
+		}
+
+		return chromedp.WaitVisible(*resolved, chromedp.ByQuery).Do(ctx)
+	}
+}
+
+// healed warns, once, when the selector called name only matched by a fallback, so it can be fixed before the
+// fallbacks stop matching too.
+func healed(ctx context.Context, name, resolved string) {
+	s := selectorsFrom(ctx)
+	if candidates := s.CSS[name]; len(candidates) == 0 || resolved == candidates[0] {
+		return
+	}
+	if _, warned := s.healed.LoadOrStore(name, true); !warned {
+		logf(ctx, tr("🩹 The %s selector %q no longer matches, using a fallback (%s).\n"), name, s.CSS[name][0], resolved)
+	}
+}
+
+// runSelectors prints the built-in selectors, as a starting point for -selectors overrides.
+func runSelectors(_ []string) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(defaultSelectors())
+}
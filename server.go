@@ -0,0 +1,1000 @@
+package lld
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Job statuses.
+const (
+	jobQueued   = "queued"
+	jobRunning  = "running"
+	jobPaused   = "paused"
+	jobDone     = "done"
+	jobFailed   = "failed"
+	jobCanceled = "canceled"
+)
+
+type Job struct {
+	ID       string     `json:"id"`
+	Course   string     `json:"course"`
+	Status   string     `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	Code     string     `json:"errorCode,omitempty"` // See errorKinds.
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	User     string     `json:"user,omitempty"` // Whose session it runs in, see serverConfig.Users.
+	Priority int        `json:"priority"`       // Queued jobs run highest priority first, then oldest first.
+	// Done of Total items, once it knows how many there are.
+	Done     int          `json:"done,omitempty"`
+	Total    int          `json:"total,omitempty"`
+	Failures []JobFailure `json:"failures,omitempty"`
+	Active   []ActiveItem `json:"active,omitempty"` // The items in progress.
+
+	events  *events
+	cancel  context.CancelFunc // Stops the job while it's running.
+	held    chan struct{}      // While paused, closed on resume.
+	skips   map[int]context.CancelFunc
+	version int // Of the stored job, see jobQueue.
+}
+
+// JobFailure is an item of a job that failed.
+type JobFailure struct {
+	Section    string `json:"section"`
+	Title      string `json:"title"`
+	Error      string `json:"error"`
+	ErrorCode  string `json:"errorCode"`            // See errorKinds.
+	Screenshot string `json:"screenshot,omitempty"` // Its URL, with -screenshots.
+
+	screenshot string // The file.
+}
+
+// ActiveItem is an item of a job in progress, which POST /jobs/{id}/items/{n}/skip can skip.
+type ActiveItem struct {
+	N       int    `json:"n"`
+	Section string `json:"section"`
+	Title   string `json:"title"`
+}
+
+// serverConfig is the (reloadable) -config file; any key left out keeps the value given on the command line.
+type serverConfig struct {
+	Output      string `json:"output"`
+	Transcripts bool   `json:"transcripts"`
+	Videos      bool   `json:"videos"`
+	JSON        bool   `json:"json"`
+	Readme      bool   `json:"readme"`
+	Timeout     string `json:"timeout"`
+	Backoff     string `json:"backoff"`
+	// CredentialSource is read once at startup, as the session is only logged in then.
+	CredentialSource string `json:"credential_source"`
+	// Users can each run jobs in a session of their own, for their own license, instead of the daemon's.
+	Users map[string]serverUser `json:"users"`
+	// Token is the API token of the daemon itself, whose jobs run in its own session, and who can manage everyone's.
+	// Once it or any users are configured, every API request needs one of their tokens.
+	Token string `json:"token"`
+}
+
+// serverUser is a user of a shared daemon.
+type serverUser struct {
+	// CredentialSource is where their session is, as for -credential-source; it's logged in at their first job.
+	CredentialSource string `json:"credential_source"`
+	// Output is where their courses go, instead of the daemon's output.
+	Output string `json:"output"`
+	// Token is their API token, as a bearer token: the jobs they submit with it run in their session.
+	Token string `json:"token"`
+}
+
+// session is a user's browser context, with cookies of its own.
+type session struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type server struct {
+	mu           sync.Mutex
+	base         options // From the command line.
+	opts         options // base + config file, swapped on SIGHUP.
+	configFile   string
+	users        map[string]serverUser
+	token        string             // The daemon's API token, see serverConfig.Token.
+	browser      context.Context    // The daemon's logged in browser.
+	sessions     map[string]session // Those of the users, only touched by the worker.
+	sessionCheck sessionCheck
+	queue        jobQueue
+	jobs         []*Job // Those submitted or running here, with their events.
+	wake         chan struct{}
+}
+
+func runServe(args []string) {
+	var (
+		opts       options
+		addr       string
+		configFile string
+		pidFile    string
+		queue      string
+	)
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	registerFlags(flags, &opts)
+	flags.StringVar(&addr, "addr", "localhost:8080", "Address for the HTTP API to listen on.")
+	flags.StringVar(&configFile, "config", "", "JSON config file with download options, reloaded on SIGHUP.")
+	flags.StringVar(&pidFile, "pid-file", "", "File to write the process ID to.")
+	flags.StringVar(&queue, "queue", "memory", "Where to keep the jobs: memory, sqlite:FILE, or redis://HOST[:PORT][/DB].")
+	_ = flags.Parse(args)
+	if err := setup(&opts); err != nil {
+		log.Fatal(errorText(err))
+	}
+
+	q, err := newJobQueue(queue)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	s := &server{base: opts, configFile: configFile, queue: q, wake: make(chan struct{}, 1)}
+	if err := s.reload(); err != nil {
+		log.Fatal(errorText(err))
+	}
+
+	if pidFile != "" {
+		if err := writePIDFile(pidFile); err != nil {
+			log.Fatalf(tr("❌ failed to write PID file: %v"), err)
+		}
+		defer func() {
+			_ = os.Remove(pidFile)
+		}()
+	}
+
+	// The browser lives as long as the daemon; each job gets its own -timeout instead.
+	ctx, cancel := newChromeDPCtx(0, &opts)
+	defer cancel()
+
+	startOpts := s.opts
+	if err := login(ctx, &startOpts); err != nil {
+		log.Fatal(errorText(err))
+	}
+	s.browser, s.sessions = ctx, map[string]session{}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf(tr("❌ failed to listen on %s: %v"), addr, err)
+	}
+	srv := &http.Server{Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second, Protocols: &http.Protocols{}}
+	// The gRPC API needs HTTP/2, which gRPC clients speak without TLS.
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf(tr("❌ server failed: %v"), err)
+		}
+	}()
+	go s.work(ctx)
+	go sdWatchdog(ctx)
+
+	log.Printf(tr("🛰️ Listening on %s\n"), ln.Addr())
+	sdNotify("READY=1")
+
+	s.handleSignals()
+
+	sdNotify("STOPPING=1")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	_ = srv.Shutdown(shutdownCtx)
+	shutdownTracing()
+	stopProfiling(&opts)
+	log.Println(tr("👋 Server stopped."))
+}
+
+// handleSignals blocks, reloading the config on SIGHUP, until SIGINT/SIGTERM.
+func (s *server) handleSignals() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	for sig := range sigs {
+		if sig != syscall.SIGHUP {
+			return
+		}
+		sdNotify("RELOADING=1")
+		if err := s.reload(); err != nil {
+			log.Printf(tr("⚠️ failed to reload config, keeping the previous one: %v"), err)
+		} else {
+			log.Println(tr("🔄 Config reloaded."))
+		}
+		sdNotify("READY=1")
+	}
+}
+
+// reload re-reads the config file on top of the command line options. Running jobs keep the options they started with.
+func (s *server) reload() error {
+	opts := s.base
+	var (
+		users map[string]serverUser
+		token string
+	)
+	if s.configFile != "" {
+		cfg := serverConfig{
+			Output:           opts.outDir,
+			Transcripts:      opts.dlTranscripts,
+			Videos:           opts.dlVideos,
+			JSON:             opts.saveJSON,
+			Readme:           opts.readme,
+			Timeout:          opts.timeout.String(),
+			Backoff:          opts.backoff.String(),
+			CredentialSource: opts.credentialSource,
+		}
+		b, err := os.ReadFile(s.configFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", s.configFile, err)
+		}
+		if err := json.Unmarshal(b, &cfg); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", s.configFile, err)
+		}
+		opts.outDir, opts.dlTranscripts, opts.dlVideos = cfg.Output, cfg.Transcripts, cfg.Videos
+		opts.saveJSON, opts.readme, opts.credentialSource = cfg.JSON, cfg.Readme, cfg.CredentialSource
+		users, token = cfg.Users, cfg.Token
+		if err := checkTokens(token, users); err != nil {
+			return err
+		}
+		if opts.timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", s.configFile, err)
+		}
+		if opts.backoff, err = time.ParseDuration(cfg.Backoff); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", s.configFile, err)
+		}
+	}
+	if !opts.dlVideos && !opts.dlTranscripts {
+		return errors.New("You must specify at least one of -transcripts or -videos to download.")
+	}
+
+	s.mu.Lock()
+	s.opts, s.users, s.token = opts, users, token
+	s.mu.Unlock()
+
+	return nil
+}
+
+// work runs queued jobs one at a time, since they all share the one logged in browser.
+func (s *server) work(ctx context.Context) {
+	for {
+		j, jobCtx, opts := s.next(ctx)
+		if j == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+				continue
+			case <-time.After(queuePoll):
+				continue
+			}
+		}
+
+		jobCtx = withLogFields(jobCtx, "job", j.ID)
+		// Jobs run one at a time, so whatever is logged meanwhile is the job's progress.
+		out := log.Writer()
+		log.SetOutput(io.MultiWriter(out, j.events))
+		logf(jobCtx, tr("📦 Starting job %s: %s\n"), j.ID, j.Course)
+		err := s.runJob(jobCtx, j, &opts)
+		canceled := jobCtx.Err() != nil && ctx.Err() == nil
+		if sess, ok := s.sessions[j.User]; ok && errors.Is(err, ErrAuthExpired) {
+			// Log them in again on their next job.
+			sess.cancel()
+			delete(s.sessions, j.User)
+		}
+		log.SetOutput(out)
+
+		s.mu.Lock()
+		j.cancel()
+		j.cancel = nil
+		now := time.Now()
+		j.Finished = &now
+		switch {
+		case canceled:
+			j.Status = jobCanceled
+		case err != nil:
+			j.Status = jobFailed
+			j.Error = err.Error()
+			j.Code = errorCode(err)
+		default:
+			j.Status = jobDone
+		}
+		ev := Event{Type: eventStatus, Status: j.Status, Error: j.Error, ErrorCode: j.Code}
+		s.save(j)
+		s.prune()
+		s.mu.Unlock()
+		j.events.add(ev)
+		j.events.close()
+		logf(jobCtx, tr("🏁 Job %s %s\n"), j.ID, tr(j.Status))
+	}
+}
+
+func (s *server) runJob(ctx context.Context, j *Job, opts *options) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	if j.User != "" {
+		s.mu.Lock()
+		user, ok := s.users[j.User]
+		s.mu.Unlock()
+		if !ok {
+			return fmt.Errorf("unknown user %q", j.User)
+		}
+		sess, err := s.session(j.User, user, opts)
+		if err != nil {
+			return err
+		}
+		// Run in the user's browser, while still stopping with the job.
+		userCtx, stop := context.WithCancel(sess)
+		defer stop()
+		defer context.AfterFunc(ctx, stop)()
+		ctx = userCtx
+		opts.outDir = cmp.Or(user.Output, opts.outDir)
+	}
+
+	slug, err := courseSlug(opts.learningBase, j.Course)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(opts.outDir, slug)
+	opts.progress = func(done, total int, video VideoEntry, err error) {
+		s.progress(j, done, total, video, err)
+	}
+	opts.hold = func(ctx context.Context) {
+		s.hold(ctx, j)
+	}
+	opts.track = func(n int, video VideoEntry, skip context.CancelFunc) func() {
+		return s.track(j, n, video, skip)
+	}
+	course, err := downloadCourse(ctx, opts, j.Course, dir)
+	if err != nil {
+		return err
+	}
+	if err := finishCourse(ctx, opts, j.Course, dir); err != nil {
+		return err
+	}
+	if len(course.failures) > 0 {
+		return &itemsError{errs: slices.Collect(maps.Values(course.failures))}
+	}
+
+	return nil
+}
+
+// session returns the browser context of a user, logging them in with their credential source first if needed.
+// Each user gets a browser context of their own, so their cookies never mix with anyone else's.
+func (s *server) session(name string, user serverUser, opts *options) (context.Context, error) {
+	if sess, ok := s.sessions[name]; ok {
+		return sess.ctx, nil
+	}
+	ctx, cancel := chromedp.NewContext(s.browser, chromedp.WithNewBrowserContext())
+	log.Printf(tr("🔑 Logging in as %s.\n"), name)
+	if err := restoreSession(ctx, user.CredentialSource); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to log in as %s: %w", name, err)
+	}
+	if err := prepareBrowser(ctx, opts); err != nil {
+		cancel()
+		return nil, err
+	}
+	s.sessions[name] = session{ctx: ctx, cancel: cancel}
+
+	return ctx, nil
+}
+
+// progress records that another item of j is done, and whether it failed.
+func (s *server) progress(j *Job, done, total int, video VideoEntry, err error) {
+	ev := Event{Type: eventItem, Message: video.Title, Done: done, Total: total}
+	s.mu.Lock()
+	j.Done, j.Total = done, total
+	if err != nil {
+		f := JobFailure{Section: video.Section, Title: video.Title, Error: err.Error(), ErrorCode: errorCode(err)}
+		if shot := video.filename + screenshotExt; exists(shot) {
+			f.screenshot = shot
+			f.Screenshot = fmt.Sprintf("/jobs/%s/failures/%d/screenshot", j.ID, len(j.Failures))
+		}
+		j.Failures = append(j.Failures, f)
+		ev.Error, ev.ErrorCode = f.Error, f.ErrorCode
+	}
+	s.save(j)
+	s.mu.Unlock()
+
+	j.events.add(ev)
+}
+
+// hold blocks while j is paused, or until ctx is done.
+func (s *server) hold(ctx context.Context, j *Job) {
+	s.mu.Lock()
+	held := j.held
+	s.mu.Unlock()
+	if held == nil {
+		return
+	}
+	logf(ctx, tr("⏸️ Job %s paused.\n"), j.ID)
+	select {
+	case <-ctx.Done():
+	case <-held:
+		logf(ctx, tr("▶️ Job %s resumed.\n"), j.ID)
+	}
+}
+
+// track lists an item of j as active until the returned func is called, so it can be skipped.
+func (s *server) track(j *Job, n int, video VideoEntry, skip context.CancelFunc) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j.skips == nil {
+		j.skips = map[int]context.CancelFunc{}
+	}
+	j.skips[n] = skip
+	// Copied on every change, as the copies handed out by job share it.
+	j.Active = append(slices.Clone(j.Active), ActiveItem{N: n, Section: video.Section, Title: video.Title})
+	s.save(j)
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(j.skips, n)
+		j.Active = slices.DeleteFunc(slices.Clone(j.Active), func(a ActiveItem) bool { return a.N == n })
+		s.save(j)
+	}
+}
+
+// queuePoll is how often an idle worker looks for jobs queued by other daemons sharing the -queue.
+const queuePoll = 5 * time.Second
+
+// next claims the queued job to run next (see Job.Priority), returning it along with its (cancelable) context and the
+// options it should use. With a shared -queue, another daemon may claim it first, and then the next one is tried.
+func (s *server) next(ctx context.Context) (*Job, context.Context, options) {
+	s.mu.Lock()
+	opts := s.opts
+	s.mu.Unlock()
+	for {
+		jobs, err := s.queue.list(ctx)
+		if err != nil {
+			logf(ctx, tr("⚠️ failed to list jobs: %v"), err)
+			return nil, nil, opts
+		}
+		next := nextQueued(jobs)
+		if next == nil {
+			return nil, nil, opts
+		}
+		now := time.Now()
+		claimed, err := updateJob(ctx, s.queue, next.ID, func(j *Job) error {
+			if j.Status != jobQueued {
+				return &conflictError{msg: "job isn't queued"}
+			}
+			j.Status, j.Started = jobRunning, &now
+			return nil
+		})
+		var conflict *conflictError
+		switch {
+		case errors.As(err, &conflict):
+			continue
+		case err != nil:
+			logf(ctx, tr("⚠️ failed to claim job %s: %v"), next.ID, err)
+			return nil, nil, opts
+		}
+		j, jobCtx := s.start(ctx, claimed)
+
+		return j, jobCtx, opts
+	}
+}
+
+// nextQueued picks the queued job to run next: the one of highest priority, then the oldest.
+func nextQueued(jobs []*Job) *Job {
+	var next *Job
+	for _, j := range jobs {
+		if j.Status == jobQueued && (next == nil || j.Priority > next.Priority) {
+			next = j
+		}
+	}
+
+	return next
+}
+
+// start keeps the state of a job claimed to run here, which gets its (cancelable) context.
+func (s *server) start(ctx context.Context, claimed *Job) (*Job, context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.find(claimed.ID)
+	if j == nil {
+		// Queued by another daemon.
+		j = &Job{events: newEvents()}
+		s.jobs = append(s.jobs, j)
+	}
+	claimed.events = j.events
+	*j = *claimed
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.events.add(Event{Type: eventStatus, Status: jobRunning})
+
+	return j, ctx
+}
+
+// save stores the state of a job running here, which nobody else changes meanwhile; the caller holds the lock.
+func (s *server) save(j *Job) {
+	local := *j
+	if _, err := updateJob(context.Background(), s.queue, j.ID, func(stored *Job) error {
+		version := stored.version
+		*stored = local
+		stored.version = version
+		return nil
+	}); err != nil {
+		log.Printf(tr("⚠️ failed to save job %s: %v"), j.ID, err)
+	}
+}
+
+// endpoints is the serve API, which both the routes and the OpenAPI spec (GET /openapi.json) are made from.
+func (s *server) endpoints() []endpoint {
+	notFound := []int{http.StatusNotFound}
+	// Changes to a job are only allowed to whoever submitted it, or the daemon.
+	change := []int{http.StatusNotFound, http.StatusForbidden, http.StatusConflict}
+
+	return []endpoint{
+		{
+			method: http.MethodPost, path: "/jobs", id: "submitJob", summary: "Queue a course for download.",
+			request: SubmitRequest{}, response: Job{}, status: http.StatusAccepted, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+			handler: s.handleSubmit,
+		},
+		{
+			method: http.MethodGet, path: "/jobs", id: "listJobs", summary: "List the caller's jobs, or every job for the daemon.",
+			response: []Job{}, status: http.StatusOK, handler: s.handleList,
+		},
+		{
+			method: http.MethodGet, path: "/jobs/{id}", id: "getJob", summary: "Get a job.",
+			response: Job{}, status: http.StatusOK, errors: notFound, handler: s.handleGet,
+		},
+		{
+			method: http.MethodGet, path: "/jobs/{id}/events", id: "watchJob", summary: "Stream a job's progress.",
+			response: Event{}, status: http.StatusOK, errors: notFound, contentType: "text/event-stream",
+			handler: s.handleEvents,
+		},
+		{
+			method: http.MethodGet, path: "/jobs/{id}/failures/{n}/screenshot", id: "getFailureScreenshot",
+			summary:  "Get the screenshot of a failed item, taken with -screenshots.",
+			response: []byte{}, status: http.StatusOK, errors: notFound, contentType: "image/png", handler: s.handleScreenshot,
+		},
+		{
+			method: http.MethodPatch, path: "/jobs/{id}", id: "updateJob", summary: "Change the priority of a queued job.",
+			request: UpdateRequest{}, response: Job{}, status: http.StatusOK,
+			errors: append([]int{http.StatusBadRequest}, change...), handler: s.handleUpdate,
+		},
+		{
+			method: http.MethodPost, path: "/jobs/{id}/promote", id: "promoteJob", summary: "Move a queued job to the front of the queue.",
+			response: Job{}, status: http.StatusOK, errors: change, handler: s.handlePromote,
+		},
+		{
+			method: http.MethodPost, path: "/jobs/{id}/pause", id: "pauseJob",
+			summary:  "Pause a running job once the items in progress are done.",
+			response: Job{}, status: http.StatusOK, errors: change, handler: s.handlePause,
+		},
+		{
+			method: http.MethodPost, path: "/jobs/{id}/resume", id: "resumeJob", summary: "Resume a paused job.",
+			response: Job{}, status: http.StatusOK, errors: change, handler: s.handleResume,
+		},
+		{
+			method: http.MethodPost, path: "/jobs/{id}/items/{n}/skip", id: "skipItem",
+			summary:  "Skip an item in progress, carrying on with the rest of the job.",
+			response: Job{}, status: http.StatusAccepted, errors: []int{http.StatusNotFound, http.StatusForbidden}, handler: s.handleSkip,
+		},
+		{
+			method: http.MethodDelete, path: "/jobs/{id}", id: "cancelJob", summary: "Cancel a queued or running job.",
+			response: Job{}, status: http.StatusAccepted, errors: change,
+			handler: s.handleCancel,
+		},
+	}
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	for _, e := range s.endpoints() {
+		mux.Handle(e.method+" "+e.path, s.authenticate(e.handler))
+	}
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /{$}", handleDashboard)
+	mux.Handle("POST "+grpcPath+"{method}", s.authenticate(http.HandlerFunc(s.handleGRPC)))
+
+	return mux
+}
+
+// SubmitRequest is the body of POST /jobs.
+type SubmitRequest struct {
+	Course string `json:"course"`
+	// User is the user whose token the request has, if given; the job runs in their session either way.
+	User     string `json:"user,omitempty"`
+	Priority int    `json:"priority,omitempty"` // See Job.Priority.
+}
+
+// UpdateRequest is the body of PATCH /jobs/{id}.
+type UpdateRequest struct {
+	Priority int `json:"priority"`
+}
+
+// validate checks the request of caller (see callerFrom), normalizing its course to the canonical course URL under
+// base.
+func (req *SubmitRequest) validate(base *url.URL, caller string) error {
+	if req.User != "" && req.User != caller {
+		return &permissionError{msg: "jobs can only be submitted for the user of the token"}
+	}
+	req.User = caller
+	if req.Course == "" {
+		return &fieldError{field: "course", err: errors.New("is required")}
+	}
+	course, err := normalizeCourseURL(base, req.Course)
+	if err != nil {
+		return &fieldError{field: "course", err: err}
+	}
+	req.Course = course
+
+	return nil
+}
+
+func (s *server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req SubmitRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	j, err := s.submit(r.Context(), req)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+// submit validates req and queues its job, for both APIs.
+func (s *server) submit(ctx context.Context, req SubmitRequest) (Job, error) {
+	s.mu.Lock()
+	base := s.opts.learningBase
+	s.mu.Unlock()
+	if err := req.validate(base, callerFrom(ctx)); err != nil {
+		return Job{}, err
+	}
+
+	j := &Job{
+		Course: req.Course, User: req.User, Priority: req.Priority, Status: jobQueued, Created: time.Now(), events: newEvents(),
+	}
+	j.events.add(Event{Type: eventStatus, Status: jobQueued})
+	// Locked until it's kept here, so that the worker doesn't take it for another daemon's job.
+	s.mu.Lock()
+	if err := s.queue.add(ctx, j); err != nil {
+		s.mu.Unlock()
+		return Job{}, err
+	}
+	s.jobs = append(s.jobs, j)
+	resp := *j
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return resp, nil
+}
+
+func (s *server) handleList(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.list(r.Context())
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// list returns the jobs the caller owns, for both APIs.
+func (s *server) list(ctx context.Context) ([]*Job, error) {
+	jobs, err := s.queue.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(jobs, func(j *Job) bool { return owns(ctx, j) != nil }), nil
+}
+
+func (s *server) handleGet(w http.ResponseWriter, r *http.Request) {
+	j, err := s.queue.get(r.Context(), r.PathValue("id"))
+	if err == nil && (j == nil || owns(r.Context(), j) != nil) {
+		err = errNoJob
+	}
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, j)
+}
+
+func (s *server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	j, err := s.cancel(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+// cancel cancels a job: a queued one never starts, and one running here is stopped where it is.
+func (s *server) cancel(ctx context.Context, id string) (Job, error) {
+	s.mu.Lock()
+	if j := s.find(id); j != nil && (j.Status == jobRunning || j.Status == jobPaused) {
+		if err := owns(ctx, j); err != nil {
+			s.mu.Unlock()
+			return Job{}, err
+		}
+		// The worker marks it canceled once it has stopped.
+		j.cancel()
+		resp := *j
+		s.mu.Unlock()
+		return resp, nil
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	j, err := updateJob(ctx, s.queue, id, func(j *Job) error {
+		if err := owns(ctx, j); err != nil {
+			return err
+		}
+		if j.Status != jobQueued {
+			return &conflictError{msg: "job already finished, or running on another daemon"}
+		}
+		j.Status, j.Finished = jobCanceled, &now
+		return nil
+	})
+	if err != nil {
+		return Job{}, err
+	}
+	s.mu.Lock()
+	if local := s.find(id); local != nil {
+		local.Status, local.Finished = j.Status, j.Finished
+		local.events.add(Event{Type: eventStatus, Status: jobCanceled})
+		local.events.close()
+		s.prune()
+	}
+	s.mu.Unlock()
+
+	return *j, nil
+}
+
+func (s *server) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.job(r.PathValue("id"))
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if !ok || owns(r.Context(), &j) != nil || err != nil || n < 0 || n >= len(j.Failures) || j.Failures[n].screenshot == "" {
+		writeError(w, http.StatusNotFound, errors.New("no such screenshot"))
+		return
+	}
+
+	http.ServeFile(w, r, j.Failures[n].screenshot)
+}
+
+func (s *server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.reprioritize(w, r, req.Priority)
+}
+
+// handlePromote puts a queued job ahead of all others, by giving it a higher priority than any of them.
+func (s *server) handlePromote(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.queue.list(r.Context())
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	top := 0
+	for _, j := range jobs {
+		if j.Status == jobQueued {
+			top = max(top, j.Priority+1)
+		}
+	}
+
+	s.reprioritize(w, r, top)
+}
+
+// reprioritize sets the priority of a queued job.
+func (s *server) reprioritize(w http.ResponseWriter, r *http.Request, priority int) {
+	j, err := updateJob(r.Context(), s.queue, r.PathValue("id"), func(j *Job) error {
+		if err := owns(r.Context(), j); err != nil {
+			return err
+		}
+		if j.Status != jobQueued {
+			return &conflictError{msg: "job isn't queued"}
+		}
+		j.Priority = priority
+		return nil
+	})
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, j)
+}
+
+// handleSkip gives up on an active item of a job; it fails as skipped.
+func (s *server) handleSkip(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.find(r.PathValue("id"))
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if j == nil || err != nil || j.skips[n] == nil {
+		writeError(w, http.StatusNotFound, errors.New("no such item in progress"))
+		return
+	}
+	if err := owns(r.Context(), j); err != nil {
+		writeJobError(w, err)
+		return
+	}
+	j.skips[n]()
+
+	writeJSON(w, http.StatusAccepted, *j)
+}
+
+// handlePause holds a running job's remaining items; those already being downloaded are finished first.
+func (s *server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, true)
+}
+
+func (s *server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, false)
+}
+
+func (s *server) setPaused(w http.ResponseWriter, r *http.Request, pause bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.find(r.PathValue("id"))
+	if j == nil {
+		writeError(w, http.StatusConflict, errors.New("job isn't running here"))
+		return
+	}
+	if err := owns(r.Context(), j); err != nil {
+		writeJobError(w, err)
+		return
+	}
+	switch {
+	case pause && j.Status != jobRunning:
+		writeError(w, http.StatusConflict, errors.New("job isn't running"))
+		return
+	case !pause && j.Status != jobPaused:
+		writeError(w, http.StatusConflict, errors.New("job isn't paused"))
+		return
+	}
+	if pause {
+		j.Status, j.held = jobPaused, make(chan struct{})
+	} else {
+		close(j.held)
+		j.Status, j.held = jobRunning, nil
+	}
+	j.events.add(Event{Type: eventStatus, Status: j.Status})
+	s.save(j)
+
+	writeJSON(w, http.StatusOK, *j)
+}
+
+// keptJobs is how many finished jobs are kept here, for their events and screenshots, besides those still queued or
+// running; the queue has them all.
+const keptJobs = 100
+
+// prune forgets the oldest finished jobs beyond keptJobs; the caller holds the lock.
+func (s *server) prune() {
+	finished := 0
+	for _, j := range s.jobs {
+		if j.Finished != nil {
+			finished++
+		}
+	}
+	s.jobs = slices.DeleteFunc(s.jobs, func(j *Job) bool {
+		if j.Finished == nil || finished <= keptJobs {
+			return false
+		}
+		finished--
+		return true
+	})
+}
+
+// find returns the job with the given ID, or nil; the caller holds the lock.
+func (s *server) find(id string) *Job {
+	i := slices.IndexFunc(s.jobs, func(j *Job) bool { return j.ID == id })
+	if i < 0 {
+		return nil
+	}
+
+	return s.jobs[i]
+}
+
+// job returns a copy of the job with the given ID.
+func (s *server) job(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j := s.find(id); j != nil {
+		return *j, true
+	}
+
+	return Job{}, false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// APIError is the body of every error response of the serve API.
+type APIError struct {
+	Code  string `json:"code"` // The status, in snake case, e.g. not_found.
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"` // The invalid field of the request, if that's what's wrong.
+}
+
+// fieldError is a request field failing validation.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.field + " " + e.err.Error()
+}
+
+// writeJobError responds with the failure of a request about a job.
+func writeJobError(w http.ResponseWriter, err error) {
+	var (
+		conflict   *conflictError
+		fe         *fieldError
+		permission *permissionError
+	)
+	switch {
+	case errors.Is(err, errNoJob):
+		writeError(w, http.StatusNotFound, err)
+	case errors.As(err, &conflict):
+		writeError(w, http.StatusConflict, err)
+	case errors.As(err, &fe):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.As(err, &permission):
+		writeError(w, http.StatusForbidden, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := APIError{
+		Code:  strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Error: err.Error(),
+	}
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		resp.Field = fe.field
+	}
+
+	writeJSON(w, status, resp)
+}
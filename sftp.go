@@ -0,0 +1,66 @@
+package lld
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// sftpUploader uploads over SFTP with the system's sftp client, given as sftp://user@host[:port]/dir[?key=identity].
+// It runs in batch mode, so authentication has to be by key (an agent, ~/.ssh/config, or the key parameter).
+type sftpUploader struct {
+	dest string // user@host
+	root string
+	args []string
+	made map[string]bool // Directories known to exist.
+}
+
+func newSFTP(u *url.URL) *sftpUploader {
+	s := &sftpUploader{
+		dest: u.Hostname(),
+		root: u.Path,
+		args: []string{"-b", "-", "-o", "BatchMode=yes"},
+		made: map[string]bool{},
+	}
+	if u.User != nil {
+		s.dest = u.User.Username() + "@" + s.dest
+	}
+	if port := u.Port(); port != "" {
+		s.args = append(s.args, "-P", port)
+	}
+	if key := u.Query().Get("key"); key != "" {
+		s.args = append(s.args, "-i", key)
+	}
+
+	return s
+}
+
+func (s *sftpUploader) put(ctx context.Context, file, rel string) error {
+	remote := path.Join(s.root, rel)
+	var batch strings.Builder
+	// sftp has no mkdir -p, so create each missing parent; the leading - ignores "already exists".
+	var dirs []string
+	for dir := path.Dir(remote); dir != "." && dir != "/" && !s.made[dir]; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		fmt.Fprintf(&batch, "-mkdir %s\n", sftpQuote(dirs[i]))
+	}
+	fmt.Fprintf(&batch, "put %s %s\n", sftpQuote(file), sftpQuote(remote))
+
+	if err := runCLI(ctx, strings.NewReader(batch.String()), "sftp", append(s.args, s.dest)...); err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		s.made[dir] = true
+	}
+
+	return nil
+}
+
+// sftpQuote quotes p for an sftp batch file.
+func sftpQuote(p string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p) + `"`
+}
@@ -0,0 +1,87 @@
+package lld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// normalizeTarget checks what's to be downloaded, the course of -course or the video of -video, turning either into its
+// canonical URL.
+func normalizeTarget(opts *options) error {
+	var err error
+	switch {
+	case opts.videoURL != "" && opts.courseURL != "":
+		return errors.New("give either -course or -video, not both")
+	case opts.videoURL != "":
+		opts.videoURL, err = normalizeVideoURL(opts.learningBase, opts.videoURL)
+	default:
+		opts.courseURL, err = normalizeCourseURL(opts.learningBase, opts.courseURL)
+	}
+
+	return err
+}
+
+// downloadSingle downloads just the item videoURL links to, for -video. Its section and number, and so its file name,
+// come from the table of contents next to it, without visiting the rest of the course. It's saved into the course's
+// directory, as a download of the whole course would be, and an index.json of the course already downloaded there is
+// refreshed to include it.
+func downloadSingle(ctx context.Context, opts *options, videoURL string) (err error) {
+	courseURL, err := normalizeCourseURL(opts.learningBase, videoURL)
+	if err != nil {
+		return err
+	}
+	ctx, span := startSpan(ctx, "course", "url", courseURL)
+	defer span.end(&err)
+	ctx = withLogFields(ctx, "course", courseURL)
+
+	slug, err := courseSlug(opts.learningBase, courseURL)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(opts.outDir, slug)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	// The classroom page of a video shows the whole table of contents too.
+	videos, err := parseCourseVideos(ctx, opts, videoURL, dir)
+	if err != nil {
+		return fmt.Errorf("failed to extract video links: %w", err)
+	}
+	video, err := findVideo(videos, videoURL)
+	if err != nil {
+		return err
+	}
+	logf(ctx, tr("🎯 Found %s in section %s\n"), video.Title, video.Section)
+
+	failures := processVideos(ctx, []VideoEntry{video}, opts)
+	if _, err := os.Stat(filepath.Join(dir, "course.json")); err == nil {
+		if err := refreshIndex(ctx, dir, failures, opts); err != nil {
+			logln(ctx, errorText(err))
+		}
+	}
+	if err := failures[video.filename]; err != nil {
+		return &itemsError{errs: []error{err}}
+	}
+
+	return nil
+}
+
+// findVideo returns the item of the table of contents videoURL links to.
+func findVideo(videos []VideoEntry, videoURL string) (VideoEntry, error) {
+	want, err := url.Parse(videoURL)
+	if err != nil {
+		return VideoEntry{}, fmt.Errorf("bad url: %w", err)
+	}
+	for _, v := range videos {
+		if u, err := url.Parse(v.Href); err == nil && path.Base(u.Path) == path.Base(want.Path) {
+			return v, nil
+		}
+	}
+
+	return VideoEntry{}, fmt.Errorf("%s isn't in the course's table of contents (was it removed?)", videoURL)
+}
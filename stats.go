@@ -0,0 +1,41 @@
+package lld
+
+import (
+	"context"
+	"strings"
+)
+
+// readingWPM is the average adult's silent reading speed, in words per minute.
+const readingWPM = 238
+
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
+// readingMinutes is how long words take to read, rounded up to whole minutes.
+func readingMinutes(words int) int {
+	return (words + readingWPM - 1) / readingWPM
+}
+
+// saveReadingStats counts the words of every transcript saved for the course, including those of earlier runs, and
+// reports the total so the course can be planned into study sessions, adding them to course.json.
+func saveReadingStats(ctx context.Context, course *Course, videos []VideoEntry, dir string) error {
+	course.Words, course.ReadingMinutes = 0, 0
+	transcripts := 0
+	for i, v := range videos {
+		words := countWords(loadTranscript(v.filename))
+		if words == 0 {
+			continue
+		}
+		transcripts++
+		videos[i].Words, videos[i].ReadingMinutes = words, readingMinutes(words)
+		course.Words += words
+	}
+	if transcripts == 0 {
+		return nil
+	}
+	course.ReadingMinutes = readingMinutes(course.Words)
+	logf(ctx, tr("📖 %d words across %d transcript(s), about %d min of reading\n"), course.Words, transcripts, course.ReadingMinutes)
+
+	return writeCourseJSON(ctx, course, dir)
+}
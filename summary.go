@@ -0,0 +1,175 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// keyPointsPerVideo is how many sentences are picked from each transcript.
+const keyPointsPerVideo = 3
+
+var sentenceRE = regexp.MustCompile(`[^.!?]+[.!?]+`)
+
+// loadTranscript reads back the transcript saved for the item at filename (see loadTranscriptEntry).
+func loadTranscript(filename string) string {
+	v, _ := loadTranscriptEntry(filename)
+
+	return v.Transcript
+}
+
+// loadTranscriptEntry reads back the item saved at filename with its transcript and captions, from its .json, .jsonl,
+// or the JSON copy of its .txt. The .txt itself isn't read, as -transcript-template decides what's in it; without
+// the copy (saved before there was one), the transcript is made from the .vtt captions, if there are any.
+func loadTranscriptEntry(filename string) (VideoEntry, bool) {
+	var v VideoEntry
+	for _, ext := range []string{".json", transcriptDataExt} {
+		if b, err := os.ReadFile(filename + ext); err == nil {
+			if json.Unmarshal(b, &v) == nil {
+				return v, true
+			}
+		}
+	}
+	if f, err := os.Open(filename + ".jsonl"); err == nil {
+		defer f.Close()
+		dec := json.NewDecoder(f)
+		if dec.Decode(&v) == nil { // The first line; the captions follow.
+			for {
+				var cue Cue
+				if dec.Decode(&cue) != nil {
+					break
+				}
+				v.Captions = append(v.Captions, cue)
+			}
+			return v, true
+		}
+	}
+	if cues, err := parseVTT(filename + ".vtt"); err == nil && len(cues) > 0 {
+		lines := make([]string, len(cues))
+		for i, cue := range cues {
+			lines[i] = cue.Text
+		}
+		v.Transcript, v.Captions = strings.Join(lines, "\n"), cues
+		return v, true
+	}
+
+	return v, false
+}
+
+// writeSummaries writes a Section_N_summary.md per section with the key points of each of its videos.
+func writeSummaries(ctx context.Context, videos []VideoEntry, dir string) error {
+	n, section := 0, ""
+	var sb strings.Builder
+	flush := func() error {
+		if n == 0 {
+			return nil
+		}
+		filename := filepath.Join(dir, fmt.Sprintf("Section_%d_summary.md", n))
+		if err := os.WriteFile(filename, []byte(sb.String()), 0o600); err != nil {
+			return fmt.Errorf("failed to create file %s: %w", filename, err)
+		}
+		logf(ctx, tr("💾 summary saved: %s\n"), filename)
+
+		return nil
+	}
+
+	for _, v := range videos {
+		if v.Section != section || n == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			n++
+			section = v.Section
+			sb.Reset()
+			fmt.Fprintf(&sb, "# Section %d: %s\n", n, section)
+		}
+		points := keyPoints(loadTranscript(v.filename), keyPointsPerVideo)
+		if len(points) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n## %d. %s\n\n", v.Index, v.Title)
+		for _, p := range points {
+			sb.WriteString("- " + p + "\n")
+		}
+	}
+
+	return flush()
+}
+
+// tokenize splits s into lowercase words.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// keywordCounts counts the words of text that say something about it: those that aren't stop words in any language,
+// nor too short to.
+func keywordCounts(text string) map[string]int {
+	common := map[string]bool{}
+	for _, words := range stopwords() {
+		for _, w := range words {
+			common[w] = true
+		}
+	}
+	freq := map[string]int{}
+	for _, w := range tokenize(text) {
+		if len(w) > 2 && !common[w] {
+			freq[w]++
+		}
+	}
+
+	return freq
+}
+
+// keyPoints picks the n sentences of text that best cover its most frequent words, in their original order.
+func keyPoints(text string, n int) []string {
+	sentences := sentenceRE.FindAllString(strings.ReplaceAll(text, "\n", " "), -1)
+	if len(sentences) <= n {
+		out := make([]string, 0, len(sentences))
+		for _, s := range sentences {
+			out = append(out, strings.TrimSpace(s))
+		}
+
+		return out
+	}
+
+	freq := keywordCounts(text)
+
+	type scored struct {
+		i     int
+		score float64
+	}
+	scores := make([]scored, len(sentences))
+	for i, s := range sentences {
+		words := tokenize(s)
+		total := 0
+		for _, w := range words {
+			total += freq[w]
+		}
+		// Normalize so long rambling sentences don't win by length alone.
+		scores[i] = scored{i: i, score: float64(total) / float64(len(words)+5)}
+	}
+	slices.SortStableFunc(scores, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	picked := scores[:n]
+	slices.SortFunc(picked, func(a, b scored) int { return a.i - b.i })
+
+	out := make([]string, 0, n)
+	for _, p := range picked {
+		out = append(out, strings.TrimSpace(sentences[p.i]))
+	}
+
+	return out
+}
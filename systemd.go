@@ -0,0 +1,60 @@
+package lld
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotify sends a state update (e.g. "READY=1") to systemd when running as a Type=notify service.
+// It's a no-op when $NOTIFY_SOCKET isn't set.
+func sdNotify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	if socket[0] == '@' {
+		// Abstract namespace socket.
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		log.Printf(tr("⚠️ failed to notify systemd: %v"), err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		log.Printf(tr("⚠️ failed to notify systemd: %v"), err)
+	}
+}
+
+// sdWatchdog pings the systemd watchdog at half of WatchdogSec until ctx is done. It's a no-op without $WATCHDOG_USEC.
+func sdWatchdog(ctx context.Context) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(usec) * time.Microsecond / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sdNotify("WATCHDOG=1")
+		}
+	}
+}
+
+func writePIDFile(filename string) error {
+	return os.WriteFile(filename, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o600)
+}
@@ -0,0 +1,49 @@
+package lld
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// defaultTranscriptTemplate renders the text transcript when there's no -transcript-template.
+const defaultTranscriptTemplate = `URL: {{.Href}}
+Section: {{.Section}}
+Title: {{.Title}}
+Index: {{.Index}}
+Duration: {{.Duration}}
+{{with .Language}}Language: {{.}}
+{{end}}Transcript:
+{{.Transcript}}
+`
+
+// setupTranscriptTemplate parses the -transcript-template file, or else the default one.
+func setupTranscriptTemplate(opts *options) error {
+	text := defaultTranscriptTemplate
+	if opts.transcriptTemplate != "" {
+		b, err := os.ReadFile(opts.transcriptTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", opts.transcriptTemplate, err)
+		}
+		text = string(b)
+	}
+	t, err := template.New("transcript").Funcs(transcriptFuncs()).Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse the transcript template: %w", err)
+	}
+	opts.transcriptTmpl = t
+
+	return nil
+}
+
+// transcriptFuncs are the functions available to transcript templates, besides the built-in ones.
+func transcriptFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":      strings.Join,
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"trim":      strings.TrimSpace,
+		"timestamp": vttTime, // Of a cue's Start or End, e.g. 00:01:02.500.
+	}
+}
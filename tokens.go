@@ -0,0 +1,108 @@
+package lld
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// tokenCookie carries the API token of the dashboard, as its event streams and screenshots can't send headers.
+const tokenCookie = "lld_token"
+
+// permissionError is a request for something the caller isn't allowed to do.
+type permissionError struct {
+	msg string
+}
+
+func (e *permissionError) Error() string {
+	return e.msg
+}
+
+type callerKey struct{}
+
+// callerFrom returns the user who made the request, by their token: "" is the daemon itself, whose token (or anybody,
+// when no tokens are configured) runs jobs in the daemon's own session and manages everyone's jobs.
+func callerFrom(ctx context.Context) string {
+	user, _ := ctx.Value(callerKey{}).(string)
+	return user
+}
+
+// checkTokens checks every user of a -config file has a token of their own, as it's who they are to the API.
+func checkTokens(token string, users map[string]serverUser) error {
+	seen := map[string]bool{token: token != ""}
+	for name, u := range users {
+		if u.Token == "" {
+			return fmt.Errorf("user %q has no token", name)
+		}
+		if seen[u.Token] {
+			return fmt.Errorf("user %q has the same token as another", name)
+		}
+		seen[u.Token] = true
+	}
+
+	return nil
+}
+
+// authenticate lets through requests with a bearer token (or the dashboard's cookie) of the daemon or one of its
+// users, as the caller. Without any tokens configured, everyone is let through as the daemon.
+func (s *server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := s.caller(r)
+		if err != nil {
+			if strings.HasPrefix(r.URL.Path, grpcPath) {
+				w.Header().Set("Content-Type", "application/grpc")
+				writeGRPCStatus(w, grpcUnauthenticated, err.Error())
+				return
+			}
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, user)))
+	})
+}
+
+// caller returns the user whose token r carries.
+func (s *server) caller(r *http.Request) (string, error) {
+	s.mu.Lock()
+	token, users := s.token, s.users
+	s.mu.Unlock()
+	if token == "" && len(users) == 0 {
+		return "", nil
+	}
+
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	// The cookie is only taken for reading, as browsers send it along with requests that other sites make.
+	if !ok && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		if c, err := r.Cookie(tokenCookie); err == nil {
+			got, _ = url.PathUnescape(c.Value)
+		}
+	}
+	if got == "" {
+		return "", errors.New("a bearer token is required")
+	}
+	if token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+		return "", nil
+	}
+	for name, u := range users {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(u.Token)) == 1 {
+			return name, nil
+		}
+	}
+
+	return "", errors.New("invalid token")
+}
+
+// owns checks the caller may see and change j: it's theirs, or they're the daemon. To anyone else it isn't there.
+func owns(ctx context.Context, j *Job) error {
+	if caller := callerFrom(ctx); caller != "" && j.User != caller {
+		return &permissionError{msg: "job belongs to another user"}
+	}
+
+	return nil
+}
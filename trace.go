@@ -0,0 +1,220 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tracer exports the spans of the run with -otlp-endpoint, and is nil (making every span a no-op) without it, or once
+// it's shut down.
+var tracer atomic.Pointer[spanExporter] //nolint:gochecknoglobals // Set from -otlp-endpoint.
+
+// span is a timed step of the run, e.g. visiting a video, in a trace of the whole run.
+type span struct {
+	traceID  [16]byte
+	id       [8]byte
+	parentID [8]byte
+	name     string
+	attrs    []string // Key, value, key, value...
+	start    time.Time
+	exporter *spanExporter
+}
+
+type spanKey struct{}
+
+// setupTracing starts exporting spans to the -otlp-endpoint, which defaults to the standard OTEL_EXPORTER_OTLP_*
+// environment variables.
+func setupTracing(opts *options) {
+	endpoint := opts.otlpEndpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint == "" && base != "" {
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	if endpoint == "" || tracer.Load() != nil {
+		return
+	}
+	e := newSpanExporter(endpoint)
+	if !tracer.CompareAndSwap(nil, e) {
+		close(e.stop)
+	}
+}
+
+// startSpan starts a span named name as a child of the one in ctx, if any, returning the context to start its
+// children with. attrs are pairs of keys and values.
+func startSpan(ctx context.Context, name string, attrs ...string) (context.Context, *span) {
+	e := tracer.Load()
+	if e == nil {
+		return ctx, nil
+	}
+	s := &span{name: name, attrs: attrs, start: time.Now(), exporter: e}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.traceID, s.parentID = parent.traceID, parent.id
+	} else {
+		_, _ = rand.Read(s.traceID[:])
+	}
+	_, _ = rand.Read(s.id[:])
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// end ends the span, as failed if *err isn't nil. It's meant to be deferred, hence the pointer.
+func (s *span) end(err *error) {
+	if s == nil {
+		return
+	}
+	s.exporter.add(s.otlp(time.Now(), *err))
+}
+
+// otlpSpan is a span in the JSON encoding of OTLP.
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 1 is OK, 2 is an error.
+	Message string `json:"message,omitempty"`
+}
+
+func (s *span) otlp(end time.Time, err error) otlpSpan {
+	o := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.id[:]),
+		Name:              s.name,
+		Kind:              1, // Internal.
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		Status:            otlpStatus{Code: 1},
+	}
+	if s.parentID != [8]byte{} {
+		o.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	for i := 0; i+1 < len(s.attrs); i += 2 {
+		a := otlpAttribute{Key: s.attrs[i]}
+		a.Value.StringValue = s.attrs[i+1]
+		o.Attributes = append(o.Attributes, a)
+	}
+	if err != nil {
+		o.Status = otlpStatus{Code: 2, Message: err.Error()}
+	}
+
+	return o
+}
+
+// spanExporter sends the ended spans to an OTLP/HTTP collector in batches, every few seconds.
+type spanExporter struct {
+	endpoint string
+	client   *http.Client
+	mu       sync.Mutex
+	batch    []otlpSpan
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+func newSpanExporter(endpoint string) *spanExporter {
+	e := &spanExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go e.run()
+
+	return e
+}
+
+func (e *spanExporter) add(s otlpSpan) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.batch = append(e.batch, s)
+}
+
+func (e *spanExporter) run() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.flush()
+		case <-e.stop:
+			e.flush()
+			return
+		}
+	}
+}
+
+// flush sends the spans ended since the last flush.
+func (e *spanExporter) flush() {
+	e.mu.Lock()
+	batch := e.batch
+	e.batch = nil
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	body := map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{"attributes": []any{map[string]any{
+				"key": "service.name", "value": map[string]any{"stringValue": "lld"},
+			}}},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": "lld", "version": currentVersion()},
+				"spans": batch,
+			}},
+		}},
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		log.Printf(tr("⚠️ failed to export traces: %v"), err)
+		return
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(b))
+	if err != nil {
+		log.Printf(tr("⚠️ failed to export traces: %v"), err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		log.Printf(tr("⚠️ failed to export traces: %v"), newStatusError(resp))
+	}
+}
+
+// shutdownTracing sends the spans that are left, before exiting or closing a Client. Spans ended after it are dropped,
+// and calling it again does nothing.
+func shutdownTracing() {
+	e := tracer.Swap(nil)
+	if e == nil {
+		return
+	}
+	close(e.stop)
+	<-e.stopped
+}
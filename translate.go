@@ -0,0 +1,188 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// translator is a machine translation backend for -translate.
+type translator interface {
+	// translate translates texts into the target language (ISO 639-1), keeping their order.
+	translate(ctx context.Context, texts []string, target string) ([]string, error)
+}
+
+// translateBatch is how many texts go in one request, which keeps within every backend's limits.
+const translateBatch = 50
+
+// newTranslator picks the -translate-backend. The API key comes from $LLD_TRANSLATE_KEY, so it stays out of ps.
+func newTranslator(backend, endpoint string) (translator, error) {
+	key := os.Getenv("LLD_TRANSLATE_KEY")
+	switch backend {
+	case "deepl":
+		if endpoint == "" {
+			// Free API keys end in :fx and have their own host.
+			endpoint = "https://api.deepl.com/v2/translate"
+			if strings.HasSuffix(key, ":fx") {
+				endpoint = "https://api-free.deepl.com/v2/translate"
+			}
+		}
+		return &deepL{endpoint: endpoint, key: key}, nil
+	case "google":
+		if endpoint == "" {
+			endpoint = "https://translation.googleapis.com/language/translate/v2"
+		}
+		return &googleTranslate{endpoint: endpoint, key: key}, nil
+	case "libretranslate":
+		if endpoint == "" {
+			endpoint = "https://libretranslate.com"
+		}
+		return &libreTranslate{endpoint: strings.TrimSuffix(endpoint, "/") + "/translate", key: key}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported -translate-backend %q, expected deepl, google, or libretranslate", backend)
+}
+
+// translateTranscript writes the transcript (and captions) of video translated into -translate, as
+// <name>.<lang>.txt/json and <name>.<lang>.vtt.
+func translateTranscript(ctx context.Context, video VideoEntry, opts *options) error {
+	lang := opts.translate
+	if video.Language == lang {
+		return nil
+	}
+	lines := strings.Split(video.Transcript, "\n")
+	texts := make([]string, 0, len(lines)+len(video.Captions))
+	texts = append(texts, lines...)
+	for _, c := range video.Captions {
+		texts = append(texts, c.Text)
+	}
+
+	out := make([]string, 0, len(texts))
+	for start := 0; start < len(texts); start += translateBatch {
+		got, err := opts.translator.translate(ctx, texts[start:min(start+translateBatch, len(texts))], lang)
+		if err != nil {
+			return fmt.Errorf("failed to translate: %w", err)
+		}
+		out = append(out, got...)
+	}
+	if len(out) != len(texts) {
+		return fmt.Errorf("failed to translate: got %d texts back for %d", len(out), len(texts))
+	}
+
+	translated := video
+	translated.Language = lang
+	translated.Transcript = strings.Join(out[:len(lines)], "\n")
+	translated.Captions = make([]Cue, len(video.Captions))
+	for i, c := range video.Captions {
+		translated.Captions[i] = Cue{Start: c.Start, End: c.End, Text: out[len(lines)+i]}
+	}
+	if err := writeTranscript(ctx, translated, video.filename+"."+lang, opts); err != nil {
+		return err
+	}
+	if len(translated.Captions) > 0 {
+		if err := writeVTT(ctx, video.filename+"."+lang+".vtt", translated.Captions, lang); err != nil {
+			return err
+		}
+	}
+	logf(ctx, tr("🌐 Translated into %s: %s\n"), lang, video.Title)
+
+	return nil
+}
+
+// postJSON posts req as JSON and decodes the response into resp.
+func postJSON(ctx context.Context, endpoint string, header http.Header, req, resp any) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		r.Header[k] = vs
+	}
+	r.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return newStatusError(res)
+	}
+
+	return json.NewDecoder(res.Body).Decode(resp)
+}
+
+type deepL struct {
+	endpoint, key string
+}
+
+func (d *deepL) translate(ctx context.Context, texts []string, target string) ([]string, error) {
+	var resp struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	req := map[string]any{"text": texts, "target_lang": strings.ToUpper(target)}
+	if err := postJSON(ctx, d.endpoint, http.Header{"Authorization": {"DeepL-Auth-Key " + d.key}}, req, &resp); err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(resp.Translations))
+	for _, t := range resp.Translations {
+		out = append(out, t.Text)
+	}
+
+	return out, nil
+}
+
+type googleTranslate struct {
+	endpoint, key string
+}
+
+func (g *googleTranslate) translate(ctx context.Context, texts []string, target string) ([]string, error) {
+	var resp struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	endpoint := g.endpoint + "?key=" + url.QueryEscape(g.key)
+	if err := postJSON(ctx, endpoint, nil, map[string]any{"q": texts, "target": target, "format": "text"}, &resp); err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(resp.Data.Translations))
+	for _, t := range resp.Data.Translations {
+		out = append(out, t.TranslatedText)
+	}
+
+	return out, nil
+}
+
+type libreTranslate struct {
+	endpoint, key string
+}
+
+func (l *libreTranslate) translate(ctx context.Context, texts []string, target string) ([]string, error) {
+	var resp struct {
+		TranslatedText []string `json:"translatedText"`
+	}
+	req := map[string]any{"q": texts, "source": "auto", "target": target, "format": "text"}
+	if l.key != "" {
+		req["api_key"] = l.key
+	}
+	if err := postJSON(ctx, l.endpoint, nil, req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.TranslatedText, nil
+}
@@ -0,0 +1,134 @@
+package lld
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transliterate spells s in ASCII where it knows how: Latin letters lose their accents (German umlauts become ae, oe,
+// ue), and Cyrillic and Japanese kana are romanized. Anything else, like kanji, which can't be read without a
+// dictionary, is left alone.
+func transliterate(s string) string {
+	var sb strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r <= unicode.MaxASCII {
+			sb.WriteRune(r)
+			continue
+		}
+		if kana, n := romaji(runes[i:]); n > 0 {
+			sb.WriteString(kana)
+			i += n - 1
+			continue
+		}
+		latin, ok := translitTable()[unicode.ToLower(r)]
+		if !ok {
+			sb.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(r) && latin != "" {
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		sb.WriteString(latin)
+	}
+
+	return sb.String()
+}
+
+// translitTable maps lowercase Latin and Cyrillic letters to ASCII.
+func translitTable() map[rune]string {
+	return map[rune]string{
+		// German first, as it has its own conventions.
+		'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss",
+		'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+		'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+		'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+		'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+		'ł': "l", 'ľ': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+		'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+		'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ș': "s", 'ť': "t", 'ț': "t", 'þ': "th",
+		'ù': "u", 'ú': "u", 'û': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+		'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+		// Russian, and Ukrainian's extra letters.
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z",
+		'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+		'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+		'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+		'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+	}
+}
+
+// kanaTable maps hiragana to Hepburn romaji; katakana is looked up as the matching hiragana.
+func kanaTable() map[rune]string {
+	return map[rune]string{
+		'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+		'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+		'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+		'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+		'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+		'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+		'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+		'や': "ya", 'ゆ': "yu", 'よ': "yo",
+		'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+		'わ': "wa", 'を': "o", 'ん': "n",
+		'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+		'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+		'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+		'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+		'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+		'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゔ': "vu",
+	}
+}
+
+// toHiragana folds katakana onto hiragana, which sits 0x60 below it.
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 0x60
+	}
+
+	return r
+}
+
+// romaji romanizes the kana at the start of runes, returning it and how many runes it took, or 0 if it isn't kana.
+// It handles the contracted sounds (きゃ is kya), the small tsu doubling the next consonant, and the long vowel mark.
+func romaji(runes []rune) (string, int) {
+	kana := kanaTable()
+	switch toHiragana(runes[0]) {
+	case 'っ':
+		// A doubled consonant, like きって (kitte), but ch is written tch.
+		if len(runes) < 2 {
+			return "", 1
+		}
+		next, n := romaji(runes[1:])
+		if next == "" {
+			return "", 1
+		}
+		if strings.HasPrefix(next, "ch") {
+			return "t" + next, n + 1
+		}
+		return next[:1] + next, n + 1
+	case 'ー':
+		// Just lengthens the vowel before it, which the romaji already has.
+		return "", 1
+	}
+
+	syllable, ok := kana[toHiragana(runes[0])]
+	if !ok {
+		return "", 0
+	}
+	if len(runes) < 2 || !strings.HasSuffix(syllable, "i") || len(syllable) < 2 {
+		return syllable, 1
+	}
+	small := map[rune]string{'ゃ': "a", 'ゅ': "u", 'ょ': "o"}
+	vowel, ok := small[toHiragana(runes[1])]
+	if !ok {
+		return syllable, 1
+	}
+	stem := strings.TrimSuffix(syllable, "i")
+	if stem != "sh" && stem != "ch" && stem != "j" {
+		stem += "y"
+	}
+
+	return stem + vowel, 2
+}
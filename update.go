@@ -0,0 +1,304 @@
+package lld
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version is set by goreleaser at build time.
+var version = "dev" //nolint:gochecknoglobals // Set via -ldflags.
+
+const latestReleaseURL = "https://api.github.com/repos/jh125486/lld/releases/latest"
+
+type release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func currentVersion() string {
+	if version != "dev" {
+		return version
+	}
+	// Fall back to the module version for `go install` builds.
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	return version
+}
+
+func runVersion(args []string) {
+	var check bool
+	flags := flag.NewFlagSet("version", flag.ExitOnError)
+	flags.BoolVar(&check, "check", false, "Whether or not to check GitHub for a newer release.")
+	_ = flags.Parse(args)
+
+	fmt.Println("lld", currentVersion())
+	if !check {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	rel, err := latestRelease(ctx)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	if newerVersion(rel.TagName, currentVersion()) {
+		log.Printf(tr("⬆️ A newer version is available: %s (%s). Run `lld self-update` to install it.\n"), rel.TagName, rel.HTMLURL)
+		return
+	}
+	log.Println(tr("✅ You're running the latest version."))
+}
+
+func runSelfUpdate(args []string) {
+	var force bool
+	flags := flag.NewFlagSet("self-update", flag.ExitOnError)
+	flags.BoolVar(&force, "force", false, "Whether or not to reinstall even when already up to date.")
+	_ = flags.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	rel, err := latestRelease(ctx)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	if !force && !newerVersion(rel.TagName, currentVersion()) {
+		log.Println(tr("✅ You're running the latest version."))
+		return
+	}
+
+	log.Printf(tr("⬇️ Updating to %s...\n"), rel.TagName)
+	if err := selfUpdate(ctx, rel); err != nil {
+		log.Fatal(errorText(err))
+	}
+	log.Printf(tr("✅ Updated to %s.\n"), rel.TagName)
+}
+
+func latestRelease(ctx context.Context) (*release, error) {
+	b, err := fetchURL(ctx, latestReleaseURL)
+	if err != nil {
+		return nil, err
+	}
+	var rel release
+	if err := json.Unmarshal(b, &rel); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", latestReleaseURL, err)
+	}
+
+	return &rel, nil
+}
+
+// selfUpdate downloads the release archive for this platform, checks it against checksums.txt, and swaps the binary.
+// A release without checksums.txt is refused, rather than installed unchecked.
+func selfUpdate(ctx context.Context, rel *release) error {
+	name := archiveName()
+	var archiveURL, checksumsURL string
+	for _, a := range rel.Assets {
+		switch a.Name {
+		case name:
+			archiveURL = a.URL
+		case "checksums.txt":
+			checksumsURL = a.URL
+		}
+	}
+	if archiveURL == "" {
+		return fmt.Errorf("no release asset %s for this platform", name)
+	}
+	if checksumsURL == "" {
+		return fmt.Errorf("release %s has no checksums.txt to verify %s with, not updating", rel.TagName, name)
+	}
+
+	archive, err := fetchURL(ctx, archiveURL)
+	if err != nil {
+		return err
+	}
+	sums, err := fetchURL(ctx, checksumsURL)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksum(archive, name, sums); err != nil {
+		return err
+	}
+
+	bin, err := extractBinary(archive, name)
+	if err != nil {
+		return err
+	}
+
+	return replaceExecutable(bin)
+}
+
+// archiveName mirrors the name_template in .goreleaser.yaml.
+func archiveName() string {
+	arch := runtime.GOARCH
+	if arch == "amd64" {
+		arch = "x86_64"
+	}
+	ext := ".tar.gz"
+	if runtime.GOOS == "windows" {
+		ext = ".zip"
+	}
+
+	return "lld_" + strings.ToUpper(runtime.GOOS[:1]) + runtime.GOOS[1:] + "_" + arch + ext
+}
+
+func verifyChecksum(archive []byte, name string, sums []byte) error {
+	sum := sha256.Sum256(archive)
+	want := ""
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) == 2 && fields[1] == name {
+			want = fields[0]
+		}
+	}
+	if want != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("checksum mismatch for %s", name)
+	}
+
+	return nil
+}
+
+func extractBinary(archive []byte, name string) ([]byte, error) {
+	bin := "lld"
+	if runtime.GOOS == "windows" {
+		bin = "lld.exe"
+	}
+
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) == bin {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", name, err)
+				}
+				defer func() {
+					_ = rc.Close()
+				}()
+				return io.ReadAll(rc)
+			}
+		}
+		return nil, fmt.Errorf("%s not found in %s", bin, name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	tarReader := tar.NewReader(gz)
+	for {
+		hdr, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if filepath.Base(hdr.Name) == bin {
+			return io.ReadAll(tarReader)
+		}
+	}
+
+	return nil, fmt.Errorf("%s not found in %s", bin, name)
+}
+
+// replaceExecutable writes the new binary next to the running one and renames it into place.
+// The old binary is moved aside first, since Windows refuses to overwrite a running executable.
+func replaceExecutable(bin []byte) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+
+	tmp := exe + ".new"
+	if err := os.WriteFile(tmp, bin, 0o755); err != nil { //nolint:gosec // It's an executable.
+		return fmt.Errorf("failed to create file %s: %w", tmp, err)
+	}
+	old := exe + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		_ = os.Rename(old, exe)
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	_ = os.Remove(old) // Fails harmlessly on Windows while we're still running.
+
+	return nil
+}
+
+func fetchURL(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// newerVersion reports whether semver a is newer than b; anything unparsable (like "dev") is treated as oldest.
+func newerVersion(a, b string) bool {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return pa[i] > pb[i]
+		}
+	}
+
+	return false
+}
+
+func parseVersion(v string) [3]int {
+	var out [3]int
+	v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	for i, p := range strings.SplitN(v, ".", 3) {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return [3]int{}
+		}
+		out[i] = n
+	}
+
+	return out
+}
@@ -0,0 +1,106 @@
+package lld
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// uploader is a remote storage backend for -upload.
+type uploader interface {
+	// put uploads the local file to rel (slash separated) under the remote root.
+	put(ctx context.Context, file, rel string) error
+}
+
+// newUploader picks the backend by the scheme of the -upload URL.
+func newUploader(raw string) (uploader, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("bad url: %w", err)
+	}
+	switch u.Scheme {
+	case "webdav", "webdavs":
+		return newWebDAV(u), nil
+	case "sftp":
+		return newSFTP(u), nil
+	case "az":
+		return newAzure(u), nil
+	case "gs":
+		return newGCS(u), nil
+	}
+
+	return nil, fmt.Errorf("unsupported -upload scheme %q", u.Scheme)
+}
+
+// uploadPassword is the password for u, from the URL or else $LLD_UPLOAD_PASSWORD, so it needn't show up in ps.
+func uploadPassword(u *url.URL) string {
+	if p, ok := u.User.Password(); ok {
+		return p
+	}
+
+	return os.Getenv("LLD_UPLOAD_PASSWORD")
+}
+
+// uploadCourse copies everything saved in dir to <slug>/ under the -upload URL.
+func uploadCourse(ctx context.Context, opts *options, courseURL, dir string) error {
+	if opts.uploader == nil {
+		return nil
+	}
+	slug, err := courseSlug(opts.learningBase, courseURL)
+	if err != nil {
+		return err
+	}
+
+	logf(ctx, tr("☁️ Uploading %s\n"), dir)
+	// Items' files are only uploaded again when they changed since they were last uploaded there.
+	with := opts.upload
+	if u, err := url.Parse(opts.upload); err == nil {
+		with = u.Redacted()
+	}
+	checkpoints, uploaded := map[string]*checkpoint{}, map[*checkpoint]bool{}
+	err = walkCourseFiles(dir, "", func(file, rel string, info fs.FileInfo) error {
+		cp := itemCheckpoint(checkpoints, file)
+		if cp != nil && cp.uploadedSince(with, info.ModTime()) {
+			return nil
+		}
+		if err := opts.uploader.put(ctx, file, slug+"/"+rel); err != nil {
+			return inStage(stageUpload, fmt.Errorf("failed to upload %s: %w", file, err))
+		}
+		uploaded[cp] = true
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for cp := range uploaded {
+		cp.mark(ctx, stageUpload, with)
+	}
+
+	return nil
+}
+
+// runCLI runs an external client, folding its stderr into the error.
+func runCLI(ctx context.Context, stdin io.Reader, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+
+	return runCmd(cmd)
+}
+
+// runCmd runs cmd, folding its stderr into the error.
+func runCmd(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
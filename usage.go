@@ -0,0 +1,150 @@
+package lld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// errBudget is why items are skipped once -max-bytes have been downloaded.
+var errBudget = errors.New("-max-bytes reached")
+
+// meter counts the bytes downloaded in a run, against the -max-bytes budget, if any.
+type meter struct {
+	bytes atomic.Int64
+	max   int64 // Zero for no limit.
+}
+
+// Usage is how much has been downloaded over every run, kept in usage.json in lld's config directory.
+type Usage struct {
+	Bytes   int64     `json:"bytes"`
+	Runs    int       `json:"runs"`
+	Since   time.Time `json:"since"`
+	Updated time.Time `json:"updated"`
+}
+
+// setupMeter parses -max-bytes, like 500M or 2.5G, into the run's meter.
+func setupMeter(opts *options) error {
+	opts.meter = &meter{}
+	if opts.maxBytes == "" {
+		return nil
+	}
+	n, err := parseByteSize(opts.maxBytes)
+	if err != nil || n < 0 {
+		return fmt.Errorf("unsupported -max-bytes %q, expected a size like 500M or 2.5G", opts.maxBytes)
+	}
+	opts.meter.max = n
+
+	return nil
+}
+
+// parseByteSize parses a number of bytes with an optional decimal unit (k, M, G, or T, with or without a B), as
+// byteSize writes them.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	unit := 1.0
+	for _, u := range "KMGT" {
+		unit *= 1000
+		if n, ok := strings.CutSuffix(s, string(u)); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			return int64(f * unit), err
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+
+	return int64(n), err
+}
+
+// add counts n more bytes downloaded.
+func (m *meter) add(n int64) {
+	if m != nil {
+		m.bytes.Add(n)
+	}
+}
+
+// total is how many bytes were downloaded so far in the run.
+func (m *meter) total() int64 {
+	if m == nil {
+		return 0
+	}
+
+	return m.bytes.Load()
+}
+
+// exhausted is whether the run has downloaded all its -max-bytes allow.
+func (m *meter) exhausted() bool {
+	return m != nil && m.max > 0 && m.total() >= m.max
+}
+
+// counted wraps a response body, counting what's read from it.
+func (m *meter) counted(body io.ReadCloser) io.ReadCloser {
+	if m == nil {
+		return body
+	}
+
+	return &countingBody{ReadCloser: body, m: m}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	m *meter
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.m.add(int64(n))
+
+	return n, err
+}
+
+// usageFile is where the lifetime usage is kept: lld/usage.json in the user's config directory.
+func usageFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "lld", "usage.json"), nil
+}
+
+// reportUsage adds the run's downloads to the lifetime usage, and logs both.
+func reportUsage(opts *options) {
+	run := opts.meter.total()
+	filename, err := usageFile()
+	if err != nil {
+		log.Printf(tr("📶 Downloaded %s\n"), byteSize(run))
+		return
+	}
+	var usage Usage
+	if b, err := os.ReadFile(filename); err == nil {
+		_ = json.Unmarshal(b, &usage)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		log.Printf(tr("⚠️ failed to read %s: %v\n"), filename, err)
+	}
+	now := time.Now().UTC()
+	if usage.Since.IsZero() {
+		usage.Since = now
+	}
+	usage.Bytes += run
+	usage.Runs++
+	usage.Updated = now
+	if err := os.MkdirAll(filepath.Dir(filename), 0o750); err == nil {
+		err = writeJSONFile(filename, usage)
+	}
+	if err != nil {
+		log.Printf(tr("⚠️ failed to save the download total: %v\n"), err)
+	}
+	log.Printf(tr("📶 Downloaded %s, %s in all since %s\n"), byteSize(run), byteSize(usage.Bytes), usage.Since.Format(time.DateOnly))
+	if opts.meter.exhausted() {
+		log.Printf(tr("⛔ Stopped at -max-bytes %s: run again (or lld retry) for the rest.\n"), byteSize(opts.meter.max))
+	}
+}
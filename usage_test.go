@@ -0,0 +1,49 @@
+package lld_test
+
+import (
+	"testing"
+
+	"github.com/jh125486/lld"
+)
+
+func TestParseByteSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s       string
+		want    int64
+		wantErr bool
+	}{
+		{s: "0", want: 0},
+		{s: "1024", want: 1024},
+		{s: "512B", want: 512},
+		{s: "500k", want: 500_000},
+		{s: "500K", want: 500_000},
+		{s: "500kB", want: 500_000},
+		{s: "500M", want: 500_000_000},
+		{s: "500 MB", want: 500_000_000},
+		{s: "2.5G", want: 2_500_000_000},
+		{s: " 2.5gb ", want: 2_500_000_000},
+		{s: "1.2 GB", want: 1_200_000_000},
+		{s: "3T", want: 3_000_000_000_000},
+		{s: "1e3", want: 1000},
+		{s: "", wantErr: true},
+		{s: "B", wantErr: true},
+		{s: "GB", wantErr: true},
+		{s: "lots", wantErr: true},
+		{s: "500MiB", wantErr: true},
+		{s: "5P", wantErr: true},
+		{s: "5 G B", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			t.Parallel()
+			got, err := lld.ParseByteSize(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseByteSize(%q) error = %v, want error %t", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseByteSize(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
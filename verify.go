@@ -0,0 +1,208 @@
+package lld
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// verifyReport is what lld verify found wrong in a course directory.
+type verifyReport struct {
+	problems map[string]string // By file, relative to the directory with forward slashes.
+	orphans  []string          // Temp files left behind by an interrupted run.
+	checked  int
+}
+
+func (r *verifyReport) bad(rel, problem string) {
+	if _, ok := r.problems[rel]; !ok {
+		r.problems[rel] = problem
+	}
+}
+
+// runVerify checks a course directory against its index.json and SHA256SUMS, optionally queuing repairs.
+func runVerify(args []string) {
+	var (
+		opts   options
+		repair bool
+	)
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s verify [flags] DIR\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	registerFlags(flags, &opts)
+	flags.BoolVar(&repair, "repair", false, "Whether or not to remove temp files, and queue damaged items in failed.json for lld retry.")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	dir := flags.Arg(0)
+	r, err := verifyDir(dir)
+	if err != nil {
+		log.Fatal(errorText(err))
+	}
+	for _, rel := range slices.Sorted(maps.Keys(r.problems)) {
+		log.Printf(tr("❌ %s: %s\n"), rel, r.problems[rel])
+	}
+	for _, rel := range r.orphans {
+		log.Printf(tr("🧹 %s: left over from an interrupted run\n"), rel)
+	}
+	if repair {
+		if err := repairDir(dir, r, &opts); err != nil {
+			log.Fatal(errorText(err))
+		}
+	}
+	if len(r.problems) > 0 {
+		log.Printf(tr("❌ %d of %d file(s) failed verification"), len(r.problems), r.checked)
+		os.Exit(exitCode(ErrCorrupt))
+	}
+	log.Printf(tr("✅ All %d file(s) verified.\n"), r.checked)
+}
+
+// verifyDir checks the files of the course in dir against the manifests it has: the size and checksum of everything in
+// index.json, and (with -checksums) SHA256SUMS. It also looks for empty media and temp files.
+func verifyDir(dir string) (*verifyReport, error) {
+	r := &verifyReport{problems: map[string]string{}}
+	index, indexErr := loadIndex(dir)
+	if indexErr == nil {
+		r.checked += verifyIndex(dir, index, r.bad)
+	}
+	total, sumsErr := verifyChecksums(dir, r.bad)
+	r.checked += total
+	if indexErr != nil && sumsErr != nil {
+		return nil, fmt.Errorf("nothing to verify %s against: %w", dir, errors.Join(indexErr, sumsErr))
+	}
+
+	err := walkFiles(dir, "", func(_, rel string, info fs.FileInfo) error {
+		switch {
+		case isTempFile(filepath.Base(rel)):
+			r.orphans = append(r.orphans, rel)
+		case info.Size() == 0 && slices.Contains([]string{itemVideo, itemAudio}, fileKind(rel)):
+			r.bad(rel, tr("empty file"))
+		}
+		return nil
+	})
+
+	return r, err
+}
+
+// verifyIndex checks every file listed in index exists with the size and checksum it was saved with, returning how
+// many there were.
+func verifyIndex(dir string, index *Index, bad func(rel, problem string)) int {
+	files := slices.Clone(index.Files)
+	for _, item := range index.Items {
+		files = append(files, item.Files...)
+	}
+	for _, f := range files {
+		file := filepath.Join(dir, filepath.FromSlash(f.Path))
+		info, err := os.Stat(file)
+		if err != nil {
+			bad(f.Path, tr("missing"))
+			continue
+		}
+		if info.Size() != f.Size {
+			bad(f.Path, fmt.Sprintf(tr("size %d, expected %d"), info.Size(), f.Size))
+			continue
+		}
+		if sum, err := hashFile(file); err != nil {
+			bad(f.Path, err.Error())
+		} else if sum != f.SHA256 {
+			bad(f.Path, tr("checksum mismatch"))
+		}
+	}
+
+	return len(files)
+}
+
+// isTempFile is whether name is a temp file written next to its final file, like by -embed-subs or an external
+// downloader, that's left behind when a run is interrupted.
+func isTempFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".part" || ext == ".tmp" || strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp")
+}
+
+// repairDir removes the temp files r found, and lists the items with damaged files in failed.json (along with the ones
+// already there) so lld retry downloads them again. The items are told apart by the files course.json records.
+func repairDir(dir string, r *verifyReport, opts *options) error {
+	for _, rel := range r.orphans {
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+	if len(r.problems) == 0 {
+		return nil
+	}
+
+	filename := filepath.Join(dir, "course.json")
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	var course Course
+	if err := json.Unmarshal(b, &course); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	restoreFilenames(course.Videos, dir, opts)
+
+	failedFile := filepath.Join(dir, failedFileName)
+	failed, err := loadFailed(failedFile)
+	if err != nil {
+		failed = &Failed{Course: course.URL}
+	}
+	queued := 0
+	for _, v := range course.Videos {
+		rel, err := filepath.Rel(dir, v.filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		problems := damaged(r.problems, rel)
+		if len(problems) == 0 || slices.ContainsFunc(failed.Failures, func(f Failure) bool { return f.File == rel }) {
+			continue
+		}
+		// Or lld retry would take the damaged files for done.
+		if err := os.Remove(checkpointFile(v.filename)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		failed.Failures = append(failed.Failures, Failure{
+			Video:     v,
+			File:      rel,
+			Stage:     stageVerify,
+			ErrorCode: errorCode(ErrCorrupt),
+			Error:     strings.Join(problems, "; "),
+		})
+		queued++
+	}
+	if queued == 0 {
+		log.Println(tr("⚠️ None of the damaged files belong to an item: download the course again to repair them."))
+		return nil
+	}
+	if err := writeJSONFile(failedFile, failed); err != nil {
+		return err
+	}
+	log.Printf(tr("💾 %d damaged item(s) queued in %s, repair them with: lld retry %s\n"), queued, failedFile, failedFile)
+
+	return nil
+}
+
+// damaged lists the problems of the files of the item saved as rel (plus an extension).
+func damaged(problems map[string]string, rel string) []string {
+	var out []string
+	for _, file := range slices.Sorted(maps.Keys(problems)) {
+		if strings.HasPrefix(file, rel+".") {
+			out = append(out, file+": "+problems[file])
+		}
+	}
+
+	return out
+}
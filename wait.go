@@ -0,0 +1,84 @@
+package lld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// defaultWaitTimeout is how long to wait for a page to show what's expected without -wait-timeout.
+	defaultWaitTimeout = 30 * time.Second
+	// pollInterval is how often a page is checked while waiting on it.
+	pollInterval = 250 * time.Millisecond
+)
+
+// errWaitTimeout is returned when a page didn't show what was waited for within -wait-timeout.
+var errWaitTimeout = errors.New("timed out")
+
+type waitTimeoutKey struct{}
+
+// withWaitTimeout returns ctx with how long its waits on the page may take, zero being for as long as it takes.
+func withWaitTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, waitTimeoutKey{}, d)
+}
+
+func waitTimeoutFrom(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(waitTimeoutKey{}).(time.Duration); ok {
+		return d
+	}
+
+	return defaultWaitTimeout
+}
+
+// poll checks done every pollInterval until it reports true, giving up with errWaitTimeout after -wait-timeout, so
+// fast pages aren't held up and slow ones are given the time they need.
+func poll(ctx context.Context, what string, done func(ctx context.Context) (bool, error)) error {
+	timeout := waitTimeoutFrom(ctx)
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := done(ctx)
+		if err != nil || ok {
+			return err
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("%w after %v waiting for %s (see -wait-timeout)", errWaitTimeout, timeout, what)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// waitSettled waits for the selector called name to match something visible, and then for the number of elements it
+// matches to stop changing, for lists like the table of contents or transcript that render bit by bit.
+func waitSettled(name string) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		var resolved string
+		if err := waitVisible(name, &resolved).Do(ctx); err != nil {
+			return err
+		}
+		sel, err := json.Marshal(resolved)
+		if err != nil {
+			return err
+		}
+		last := -1
+
+		return poll(ctx, name, func(ctx context.Context) (bool, error) {
+			var n int
+			if err := chromedp.Evaluate("document.querySelectorAll("+string(sel)+").length", &n).Do(ctx); err != nil {
+				return false, err
+			}
+			settled := n > 0 && n == last
+			last = n
+
+			return settled, nil
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package lld
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// webdav uploads to a WebDAV share (Nextcloud, ownCloud, ...), given as webdav[s]://user@host/remote.php/dav/files/user/dir.
+type webdav struct {
+	client   *http.Client
+	base     url.URL
+	user     string
+	password string
+	made     map[string]bool // Collections known to exist.
+}
+
+func newWebDAV(u *url.URL) *webdav {
+	w := &webdav{
+		client:   http.DefaultClient,
+		base:     *u,
+		user:     u.User.Username(),
+		password: uploadPassword(u),
+		made:     map[string]bool{},
+	}
+	w.base.Scheme = "http"
+	if u.Scheme == "webdavs" {
+		w.base.Scheme = "https"
+	}
+	w.base.User = nil
+
+	return w
+}
+
+func (w *webdav) put(ctx context.Context, file, rel string) error {
+	if err := w.mkcol(ctx, path.Dir(rel)); err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err := w.request(ctx, http.MethodPut, rel, f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+
+	return w.do(req)
+}
+
+// mkcol creates dir and its parents, since WebDAV won't do that implicitly.
+func (w *webdav) mkcol(ctx context.Context, dir string) error {
+	if dir == "." || dir == "/" || w.made[dir] {
+		return nil
+	}
+	if err := w.mkcol(ctx, path.Dir(dir)); err != nil {
+		return err
+	}
+	req, err := w.request(ctx, "MKCOL", dir+"/", http.NoBody)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	// 405 Method Not Allowed means it already exists.
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+		return newStatusError(resp)
+	}
+	w.made[dir] = true
+
+	return nil
+}
+
+func (w *webdav) request(ctx context.Context, method, rel string, body io.Reader) (*http.Request, error) {
+	u := w.base
+	u.Path = path.Join(w.base.Path, rel)
+	if strings.HasSuffix(rel, "/") {
+		u.Path += "/"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+
+	return req, nil
+}
+
+func (w *webdav) do(req *http.Request) error {
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newStatusError(resp)
+	}
+
+	return nil
+}